@@ -3,10 +3,15 @@ package cmd
 import (
 	"embed"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"slices"
 
 	"github.com/deponian/logalize/internal/config"
 	"github.com/deponian/logalize/internal/core"
+	"github.com/deponian/logalize/internal/pager"
+	"github.com/mattn/go-isatty"
 	"github.com/muesli/termenv"
 	"github.com/spf13/cobra"
 )
@@ -19,14 +24,15 @@ var (
 
 func NewCommand(builtins embed.FS) *cobra.Command {
 	root := &cobra.Command{
-		Use:   "logalize",
+		Use:   "logalize [FILE...]",
 		Short: "fast and extensible log colorizer",
 		Long: `Logalize is a log colorizer.
-It's fast and extensible alternative to ccze and colorize.`,
+It's fast and extensible alternative to ccze and colorize.
+It reads the standard input if no files are given or FILE is "-".`,
 		Version:      fmt.Sprintf("%s (%s) %s", version, commit, date),
-		Args:         cobra.NoArgs,
+		Args:         cobra.ArbitraryArgs,
 		SilenceUsage: true,
-		RunE: func(cmd *cobra.Command, _ []string) error {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			// build user configuration from default paths and from --config flag
 			paths, _ := cmd.Flags().GetStringArray("config")
 			cfg, err := config.CreateUserConfig(paths)
@@ -48,8 +54,31 @@ It's fast and extensible alternative to ccze and colorize.`,
 				return nil
 			}
 
+			// pipe the output through a pager if it's a terminal
+			var output io.Writer = os.Stdout
+			var pg *pager.Pager
+			if command := pager.Command(); command != "" && usePager(settings.Opts, args) {
+				// write to the terminal directly if the pager can't be started
+				if pg, err = pager.Start(command, os.Stdout); err == nil {
+					output = pg
+					// Ctrl+C is for the pager, we must not exit
+					// and give the terminal back while it's running
+					signal.Ignore(os.Interrupt)
+				}
+			}
+
 			// run the main loop
-			err = core.Run(os.Stdin, os.Stdout, settings)
+			if len(args) > 0 {
+				err = core.RunFiles(args, output, settings)
+			} else {
+				err = core.Run(os.Stdin, output, settings)
+			}
+			// it's fine if user quit the pager before the whole output was written
+			if pg != nil {
+				if closeErr := pg.Close(); err == nil || pager.Quit(err) {
+					err = closeErr
+				}
+			}
 			if err != nil {
 				return err
 			}
@@ -74,8 +103,31 @@ It's fast and extensible alternative to ccze and colorize.`,
 	root.Flags().BoolP("only-patterns", "p", false, "highlight only patterns (can be combined with -f and -w)")
 	root.Flags().BoolP("only-words", "w", false, "highlight only words (can be combined with -f and -p)")
 	root.Flags().BoolP("dry-run", "n", false, "don't alter the input in any way")
+	root.Flags().String("format", "", "use only this format and skip format detection")
+
+	root.Flags().StringArray("only-matching-format", []string{}, "show only lines that match this format (can be repeated)")
+	root.Flags().StringArray("match-pattern", []string{}, "show only lines where this pattern matched (can be repeated)")
+	root.Flags().StringArray("match-words", []string{}, "show only lines where a word from this group matched (can be repeated)")
+	root.Flags().String("min-level", "", "show only lines with this severity level or higher (trace, debug, info, warn, error, fatal)")
+	root.Flags().String("since", "", "show only lines written at this time or later (e.g. \"2024-02-17 06:00\" or 15m for 15 minutes ago)")
+	root.Flags().String("until", "", "show only lines written at this time or earlier (e.g. \"2024-02-17 07:00\" or 5m for 5 minutes ago)")
+	root.Flags().StringArrayP("highlight", "H", []string{}, "paint matches of this regexp on top of everything (can be repeated)")
+	root.Flags().StringArray("highlight-color", []string{}, "paint matches of the regexp with the background color, COLOR:REGEX (can be repeated)")
+	root.Flags().String("tz", "", "convert timestamps to this time zone (e.g. UTC, Europe/Berlin or Local)")
+	root.Flags().String("time-format", "", "rewrite timestamps using this layout (rfc3339, rfc3339nano, rfc1123, datetime, stamp, kitchen, relative or Go layout)")
+	root.Flags().String("redact", "", "hide IP addresses, UUIDs and other sensitive values (mask or pseudonym)")
+	root.Flags().Lookup("redact").NoOptDefVal = "mask"
+	root.Flags().StringP("output", "o", "ansi", "output format (ansi, plain, html, html-fragment, svg or json)")
+	root.Flags().String("color", "auto", "when to color the output (auto, always or never)")
+	root.Flags().String("color-profile", "", "colors to use instead of detected ones (truecolor, 256, 16 or ascii)")
 
 	root.Flags().BoolP("no-ansi-escape-sequences-stripping", "s", false, "disable removing of ANSI escape sequences (save input colors)")
+	root.Flags().BoolP("no-decompression", "z", false, "disable decompression of gzip, bzip2, xz and zstd input")
+	root.Flags().IntP("jobs", "j", 1, "number of lines colorized in parallel (0 means the number of CPUs)")
+
+	root.Flags().BoolP("follow", "F", false, "follow files across truncation and rotation like \"tail -F\"")
+	root.Flags().Bool("no-prefix", false, "don't prefix lines with file names when reading several files")
+	root.Flags().Bool("no-pager", false, "don't pipe the output through $LOGALIZE_PAGER, $PAGER or \"less -R\"")
 
 	// these flags will print something and stop the program
 	root.Flags().BoolP("print-config", "C", false, "print full configuration file")
@@ -101,6 +153,22 @@ func hasDarkBackground() bool {
 	return detector.HasDarkBackground()
 }
 
+// usePager reports whether the output should go through a pager:
+// the output is a terminal and the input is finite, i.e. files
+// or the standard input redirected from a file.
+// It's here for the same reason as hasDarkBackground.
+func usePager(opts config.Options, args []string) bool {
+	if opts.NoPager || opts.Follow || !isatty.IsTerminal(os.Stdout.Fd()) {
+		return false
+	}
+	if len(args) > 0 && !slices.Contains(args, "-") {
+		return true
+	}
+	info, err := os.Stdin.Stat()
+
+	return err == nil && info.Mode().IsRegular()
+}
+
 func Run(builtins embed.FS) int {
 	command := NewCommand(builtins)
 
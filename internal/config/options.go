@@ -23,10 +23,40 @@ type Options struct {
 	HighlightOnlyPatterns bool // highlight only patterns
 	HighlightOnlyWords    bool // highlight only words
 
+	Format string // the name of the only format to be used (skips format detection)
+
+	OnlyMatchingFormats []string // show only lines that matched one of these formats
+	MatchPatterns       []string // show only lines where one of these patterns matched
+	MatchWords          []string // show only lines where one of these word groups matched
+	MinLevel            string   // show only lines with this severity level or higher
+	Since               string   // show only lines written at this time or later
+	Until               string   // show only lines written at this time or earlier
+
+	Highlights      []string // paint matches of these regexps on top of everything
+	HighlightColors []string // the same, but with the background of the color (COLOR:REGEX)
+
+	TZ         string // convert timestamps to this time zone
+	TimeFormat string // rewrite timestamps using this layout (or "relative")
+
+	Redact string // hide values of capturing groups with "redact" field ("mask" or "pseudonym")
+
+	Output string // output format ("ansi", "plain", "html", "html-fragment", "svg" or "json")
+
+	Color        string // when to color the output ("auto", "always" or "never")
+	ColorProfile string // colors to use ("truecolor", "256", "16" or "ascii"), detected if empty
+
 	DryRun bool // don't alter the input
 
 	NoANSIEscapeSequencesStripping bool // disable removing of ANSI escape sequences from the input
 
+	NoDecompression bool // disable decompression of gzip, bzip2, xz and zstd input
+
+	Jobs int // number of goroutines colorizing lines (0 means the number of CPUs)
+
+	Follow   bool // follow files like "tail -F" does
+	NoPrefix bool // don't prefix lines with file names when reading several files
+	NoPager  bool // don't pipe the output through a pager
+
 	PrintConfig   bool // print fully merged configuration file and exit the program
 	PrintBuiltins bool // print built-in configuration and exit the program
 	ListThemes    bool // print all available themes and exit the program
@@ -54,11 +84,41 @@ func NewOptions(hasDarkBackground bool) *Options {
 		HighlightOnlyPatterns: false,
 		HighlightOnlyWords:    false,
 
+		Format: "",
+
+		OnlyMatchingFormats: []string{},
+		MatchPatterns:       []string{},
+		MatchWords:          []string{},
+		MinLevel:            "",
+		Since:               "",
+		Until:               "",
+
+		Highlights:      []string{},
+		HighlightColors: []string{},
+
+		TZ:         "",
+		TimeFormat: "",
+
+		Redact: "",
+
+		Output: "ansi",
+
+		Color:        "auto",
+		ColorProfile: "",
+
 		NoANSIEscapeSequencesStripping: false,
 
+		NoDecompression: false,
+
+		Jobs: 1,
+
 		Debug:  false,
 		DryRun: false,
 
+		Follow:   false,
+		NoPrefix: false,
+		NoPager:  false,
+
 		PrintConfig:   false,
 		PrintBuiltins: false,
 		ListThemes:    false,
@@ -99,10 +159,77 @@ func (opts *Options) ReadFromConfig(cfg *koanf.Koanf) {
 		opts.HighlightOnlyWords = cfg.Bool("settings.only-words")
 	}
 
+	if cfg.Exists("settings.format") {
+		opts.Format = cfg.String("settings.format")
+	}
+
+	if cfg.Exists("settings.only-matching-format") {
+		opts.OnlyMatchingFormats = cfg.Strings("settings.only-matching-format")
+	}
+	if cfg.Exists("settings.match-pattern") {
+		opts.MatchPatterns = cfg.Strings("settings.match-pattern")
+	}
+	if cfg.Exists("settings.match-words") {
+		opts.MatchWords = cfg.Strings("settings.match-words")
+	}
+	if cfg.Exists("settings.min-level") {
+		opts.MinLevel = cfg.String("settings.min-level")
+	}
+	if cfg.Exists("settings.since") {
+		opts.Since = cfg.String("settings.since")
+	}
+	if cfg.Exists("settings.until") {
+		opts.Until = cfg.String("settings.until")
+	}
+
+	if cfg.Exists("settings.highlight") {
+		opts.Highlights = cfg.Strings("settings.highlight")
+	}
+	if cfg.Exists("settings.highlight-color") {
+		opts.HighlightColors = cfg.Strings("settings.highlight-color")
+	}
+
+	if cfg.Exists("settings.tz") {
+		opts.TZ = cfg.String("settings.tz")
+	}
+	if cfg.Exists("settings.time-format") {
+		opts.TimeFormat = cfg.String("settings.time-format")
+	}
+
+	if cfg.Exists("settings.redact") {
+		opts.Redact = cfg.String("settings.redact")
+	}
+
+	if cfg.Exists("settings.output") {
+		opts.Output = cfg.String("settings.output")
+	}
+
+	if cfg.Exists("settings.color") {
+		opts.Color = cfg.String("settings.color")
+	}
+	if cfg.Exists("settings.color-profile") {
+		opts.ColorProfile = cfg.String("settings.color-profile")
+	}
+
 	if cfg.Exists("settings.no-ansi-escape-sequences-stripping") {
 		opts.NoANSIEscapeSequencesStripping = cfg.Bool("settings.no-ansi-escape-sequences-stripping")
 	}
 
+	if cfg.Exists("settings.no-decompression") {
+		opts.NoDecompression = cfg.Bool("settings.no-decompression")
+	}
+
+	if cfg.Exists("settings.jobs") {
+		opts.Jobs = cfg.Int("settings.jobs")
+	}
+
+	if cfg.Exists("settings.no-prefix") {
+		opts.NoPrefix = cfg.Bool("settings.no-prefix")
+	}
+	if cfg.Exists("settings.no-pager") {
+		opts.NoPager = cfg.Bool("settings.no-pager")
+	}
+
 	if cfg.Exists("settings.debug") {
 		opts.Debug = cfg.Bool("settings.debug")
 	}
@@ -149,10 +276,70 @@ func (opts *Options) ReadFromFlags(flags *pflag.FlagSet) {
 		opts.HighlightOnlyWords, _ = flags.GetBool("only-words")
 	}
 
+	if flags.Changed("format") {
+		opts.Format, _ = flags.GetString("format")
+	}
+
+	if flags.Changed("only-matching-format") {
+		opts.OnlyMatchingFormats, _ = flags.GetStringArray("only-matching-format")
+	}
+	if flags.Changed("match-pattern") {
+		opts.MatchPatterns, _ = flags.GetStringArray("match-pattern")
+	}
+	if flags.Changed("match-words") {
+		opts.MatchWords, _ = flags.GetStringArray("match-words")
+	}
+	if flags.Changed("min-level") {
+		opts.MinLevel, _ = flags.GetString("min-level")
+	}
+	if flags.Changed("since") {
+		opts.Since, _ = flags.GetString("since")
+	}
+	if flags.Changed("until") {
+		opts.Until, _ = flags.GetString("until")
+	}
+
+	if flags.Changed("highlight") {
+		opts.Highlights, _ = flags.GetStringArray("highlight")
+	}
+	if flags.Changed("highlight-color") {
+		opts.HighlightColors, _ = flags.GetStringArray("highlight-color")
+	}
+
+	if flags.Changed("tz") {
+		opts.TZ, _ = flags.GetString("tz")
+	}
+	if flags.Changed("time-format") {
+		opts.TimeFormat, _ = flags.GetString("time-format")
+	}
+
+	if flags.Changed("redact") {
+		opts.Redact, _ = flags.GetString("redact")
+	}
+
+	if flags.Changed("output") {
+		opts.Output, _ = flags.GetString("output")
+	}
+
+	if flags.Changed("color") {
+		opts.Color, _ = flags.GetString("color")
+	}
+	if flags.Changed("color-profile") {
+		opts.ColorProfile, _ = flags.GetString("color-profile")
+	}
+
 	if flags.Changed("no-ansi-escape-sequences-stripping") {
 		opts.NoANSIEscapeSequencesStripping, _ = flags.GetBool("no-ansi-escape-sequences-stripping")
 	}
 
+	if flags.Changed("no-decompression") {
+		opts.NoDecompression, _ = flags.GetBool("no-decompression")
+	}
+
+	if flags.Changed("jobs") {
+		opts.Jobs, _ = flags.GetInt("jobs")
+	}
+
 	if flags.Changed("debug") {
 		opts.Debug, _ = flags.GetBool("debug")
 	}
@@ -160,6 +347,16 @@ func (opts *Options) ReadFromFlags(flags *pflag.FlagSet) {
 		opts.DryRun, _ = flags.GetBool("dry-run")
 	}
 
+	if flags.Changed("follow") {
+		opts.Follow, _ = flags.GetBool("follow")
+	}
+	if flags.Changed("no-prefix") {
+		opts.NoPrefix, _ = flags.GetBool("no-prefix")
+	}
+	if flags.Changed("no-pager") {
+		opts.NoPager, _ = flags.GetBool("no-pager")
+	}
+
 	if flags.Changed("print-config") {
 		opts.PrintConfig, _ = flags.GetBool("print-config")
 	}
@@ -41,11 +41,40 @@ func TestOptionsReadFromConfig(t *testing.T) {
 		HighlightOnlyPatterns: true,
 		HighlightOnlyWords:    true,
 
+		Format: "test",
+
+		OnlyMatchingFormats: []string{"test1", "test2"},
+		MatchPatterns:       []string{"test3"},
+		MatchWords:          []string{"test4"},
+		MinLevel:            "warn",
+		Since:               "2024-02-17 06:00",
+		Until:               "15m",
+
+		Highlights:      []string{"req-[0-9]+", "user=200"},
+		HighlightColors: []string{"#ff0000:user=alice"},
+
+		TZ:         "Europe/Berlin",
+		TimeFormat: "rfc3339",
+
+		Redact: "pseudonym",
+
+		Output: "html",
+
+		Color:        "always",
+		ColorProfile: "256",
+
 		NoANSIEscapeSequencesStripping: true,
 
+		NoDecompression: true,
+
+		Jobs: 4,
+
 		Debug:  true,
 		DryRun: true,
 
+		NoPrefix: true,
+		NoPager:  true,
+
 		PrintConfig:   false,
 		PrintBuiltins: false,
 		ListThemes:    false,
@@ -89,11 +118,41 @@ func TestOptionsReadFromFlags(t *testing.T) {
 		HighlightOnlyPatterns: true,
 		HighlightOnlyWords:    true,
 
+		Format: "test",
+
+		OnlyMatchingFormats: []string{"test1", "test2"},
+		MatchPatterns:       []string{"test3"},
+		MatchWords:          []string{"test4"},
+		MinLevel:            "warn",
+		Since:               "2024-02-17 06:00",
+		Until:               "15m",
+
+		Highlights:      []string{"req-[0-9]+", "user=200"},
+		HighlightColors: []string{"#ff0000:user=alice"},
+
+		TZ:         "Europe/Berlin",
+		TimeFormat: "rfc3339",
+
+		Redact: "pseudonym",
+
+		Output: "html",
+
+		Color:        "always",
+		ColorProfile: "256",
+
 		NoANSIEscapeSequencesStripping: true,
 
+		NoDecompression: true,
+
+		Jobs: 4,
+
 		Debug:  true,
 		DryRun: true,
 
+		Follow:   true,
+		NoPrefix: true,
+		NoPager:  true,
+
 		PrintConfig:   true,
 		PrintBuiltins: true,
 		ListThemes:    true,
@@ -114,11 +173,37 @@ func TestOptionsReadFromFlags(t *testing.T) {
 	flags.BoolP("only-patterns", "p", false, "")
 	flags.BoolP("only-words", "w", false, "")
 
+	flags.String("format", "", "")
+
+	flags.StringArray("only-matching-format", []string{}, "")
+	flags.StringArray("match-pattern", []string{}, "")
+	flags.StringArray("match-words", []string{}, "")
+	flags.String("min-level", "", "")
+	flags.String("since", "", "")
+	flags.String("until", "", "")
+
+	flags.StringArrayP("highlight", "H", []string{}, "")
+	flags.StringArray("highlight-color", []string{}, "")
+	flags.String("tz", "", "")
+	flags.String("time-format", "", "")
+	flags.String("redact", "", "")
+	flags.Lookup("redact").NoOptDefVal = "mask"
+	flags.StringP("output", "o", "ansi", "")
+	flags.String("color", "auto", "")
+	flags.String("color-profile", "", "")
+
 	flags.BoolP("no-ansi-escape-sequences-stripping", "s", false, "")
 
+	flags.BoolP("no-decompression", "z", false, "")
+	flags.IntP("jobs", "j", 1, "")
+
 	flags.BoolP("debug", "d", false, "")
 	flags.BoolP("dry-run", "n", false, "")
 
+	flags.BoolP("follow", "F", false, "")
+	flags.Bool("no-prefix", false, "")
+	flags.Bool("no-pager", false, "")
+
 	flags.BoolP("print-config", "C", false, "")
 	flags.BoolP("list-themes", "T", false, "")
 	flags.BoolP("print-builtins", "B", false, "")
@@ -135,9 +220,31 @@ func TestOptionsReadFromFlags(t *testing.T) {
 		"--only-formats",
 		"--only-patterns",
 		"--only-words",
+		"--format", "test",
+		"--only-matching-format", "test1",
+		"--only-matching-format", "test2",
+		"--match-pattern", "test3",
+		"--match-words", "test4",
+		"--min-level", "warn",
+		"--since", "2024-02-17 06:00",
+		"--until", "15m",
+		"--highlight", "req-[0-9]+",
+		"-H", "user=200",
+		"--highlight-color", "#ff0000:user=alice",
+		"--tz", "Europe/Berlin",
+		"--time-format", "rfc3339",
+		"--redact=pseudonym",
+		"-o", "html",
+		"--color", "always",
+		"--color-profile", "256",
 		"--no-ansi-escape-sequences-stripping",
+		"--no-decompression",
+		"--jobs", "4",
 		"--debug",
 		"--dry-run",
+		"--follow",
+		"--no-prefix",
+		"--no-pager",
 		"--print-config",
 		"--list-themes",
 		"--print-builtins",
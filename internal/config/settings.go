@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io/fs"
 	"os"
+	"slices"
 	"strings"
 
 	goyaml "github.com/goccy/go-yaml"
@@ -13,6 +14,7 @@ import (
 	"github.com/knadh/koanf/providers/file"
 	"github.com/knadh/koanf/providers/rawbytes"
 	"github.com/knadh/koanf/v2"
+	"github.com/mattn/go-isatty"
 	"github.com/muesli/termenv"
 	"github.com/spf13/pflag"
 )
@@ -48,15 +50,78 @@ func NewSettings(builtins fs.FS, userConfig *koanf.Koanf, flags *pflag.FlagSet,
 			)
 	}
 
+	// we need WithUnsafe() to know what the terminal supports
+	// even if the output is a pipe or a file (see --color always)
+	terminal := termenv.NewOutput(os.Stdout, termenv.WithUnsafe()).ColorProfile()
+	isTerminal := isatty.IsTerminal(os.Stdout.Fd()) || isatty.IsCygwinTerminal(os.Stdout.Fd())
+	profile, err := colorProfile(*opts, isTerminal, terminal)
+	if err != nil {
+		return Settings{}, err
+	}
+
 	return Settings{
-		Config:   config,
-		Opts:     *opts,
-		Builtins: builtins,
-		// we need WithUnsafe() to color output even if it's a pipe or a file
-		ColorProfile: termenv.NewOutput(os.Stdout, termenv.WithUnsafe()).EnvColorProfile(),
+		Config:       config,
+		Opts:         *opts,
+		Builtins:     builtins,
+		ColorProfile: profile,
 	}, nil
 }
 
+// colorProfile decides how to color the output based on --color
+// and --color-profile options, NO_COLOR and FORCE_COLOR environment
+// variables and whether the output is a terminal. FORCE_COLOR can also
+// set the number of colors like in Node.js (1 is 16, 2 is 256, 3 is truecolor).
+func colorProfile(opts Options, isTerminal bool, terminal termenv.Profile) (termenv.Profile, error) {
+	profiles := map[string]termenv.Profile{
+		"truecolor": termenv.TrueColor,
+		"256":       termenv.ANSI256,
+		"16":        termenv.ANSI,
+		"ascii":     termenv.Ascii,
+	}
+	profile, explicit := profiles[opts.ColorProfile]
+	if opts.ColorProfile != "" && !explicit {
+		return termenv.Ascii, fmt.Errorf(
+			"[color-profile] %q must be \"truecolor\", \"256\", \"16\" or \"ascii\"", opts.ColorProfile)
+	}
+
+	switch opts.Color {
+	case "never":
+		return termenv.Ascii, nil
+	case "always":
+	case "auto":
+		if os.Getenv("NO_COLOR") != "" {
+			return termenv.Ascii, nil
+		}
+		switch os.Getenv("FORCE_COLOR") {
+		case "":
+			if !isTerminal {
+				return termenv.Ascii, nil
+			}
+		case "0", "false":
+			return termenv.Ascii, nil
+		case "1":
+			terminal = termenv.ANSI
+		case "2":
+			terminal = termenv.ANSI256
+		case "3":
+			terminal = termenv.TrueColor
+		}
+	default:
+		return termenv.Ascii, fmt.Errorf("[color] %q must be \"auto\", \"always\" or \"never\"", opts.Color)
+	}
+
+	if explicit {
+		return profile, nil
+	}
+	// the output is colored on request, but the terminal
+	// (e.g. TERM=dumb) doesn't tell what colors it supports
+	if terminal == termenv.Ascii {
+		return termenv.ANSI, nil
+	}
+
+	return terminal, nil
+}
+
 // CreateUserConfig builds configuration instance from default paths
 // (/etc/logalize/..., ~/.config/logalize/... and ./.logalize.yaml) and
 // other paths from userPaths variable (most likely these come from --config flag(s)).
@@ -174,9 +239,30 @@ func (s Settings) printConfig() string {
 	_ = enc.Encode(s.Config.Raw())
 	_ = enc.Close()
 
+	// the order of keys in the configuration has nothing to do
+	// with the order in which formats are tried, so show it explicitly
+	if order := formatOrder(s.Config); len(order) > 0 {
+		buf.WriteString("# formats in the order they are tried:\n")
+		for i, name := range order {
+			fmt.Fprintf(&buf, "#   %d. %s (priority %d)\n", i+1, name, s.Config.Int("formats."+name+".priority"))
+		}
+	}
+
 	return buf.String()
 }
 
+// formatOrder returns names of formats in the order they are tried:
+// formats with higher priority go first, formats with the same priority
+// are sorted by name
+func formatOrder(config *koanf.Koanf) []string {
+	names := config.MapKeys("formats")
+	slices.SortStableFunc(names, func(a, b string) int {
+		return config.Int("formats."+b+".priority") - config.Int("formats."+a+".priority")
+	})
+
+	return names
+}
+
 func (s Settings) printBuiltins() string {
 	var b strings.Builder
 	_ = fs.WalkDir(s.Builtins, ".", func(p string, d fs.DirEntry, err error) error {
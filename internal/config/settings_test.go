@@ -44,8 +44,21 @@ func TestSettingsNewGood(t *testing.T) {
 		HighlightOnlyPatterns: true,
 		HighlightOnlyWords:    true,
 
+		OnlyMatchingFormats: []string{},
+		MatchPatterns:       []string{},
+		MatchWords:          []string{},
+
+		Highlights:      []string{},
+		HighlightColors: []string{},
+
+		Output: "ansi",
+
+		Color: "auto",
+
 		NoANSIEscapeSequencesStripping: true,
 
+		Jobs: 1,
+
 		Debug:  true,
 		DryRun: true,
 
@@ -130,6 +143,68 @@ func TestSettingsNewBad(t *testing.T) {
 	}
 }
 
+func TestSettingsColorProfile(t *testing.T) {
+	tests := []struct {
+		name         string
+		color        string
+		colorProfile string
+		noColor      string
+		forceColor   string
+		isTerminal   bool
+		terminal     termenv.Profile
+		profile      termenv.Profile
+		err          bool
+	}{
+		{"AutoTerminal", "auto", "", "", "", true, termenv.TrueColor, termenv.TrueColor, false},
+		{"AutoPipe", "auto", "", "", "", false, termenv.TrueColor, termenv.Ascii, false},
+		{"AutoProfile", "auto", "16", "", "", true, termenv.TrueColor, termenv.ANSI, false},
+		{"AutoProfilePipe", "auto", "16", "", "", false, termenv.TrueColor, termenv.Ascii, false},
+		{"AutoNoColor", "auto", "", "1", "", true, termenv.TrueColor, termenv.Ascii, false},
+		{"AutoNoColorForceColor", "auto", "", "1", "1", true, termenv.TrueColor, termenv.Ascii, false},
+		{"AutoForceColor", "auto", "", "", "true", false, termenv.TrueColor, termenv.TrueColor, false},
+		{"AutoForceColor0", "auto", "", "", "0", true, termenv.TrueColor, termenv.Ascii, false},
+		{"AutoForceColorFalse", "auto", "", "", "false", true, termenv.TrueColor, termenv.Ascii, false},
+		{"AutoForceColor1", "auto", "", "", "1", false, termenv.TrueColor, termenv.ANSI, false},
+		{"AutoForceColor2", "auto", "", "", "2", false, termenv.TrueColor, termenv.ANSI256, false},
+		{"AutoForceColor3", "auto", "", "", "3", false, termenv.ANSI, termenv.TrueColor, false},
+		{"AutoForceColorProfile", "auto", "256", "", "3", false, termenv.ANSI, termenv.ANSI256, false},
+		{"AutoForceColorDumb", "auto", "", "", "yes", false, termenv.Ascii, termenv.ANSI, false},
+		{"Always", "always", "", "", "", false, termenv.ANSI256, termenv.ANSI256, false},
+		{"AlwaysNoColor", "always", "", "1", "", false, termenv.ANSI256, termenv.ANSI256, false},
+		{"AlwaysDumb", "always", "", "", "", false, termenv.Ascii, termenv.ANSI, false},
+		{"AlwaysProfile", "always", "truecolor", "", "", false, termenv.Ascii, termenv.TrueColor, false},
+		{"AlwaysAscii", "always", "ascii", "", "", true, termenv.TrueColor, termenv.Ascii, false},
+		{"Never", "never", "", "", "1", true, termenv.TrueColor, termenv.Ascii, false},
+		{"NeverProfile", "never", "truecolor", "", "", true, termenv.TrueColor, termenv.Ascii, false},
+		{"BadColor", "sometimes", "", "", "", true, termenv.TrueColor, termenv.Ascii, true},
+		{"BadColorProfile", "auto", "8", "", "", true, termenv.TrueColor, termenv.Ascii, true},
+	}
+
+	for _, tt := range tests {
+		t.Run("TestSettingsColorProfile"+tt.name, func(t *testing.T) {
+			t.Setenv("NO_COLOR", tt.noColor)
+			t.Setenv("FORCE_COLOR", tt.forceColor)
+
+			opts := NewOptions(true)
+			opts.Color, opts.ColorProfile = tt.color, tt.colorProfile
+			profile, err := colorProfile(*opts, tt.isTerminal, tt.terminal)
+			if tt.err {
+				if err == nil {
+					t.Errorf("colorProfile(...) should have failed")
+				}
+
+				return
+			}
+			if err != nil {
+				t.Errorf("colorProfile(...) failed with this error: %s", err)
+			}
+			if profile != tt.profile {
+				t.Errorf("got %v, want %v", profile, tt.profile)
+			}
+		})
+	}
+}
+
 func TestSettingsCreateUserConfigGood(t *testing.T) {
 	correctConfig := koanf.New(".")
 	err := correctConfig.Load(file.Provider("./testdata/settings/CreateUserConfig/01_good.yaml"), yaml.Parser())
@@ -2,12 +2,15 @@
 package core
 
 import (
-	"bufio"
 	"bytes"
 	"io"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/deponian/logalize/internal/config"
 	"github.com/deponian/logalize/internal/highlighter"
+	"github.com/deponian/logalize/internal/input"
 )
 
 // Run reads lines from the reader, colorizes them based on the settings
@@ -18,41 +21,338 @@ func Run(reader io.Reader, writer io.Writer, settings config.Settings) error {
 		return err
 	}
 
-	bufReader := bufio.NewReader(reader)
+	if !settings.Opts.NoDecompression {
+		decompressed, err := input.Decompress(reader)
+		if err != nil {
+			return err
+		}
+		defer decompressed.Close()
+		reader = decompressed
+	}
+
+	writer, flush := outputWriter(writer, hl)
+	header, footer := hl.Document()
+	if err := writeString(writer, header); err != nil {
+		return err
+	}
+
+	if err := colorize(reader, newLineWriter(writer, hl, nil, settings.Opts.Jobs)); err != nil {
+		return err
+	}
+
+	if err := writeEnd(writer, hl, settings, footer); err != nil {
+		return err
+	}
+
+	return flush()
+}
+
+// RunFiles does the same as Run but reads lines from the files at paths.
+// The files are read one after another unless settings.Opts.Follow is set.
+// In that case all files are followed simultaneously and their lines are
+// written in the order they arrive. If there is more than one file, every
+// line is prefixed with the name of the file it came from.
+func RunFiles(paths []string, writer io.Writer, settings config.Settings) error {
+	hl, err := highlighter.NewHighlighter(settings)
+	if err != nil {
+		return err
+	}
+
+	// every line of JSON output is a JSON object, so it can't have a prefix
+	var prefixes []string
+	if len(paths) > 1 && !settings.Opts.NoPrefix && settings.Opts.Output != "json" {
+		prefixes = sourcePrefixes(paths, hl)
+	}
+
+	writer, flush := outputWriter(writer, hl)
+	header, footer := hl.Document()
+	if err := writeString(writer, header); err != nil {
+		return err
+	}
+
+	lw := newLineWriter(writer, hl, prefixes, settings.Opts.Jobs)
+
+	if settings.Opts.Follow && len(paths) > 1 {
+		err = follow(paths, lw, !settings.Opts.NoDecompression)
+	} else {
+		err = readFiles(paths, lw, settings.Opts.Follow, !settings.Opts.NoDecompression)
+	}
+
+	if closeErr := lw.close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := writeEnd(writer, hl, settings, footer); err != nil {
+		return err
+	}
+
+	return flush()
+}
+
+// outputWriter returns the writer for the lines. If the output format
+// needs all the lines at once (e.g. SVG picture), they are collected
+// in memory and written by flush after all of them are processed.
+func outputWriter(writer io.Writer, hl highlighter.Highlighter) (io.Writer, func() error) {
+	if !hl.Buffered() {
+		return writer, func() error { return nil }
+	}
+
 	var buffer bytes.Buffer
+	flush := func() error {
+		return writeString(writer, hl.RenderAll(buffer.String()))
+	}
 
-	for {
-		b, readErr := bufReader.ReadByte()
-		if readErr != nil && readErr != io.EOF {
-			return readErr
+	return &buffer, flush
+}
+
+// writeEnd writes statistics of the format detection after all lines
+// if user set --debug flag and then the footer of the output document
+func writeEnd(writer io.Writer, hl highlighter.Highlighter, settings config.Settings, footer string) error {
+	if settings.Opts.Debug {
+		if err := writeString(writer, hl.DebugStats()); err != nil {
+			return err
 		}
+	}
+
+	return writeString(writer, footer)
+}
+
+// writeString writes the string unless it's empty
+func writeString(writer io.Writer, str string) error {
+	if str == "" {
+		return nil
+	}
+	_, err := io.WriteString(writer, str)
+
+	return err
+}
+
+// readFiles reads the files one after another and passes their lines to lw
+func readFiles(paths []string, lw lineWriter, follow, decompress bool) error {
+	for i, path := range paths {
+		reader, err := input.Open(path, follow, decompress)
+		if err != nil {
+			return err
+		}
+
+		err = readLines(reader, func(text, terminator string) error {
+			return lw.write(line{source: i, text: text, terminator: terminator})
+		})
+		_ = reader.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
 
-		if b == '\r' || b == '\n' || readErr == io.EOF {
-			// b is equal to 0 when we reach the end of the file
-			// so we won't add it to the end of the string
-			var lastCharacter string
-			if b == '\r' || b == '\n' {
-				lastCharacter = string(b)
+// line is one line of the input with its line terminator
+type line struct {
+	source     int // index of the file the line came from
+	text       string
+	terminator string
+	record     highlighter.Record // multiline record the line belongs to
+}
+
+// follow reads lines from all files at once and passes them
+// to lw in the order they arrive
+func follow(paths []string, lw lineWriter, decompress bool) error {
+	lines := make(chan line)
+	errs := make(chan error, len(paths))
+	done := make(chan struct{})
+	defer close(done)
+
+	// every file is opened in its own goroutine,
+	// so a file that is slow to open doesn't hold the others
+	for i, path := range paths {
+		go func() {
+			reader, err := input.Open(path, true, decompress)
+			if err != nil {
+				errs <- err
+
+				return
 			}
+			go func() {
+				<-done
+				_ = reader.Close()
+			}()
+
+			errs <- readLines(reader, func(text, terminator string) error {
+				select {
+				case lines <- line{source: i, text: text, terminator: terminator}:
+				case <-done:
+				}
 
-			colored := hl.Colorize(buffer.String())
+				return nil
+			})
+		}()
+	}
 
-			_, err := writer.Write([]byte(colored + lastCharacter))
+	// wait until all readers are done (only stdin can really end here)
+	for remaining := len(paths); remaining > 0; {
+		select {
+		case l := <-lines:
+			if err := lw.write(l); err != nil {
+				return err
+			}
+		case err := <-errs:
 			if err != nil {
 				return err
 			}
+			remaining--
+		}
+	}
+
+	return nil
+}
+
+// formatLine colorizes the line and puts the prefix
+// of its source in front of it (if there are any prefixes).
+// It returns an empty string if the line is filtered out.
+func formatLine(hl highlighter.Highlighter, l line, prefixes []string) string {
+	// the last line of a file is empty if the file ends with a line terminator,
+	// there is nothing to show in this case and it must not be counted as a line
+	if l.text == "" && l.terminator == "" {
+		return ""
+	}
+
+	var colored string
+	if hl.Filtering() {
+		var match highlighter.Match
+		colored, match = hl.ColorizeMatch(l.text, l.record)
+		if !hl.Wanted(match) {
+			return ""
+		}
+	} else {
+		colored = hl.ColorizeRecord(l.text, l.record)
+	}
+
+	var prefix string
+	if prefixes != nil {
+		prefix = prefixes[l.source]
+	}
+
+	return prefix + colored + hl.LineTerminator(l.terminator)
+}
+
+// colorize reads lines from the reader and passes them to lw
+func colorize(reader io.Reader, lw lineWriter) error {
+	err := readLines(reader, func(text, terminator string) error {
+		return lw.write(line{text: text, terminator: terminator})
+	})
+
+	if closeErr := lw.close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+// sourcePrefixes returns colored prefixes for lines from the files at paths.
+// A prefix is the base name of a file padded to the same width as others.
+// Full paths are used if base names aren't unique.
+func sourcePrefixes(paths []string, hl highlighter.Highlighter) []string {
+	names := make([]string, len(paths))
+	seen := make(map[string]bool, len(paths))
+	unique := true
+	for i, path := range paths {
+		names[i] = filepath.Base(path)
+		if path == input.Stdin {
+			names[i] = "stdin"
+		}
+		if seen[names[i]] {
+			unique = false
+		}
+		seen[names[i]] = true
+	}
+	if !unique {
+		for i, path := range paths {
+			if path != input.Stdin {
+				names[i] = path
+			}
+		}
+	}
+
+	width := 0
+	for _, name := range names {
+		width = max(width, utf8.RuneCountInString(name))
+	}
+
+	prefixes := make([]string, len(names))
+	for i, name := range names {
+		padded := name + strings.Repeat(" ", width-utf8.RuneCountInString(name))
+		prefixes[i] = hl.ColorizePrefix(padded+" |", i) + " "
+	}
+
+	return prefixes
+}
+
+// readLines calls f for every line from the reader.
+// '\r', '\n' and "\r\n" are treated as line terminators.
+// The last line is passed to f with an empty terminator.
+//
+// The input is read in chunks of whatever size the reader returns,
+// so lines from slow readers are passed to f as soon as they arrive.
+// Only a line that ends with '\r' at the end of a chunk waits
+// for the next one to see whether '\n' follows.
+func readLines(reader io.Reader, f func(text, terminator string) error) error {
+	chunk := make([]byte, 64*1024)
+	var buffer bytes.Buffer
+	pendingCR := false // the buffer is a line that ended with '\r' at the end of the last chunk
+
+	for {
+		n, readErr := reader.Read(chunk)
+		if readErr != nil && readErr != io.EOF {
+			return readErr
+		}
 
+		data := chunk[:n]
+		if pendingCR && (n > 0 || readErr == io.EOF) {
+			pendingCR = false
+			terminator := "\r"
+			if len(data) > 0 && data[0] == '\n' {
+				terminator = "\r\n"
+				data = data[1:]
+			}
+			if err := f(buffer.String(), terminator); err != nil {
+				return err
+			}
 			buffer.Reset()
+		}
 
-			if readErr == io.EOF {
+		for {
+			i := bytes.IndexAny(data, "\r\n")
+			if i < 0 {
 				break
 			}
 
-			continue
+			buffer.Write(data[:i])
+			terminator := data[i : i+1]
+			if data[i] == '\r' {
+				if i+1 == len(data) && readErr == nil {
+					pendingCR = true
+					data = nil
+
+					break
+				}
+				if i+1 < len(data) && data[i+1] == '\n' {
+					terminator = data[i : i+2]
+				}
+			}
+			if err := f(buffer.String(), string(terminator)); err != nil {
+				return err
+			}
+			buffer.Reset()
+			data = data[i+len(terminator):]
 		}
+		buffer.Write(data)
 
-		buffer.WriteByte(b)
+		if readErr == io.EOF {
+			return f(buffer.String(), "")
+		}
 	}
-
-	return nil
 }
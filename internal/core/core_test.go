@@ -2,14 +2,19 @@ package core
 
 import (
 	"bytes"
+	"compress/gzip"
 	"embed"
+	"errors"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/deponian/logalize/internal/config"
+	"github.com/deponian/logalize/internal/highlighter"
 	"github.com/knadh/koanf/parsers/yaml"
 	"github.com/knadh/koanf/providers/file"
 	"github.com/knadh/koanf/v2"
@@ -65,6 +70,129 @@ func TestRunGood(t *testing.T) {
 	}
 }
 
+func TestRunDebugStats(t *testing.T) {
+	cfg := koanf.New(".")
+	err := cfg.Load(file.Provider("./testdata/core/Run/01_good.yaml"), yaml.Parser())
+	if err != nil {
+		t.Fatalf("cfg.Load(...) failed with this error: %s", err)
+	}
+	err = cfg.Set("settings.theme", "test")
+	if err != nil {
+		t.Fatalf("cfg.Set(...) failed with this error: %s", err)
+	}
+
+	settings, err := config.NewSettings(builtins, cfg, nil, true)
+	if err != nil {
+		t.Fatalf("config.NewSettings(...) failed with this error: %s", err)
+	}
+	settings.Opts.Debug = true
+	settings.Opts.Format = "menetekel"
+
+	input := strings.NewReader("127.0.0.1 - [test] \"testing\"\nHello\n")
+	output := bytes.Buffer{}
+
+	if err := Run(input, &output, settings); err != nil {
+		t.Fatalf("Run() failed with this error: %s", err)
+	}
+
+	// statistics go after all lines
+	stats := "\n[debug] format detection: 2 lines, recently matched format matched 0 of them (0.0%)\n" +
+		"[debug] format menetekel (priority 0): 1 matches (50.0%)\n" +
+		"[debug] no format: 1 lines (50.0%)\n"
+	if !strings.HasSuffix(output.String(), stats) {
+		t.Errorf("got %q, want it to end with %q", output.String(), stats)
+	}
+}
+
+func TestRunHTML(t *testing.T) {
+	cfg := koanf.New(".")
+	err := cfg.Load(file.Provider("./testdata/core/Run/01_good.yaml"), yaml.Parser())
+	if err != nil {
+		t.Fatalf("cfg.Load(...) failed with this error: %s", err)
+	}
+	err = cfg.Set("settings.theme", "test")
+	if err != nil {
+		t.Fatalf("cfg.Set(...) failed with this error: %s", err)
+	}
+
+	settings, err := config.NewSettings(builtins, cfg, nil, true)
+	if err != nil {
+		t.Fatalf("config.NewSettings(...) failed with this error: %s", err)
+	}
+	settings.Opts.Output = "html-fragment"
+
+	path := filepath.Join(t.TempDir(), "test.log")
+	if err := os.WriteFile(path, []byte("Hello <true>\n"), 0o644); err != nil {
+		t.Fatalf("os.WriteFile(...) failed with this error: %s", err)
+	}
+
+	html := `<pre class="logalize" style="margin:0;padding:1em;font-family:ui-monospace,monospace">` +
+		`Hello &lt;<span style="color:#52fa8a;font-weight:bold">true</span>&gt;` + "\n" +
+		"</pre>\n"
+
+	t.Run("TestRunHTML", func(t *testing.T) {
+		output := bytes.Buffer{}
+		if err := Run(strings.NewReader("Hello <true>\n"), &output, settings); err != nil {
+			t.Fatalf("Run() failed with this error: %s", err)
+		}
+		if output.String() != html {
+			t.Errorf("got %q, want %q", output.String(), html)
+		}
+	})
+
+	t.Run("TestRunSVG", func(t *testing.T) {
+		settings := settings
+		settings.Opts.Output = "svg"
+		hl, err := highlighter.NewHighlighter(settings)
+		if err != nil {
+			t.Fatalf("NewHighlighter() failed with this error: %s", err)
+		}
+
+		// the picture is drawn after all lines are read
+		output := bytes.Buffer{}
+		if err := Run(strings.NewReader("Hello <true>\nfalse\n"), &output, settings); err != nil {
+			t.Fatalf("Run() failed with this error: %s", err)
+		}
+		svg := hl.RenderAll(hl.Colorize("Hello <true>") + "\n" + hl.Colorize("false") + "\n")
+		if output.String() != svg {
+			t.Errorf("got %q, want %q", output.String(), svg)
+		}
+	})
+
+	t.Run("TestRunFilesJSON", func(t *testing.T) {
+		settings := settings
+		settings.Opts.Output = "json"
+
+		// lines of JSON output end with "\n" and don't have prefixes
+		other := filepath.Join(t.TempDir(), "other.log")
+		if err := os.WriteFile(other, []byte("one\rtwo\r\nthree"), 0o644); err != nil {
+			t.Fatalf("os.WriteFile(...) failed with this error: %s", err)
+		}
+		json := `{"text":"Hello <true>","spans":[{"start":7,"end":11,"kind":"word","name":"good","fg":"#52fa8a","style":"bold"}]}` + "\n" +
+			`{"text":"one","spans":[]}` + "\n" +
+			`{"text":"two","spans":[]}` + "\n" +
+			`{"text":"three","spans":[]}` + "\n"
+
+		output := bytes.Buffer{}
+		if err := RunFiles([]string{path, other}, &output, settings); err != nil {
+			t.Fatalf("RunFiles() failed with this error: %s", err)
+		}
+		if output.String() != json {
+			t.Errorf("got %q, want %q", output.String(), json)
+		}
+	})
+
+	t.Run("TestRunFilesHTML", func(t *testing.T) {
+		output := bytes.Buffer{}
+		if err := RunFiles([]string{path}, &output, settings); err != nil {
+			t.Fatalf("RunFiles() failed with this error: %s", err)
+		}
+		if output.String() != html {
+			t.Errorf("got %q, want %q", output.String(), html)
+		}
+	})
+}
+
 func TestRunBad(t *testing.T) {
 	cfg := koanf.New(".")
 	err := cfg.Load(file.Provider("./testdata/core/Run/02_bad.yaml"), yaml.Parser())
@@ -141,3 +269,248 @@ func TestRunBadReader(t *testing.T) {
 		}
 	})
 }
+
+func TestRunFiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(dir+"/one.log", []byte("true\nfalse\n"), 0o600); err != nil {
+		t.Fatalf("Wasn't able to write test file: %s", err)
+	}
+	if err := os.WriteFile(dir+"/two.log", []byte("wenzel"), 0o600); err != nil {
+		t.Fatalf("Wasn't able to write test file: %s", err)
+	}
+
+	cfg := koanf.New(".")
+	err := cfg.Load(file.Provider("./testdata/core/Run/01_good.yaml"), yaml.Parser())
+	if err != nil {
+		t.Fatalf("cfg.Load(...) failed with this error: %s", err)
+	}
+	err = cfg.Set("settings.theme", "test")
+	if err != nil {
+		t.Fatalf("cfg.Set(...) failed with this error: %s", err)
+	}
+
+	settings, err := config.NewSettings(builtins, cfg, nil, true)
+	if err != nil {
+		t.Fatalf("config.NewSettings(...) failed with this error: %s", err)
+	}
+	settings.ColorProfile = termenv.TrueColor
+
+	t.Run("TestRunFilesGood", func(t *testing.T) {
+		output := bytes.Buffer{}
+		err := RunFiles([]string{dir + "/one.log", dir + "/two.log"}, &output, settings)
+		if err != nil {
+			t.Errorf("RunFiles() failed with this error: %s", err)
+		}
+
+		colored := "\x1b[38;2;255;0;0mone.log |\x1b[0m \x1b[38;2;81;250;138;1mtrue\x1b[0m\n" +
+			"\x1b[38;2;255;0;0mone.log |\x1b[0m \x1b[48;2;240;108;97mfalse\x1b[0m\n" +
+			"\x1b[38;2;0;255;0mtwo.log |\x1b[0m \x1b[38;2;248;52;178;4mwenzel\x1b[0m"
+		if output.String() != colored {
+			t.Errorf("got %v, want %v", output.String(), colored)
+		}
+	})
+
+	t.Run("TestRunFilesNoPrefix", func(t *testing.T) {
+		settings := settings
+		settings.Opts.NoPrefix = true
+		output := bytes.Buffer{}
+		err := RunFiles([]string{dir + "/one.log", dir + "/two.log"}, &output, settings)
+		if err != nil {
+			t.Errorf("RunFiles() failed with this error: %s", err)
+		}
+
+		colored := "\x1b[38;2;81;250;138;1mtrue\x1b[0m\n\x1b[48;2;240;108;97mfalse\x1b[0m\n" +
+			"\x1b[38;2;248;52;178;4mwenzel\x1b[0m"
+		if output.String() != colored {
+			t.Errorf("got %v, want %v", output.String(), colored)
+		}
+	})
+
+	t.Run("TestRunFilesOneFile", func(t *testing.T) {
+		output := bytes.Buffer{}
+		err := RunFiles([]string{dir + "/two.log"}, &output, settings)
+		if err != nil {
+			t.Errorf("RunFiles() failed with this error: %s", err)
+		}
+
+		colored := "\x1b[38;2;248;52;178;4mwenzel\x1b[0m"
+		if output.String() != colored {
+			t.Errorf("got %v, want %v", output.String(), colored)
+		}
+	})
+
+	t.Run("TestRunFilesCompressed", func(t *testing.T) {
+		var compressed bytes.Buffer
+		writer := gzip.NewWriter(&compressed)
+		if _, err := writer.Write([]byte("wenzel")); err != nil {
+			t.Fatalf("Wasn't able to compress test data: %s", err)
+		}
+		if err := writer.Close(); err != nil {
+			t.Fatalf("Wasn't able to compress test data: %s", err)
+		}
+		if err := os.WriteFile(dir+"/two.log.gz", compressed.Bytes(), 0o600); err != nil {
+			t.Fatalf("Wasn't able to write test file: %s", err)
+		}
+
+		output := bytes.Buffer{}
+		err := RunFiles([]string{dir + "/two.log.gz"}, &output, settings)
+		if err != nil {
+			t.Errorf("RunFiles() failed with this error: %s", err)
+		}
+
+		colored := "\x1b[38;2;248;52;178;4mwenzel\x1b[0m"
+		if output.String() != colored {
+			t.Errorf("got %v, want %v", output.String(), colored)
+		}
+
+		settings := settings
+		settings.Opts.NoDecompression = true
+		output = bytes.Buffer{}
+		err = RunFiles([]string{dir + "/two.log.gz"}, &output, settings)
+		if err != nil {
+			t.Errorf("RunFiles() failed with this error: %s", err)
+		}
+		if !bytes.HasPrefix(output.Bytes(), []byte{0x1f, 0x8b}) {
+			t.Errorf("input shouldn't have been decompressed, got %q", output.String())
+		}
+	})
+
+	t.Run("TestRunFilesNonExistent", func(t *testing.T) {
+		err := RunFiles([]string{dir + "/one.log", dir + "/non-existent.log"}, &bytes.Buffer{}, settings)
+		if _, ok := err.(*fs.PathError); !ok {
+			t.Errorf("RunFiles() should have failed with *fs.PathError, got: [%T] %s", err, err)
+		}
+	})
+
+	t.Run("TestRunFilesFollowNonExistent", func(t *testing.T) {
+		settings := settings
+		settings.Opts.Follow = true
+		err := RunFiles([]string{dir + "/one.log", dir + "/non-existent.log"}, &bytes.Buffer{}, settings)
+		if _, ok := err.(*fs.PathError); !ok {
+			t.Errorf("RunFiles() should have failed with *fs.PathError, got: [%T] %s", err, err)
+		}
+	})
+
+	t.Run("TestRunFilesFollow", func(t *testing.T) {
+		if err := os.WriteFile(dir+"/three.log", []byte("wenzel\n"), 0o600); err != nil {
+			t.Fatalf("Wasn't able to write test file: %s", err)
+		}
+
+		settings := settings
+		settings.Opts.Follow = true
+		output := &limitedWriter{limit: 3}
+		err := RunFiles([]string{dir + "/one.log", dir + "/three.log"}, output, settings)
+		if !errors.Is(err, errLimitReached) {
+			t.Fatalf("RunFiles() should have failed with errLimitReached, got: %v", err)
+		}
+
+		// lines from different files can be in any order
+		// but lines from the same file have to keep their order
+		got := output.String()
+		first := strings.Index(got, "\x1b[38;2;255;0;0mone.log   |\x1b[0m \x1b[38;2;81;250;138;1mtrue\x1b[0m\n")
+		second := strings.Index(got, "\x1b[38;2;255;0;0mone.log   |\x1b[0m \x1b[48;2;240;108;97mfalse\x1b[0m\n")
+		third := strings.Index(got, "\x1b[38;2;0;255;0mthree.log |\x1b[0m \x1b[38;2;248;52;178;4mwenzel\x1b[0m\n")
+		if first == -1 || second == -1 || third == -1 || first > second {
+			t.Errorf("got unexpected output: %q", got)
+		}
+	})
+
+	t.Run("TestRunFilesFollowEmpty", func(t *testing.T) {
+		if err := os.WriteFile(dir+"/empty.log", []byte{}, 0o600); err != nil {
+			t.Fatalf("Wasn't able to write test file: %s", err)
+		}
+
+		// the empty file must not hold the lines of the other one
+		settings := settings
+		settings.Opts.Follow = true
+		output := &limitedWriter{limit: 1}
+		err := RunFiles([]string{dir + "/empty.log", dir + "/three.log"}, output, settings)
+		if !errors.Is(err, errLimitReached) {
+			t.Fatalf("RunFiles() should have failed with errLimitReached, got: %v", err)
+		}
+
+		want := "\x1b[38;2;0;255;0mthree.log |\x1b[0m \x1b[38;2;248;52;178;4mwenzel\x1b[0m\n"
+		if got := output.String(); got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	})
+}
+
+func TestRunSourcePrefixes(t *testing.T) {
+	tests := []struct {
+		paths    []string
+		prefixes []string
+	}{
+		{[]string{"/var/log/api.log", "worker.log"}, []string{"api.log    | ", "worker.log | "}},
+		{[]string{"-", "/var/log/nginx/access.log"}, []string{"stdin      | ", "access.log | "}},
+		{[]string{"a/app.log", "b/app.log", "-"}, []string{"a/app.log | ", "b/app.log | ", "stdin     | "}},
+		{[]string{"ж.log", "abc.log"}, []string{"ж.log   | ", "abc.log | "}},
+	}
+
+	hl, err := highlighter.NewHighlighter(config.Settings{})
+	if err != nil {
+		t.Fatalf("highlighter.NewHighlighter(...) failed with this error: %s", err)
+	}
+
+	for _, tt := range tests {
+		t.Run("TestRunSourcePrefixes", func(t *testing.T) {
+			prefixes := sourcePrefixes(tt.paths, hl)
+			if !slices.Equal(prefixes, tt.prefixes) {
+				t.Errorf("got %q, want %q", prefixes, tt.prefixes)
+			}
+		})
+	}
+}
+
+var errLimitReached = errors.New("limit reached")
+
+// limitedWriter fails when a limited number of writes is reached
+type limitedWriter struct {
+	bytes.Buffer
+	limit int
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	n, _ := w.Buffer.Write(p)
+	w.limit--
+	if w.limit == 0 {
+		return n, errLimitReached
+	}
+
+	return n, nil
+}
+
+func TestRunReadLines(t *testing.T) {
+	type pair struct{ text, terminator string }
+
+	tests := []struct {
+		plain string
+		lines []pair
+	}{
+		{"", []pair{{"", ""}}},
+		{"one\ntwo", []pair{{"one", "\n"}, {"two", ""}}},
+		{"one\r\ntwo\n", []pair{{"one", "\r\n"}, {"two", "\n"}, {"", ""}}},
+		{"one\rtwo\r", []pair{{"one", "\r"}, {"two", "\r"}, {"", ""}}},
+		{"\r\r\n\n", []pair{{"", "\r"}, {"", "\r\n"}, {"", "\n"}, {"", ""}}},
+		{"\n\r", []pair{{"", "\n"}, {"", "\r"}, {"", ""}}},
+	}
+
+	for _, tt := range tests {
+		t.Run("TestRunReadLines"+tt.plain, func(t *testing.T) {
+			// one byte at a time to check lines split between reads
+			var lines []pair
+			err := readLines(iotest.OneByteReader(strings.NewReader(tt.plain)), func(text, terminator string) error {
+				lines = append(lines, pair{text, terminator})
+
+				return nil
+			})
+			if err != nil {
+				t.Errorf("readLines() failed with this error: %s", err)
+			}
+
+			if !slices.Equal(lines, tt.lines) {
+				t.Errorf("got %q, want %q", lines, tt.lines)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package core
+
+import (
+	"io"
+	"runtime"
+	"strings"
+
+	"github.com/deponian/logalize/internal/highlighter"
+)
+
+// batchSize is the maximum number of lines colorized by one worker at once
+const batchSize = 512
+
+// lineWriter colorizes lines and writes them to the output
+type lineWriter interface {
+	write(l line) error
+	// close flushes the lines that are still being colorized
+	close() error
+}
+
+// newLineWriter returns a lineWriter that colorizes lines on jobs goroutines.
+// Zero or negative jobs means the number of CPUs.
+func newLineWriter(writer io.Writer, hl highlighter.Highlighter, prefixes []string, jobs int) lineWriter {
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+
+	var lw lineWriter
+	if jobs == 1 {
+		lw = directWriter{writer, hl, prefixes}
+	} else {
+		lw = newPipeline(writer, hl, prefixes, jobs)
+	}
+
+	return recordWriter{lw, hl, make(map[int]*highlighter.RecordTracker)}
+}
+
+// recordWriter finds out which multiline record every line belongs to
+// before the line is colorized. Lines of every source are tracked separately,
+// so records of different files don't get mixed up in follow mode.
+type recordWriter struct {
+	lineWriter
+	hl       highlighter.Highlighter
+	trackers map[int]*highlighter.RecordTracker
+}
+
+func (rw recordWriter) write(l line) error {
+	tracker, ok := rw.trackers[l.source]
+	if !ok {
+		tracker = rw.hl.NewRecordTracker()
+		rw.trackers[l.source] = tracker
+	}
+	l.record = tracker.Track(l.text)
+
+	return rw.lineWriter.write(l)
+}
+
+// directWriter colorizes lines on the calling goroutine
+type directWriter struct {
+	writer   io.Writer
+	hl       highlighter.Highlighter
+	prefixes []string
+}
+
+func (dw directWriter) write(l line) error {
+	_, err := dw.writer.Write([]byte(formatLine(dw.hl, l, dw.prefixes)))
+
+	return err
+}
+
+func (dw directWriter) close() error {
+	return nil
+}
+
+// batch is a group of consecutive lines colorized by one worker
+type batch struct {
+	lines   []line
+	colored chan string
+}
+
+// pipeline groups lines into batches, colorizes the batches
+// on a pool of workers and writes them in the original order.
+//
+// A batch is sent to the workers as soon as there are no more lines
+// waiting in the queue, so slow input (e.g. "tail -f") is written
+// line by line without any delay.
+type pipeline struct {
+	lines    chan line
+	done     chan struct{} // closed when the output fails
+	finished chan struct{} // closed when everything is written
+	err      error
+}
+
+func newPipeline(writer io.Writer, hl highlighter.Highlighter, prefixes []string, jobs int) *pipeline {
+	p := &pipeline{
+		lines:    make(chan line, batchSize),
+		done:     make(chan struct{}),
+		finished: make(chan struct{}),
+	}
+
+	work := make(chan batch)
+	order := make(chan batch, jobs)
+
+	for range jobs {
+		go func() {
+			for b := range work {
+				var sb strings.Builder
+				for _, l := range b.lines {
+					sb.WriteString(formatLine(hl, l, prefixes))
+				}
+				b.colored <- sb.String()
+			}
+		}()
+	}
+
+	go p.group(work, order)
+	go p.output(writer, order)
+
+	return p
+}
+
+// group reads lines from the queue and sends them to the workers in batches.
+// The batches are also sent to the output in the same order.
+func (p *pipeline) group(work, order chan<- batch) {
+	defer close(work)
+	defer close(order)
+
+	for l := range p.lines {
+		b := batch{lines: []line{l}, colored: make(chan string, 1)}
+
+	fill:
+		for len(b.lines) < batchSize {
+			select {
+			case l, ok := <-p.lines:
+				if !ok {
+					break fill
+				}
+				b.lines = append(b.lines, l)
+			default:
+				break fill
+			}
+		}
+
+		select {
+		case order <- b:
+		case <-p.done:
+			return
+		}
+
+		select {
+		case work <- b:
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// output writes colorized batches to the writer in the original order
+func (p *pipeline) output(writer io.Writer, order <-chan batch) {
+	defer close(p.finished)
+
+	for b := range order {
+		if _, err := writer.Write([]byte(<-b.colored)); err != nil {
+			p.err = err
+			close(p.done)
+
+			return
+		}
+	}
+}
+
+func (p *pipeline) write(l line) error {
+	select {
+	case p.lines <- l:
+		return nil
+	case <-p.done:
+		return p.err
+	}
+}
+
+func (p *pipeline) close() error {
+	close(p.lines)
+	<-p.finished
+
+	return p.err
+}
@@ -0,0 +1,247 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/deponian/logalize/internal/config"
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/v2"
+	"github.com/muesli/termenv"
+)
+
+func pipelineSettings(t *testing.T) config.Settings {
+	t.Helper()
+
+	cfg := koanf.New(".")
+	err := cfg.Load(file.Provider("./testdata/core/Run/01_good.yaml"), yaml.Parser())
+	if err != nil {
+		t.Fatalf("cfg.Load(...) failed with this error: %s", err)
+	}
+	err = cfg.Set("settings.theme", "test")
+	if err != nil {
+		t.Fatalf("cfg.Set(...) failed with this error: %s", err)
+	}
+
+	settings, err := config.NewSettings(builtins, cfg, nil, true)
+	if err != nil {
+		t.Fatalf("config.NewSettings(...) failed with this error: %s", err)
+	}
+	settings.ColorProfile = termenv.TrueColor
+
+	return settings
+}
+
+func TestPipelineOrder(t *testing.T) {
+	settings := pipelineSettings(t)
+
+	var plain strings.Builder
+	for range 2000 {
+		plain.WriteString("127.0.0.1 - [test] \"testing\"\nHello true false\rwenzel failed\n127 times\n")
+	}
+	plain.WriteString("the end")
+
+	output := bytes.Buffer{}
+	if err := Run(strings.NewReader(plain.String()), &output, settings); err != nil {
+		t.Fatalf("Run() failed with this error: %s", err)
+	}
+	want := output.String()
+
+	for _, jobs := range []int{0, 2, 8} {
+		t.Run("TestPipelineOrder", func(t *testing.T) {
+			settings := settings
+			settings.Opts.Jobs = jobs
+			output := bytes.Buffer{}
+			if err := Run(strings.NewReader(plain.String()), &output, settings); err != nil {
+				t.Fatalf("Run() failed with this error: %s", err)
+			}
+
+			if output.String() != want {
+				t.Errorf("output with %d jobs differs from the sequential one", jobs)
+			}
+		})
+	}
+}
+
+// chanWriter sends everything written to it to a channel
+type chanWriter chan string
+
+func (w chanWriter) Write(p []byte) (int, error) {
+	w <- string(p)
+
+	return len(p), nil
+}
+
+func TestPipelineStreaming(t *testing.T) {
+	settings := pipelineSettings(t)
+	settings.Opts.Jobs = 4
+
+	reader, writer := io.Pipe()
+	output := make(chanWriter, 16)
+	result := make(chan error, 1)
+	go func() {
+		result <- Run(reader, output, settings)
+	}()
+
+	// every line has to be written before the next one is read
+	for _, tt := range []struct{ plain, colored string }{
+		{"true\n", "\x1b[38;2;81;250;138;1mtrue\x1b[0m\n"},
+		{"false\n", "\x1b[48;2;240;108;97mfalse\x1b[0m\n"},
+	} {
+		if _, err := writer.Write([]byte(tt.plain)); err != nil {
+			t.Fatalf("Wasn't able to write to the pipe: %s", err)
+		}
+
+		select {
+		case got := <-output:
+			if got != tt.colored {
+				t.Errorf("got %q, want %q", got, tt.colored)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for %q", tt.plain)
+		}
+	}
+
+	_ = writer.Close()
+	if err := <-result; err != nil {
+		t.Errorf("Run() failed with this error: %s", err)
+	}
+}
+
+func TestPipelineWriteError(t *testing.T) {
+	settings := pipelineSettings(t)
+	settings.Opts.Jobs = 4
+
+	plain := strings.Repeat("true false\n", 100000)
+	err := Run(strings.NewReader(plain), &limitedWriter{limit: 1}, settings)
+	if !errors.Is(err, errLimitReached) {
+		t.Errorf("Run() should have failed with errLimitReached, got: %v", err)
+	}
+}
+
+func TestPipelineRecords(t *testing.T) {
+	settings := pipelineSettings(t)
+
+	plain := "Oops: true\n  at main()\n\tat run\nplain\n  at main()"
+	colored := "\x1b[38;2;255;0;0mOops: \x1b[0m\x1b[38;2;81;250;138;1mtrue\x1b[0m\n" +
+		"\x1b[38;2;80;80;80m  at \x1b[0m\x1b[38;2;0;0;255mmain()\x1b[0m\n" +
+		"\x1b[38;2;80;80;80m\tat \x1b[0m\x1b[38;2;0;0;255mrun\x1b[0m\n" +
+		"plain\n" +
+		"  at main()"
+
+	for _, jobs := range []int{1, 4} {
+		t.Run("TestPipelineRecords", func(t *testing.T) {
+			settings := settings
+			settings.Opts.Jobs = jobs
+			output := bytes.Buffer{}
+			if err := Run(strings.NewReader(plain), &output, settings); err != nil {
+				t.Fatalf("Run() failed with this error: %s", err)
+			}
+
+			if output.String() != colored {
+				t.Errorf("got %q, want %q", output.String(), colored)
+			}
+		})
+	}
+
+	// records don't continue from one file to another
+	t.Run("TestPipelineRecordsSources", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := os.WriteFile(dir+"/one.log", []byte("Oops: true\n"), 0o600); err != nil {
+			t.Fatalf("Wasn't able to write test file: %s", err)
+		}
+		if err := os.WriteFile(dir+"/two.log", []byte("  at main()"), 0o600); err != nil {
+			t.Fatalf("Wasn't able to write test file: %s", err)
+		}
+
+		settings := settings
+		settings.Opts.NoPrefix = true
+		output := bytes.Buffer{}
+		if err := RunFiles([]string{dir + "/one.log", dir + "/two.log"}, &output, settings); err != nil {
+			t.Fatalf("RunFiles() failed with this error: %s", err)
+		}
+
+		colored := "\x1b[38;2;255;0;0mOops: \x1b[0m\x1b[38;2;81;250;138;1mtrue\x1b[0m\n  at main()"
+		if output.String() != colored {
+			t.Errorf("got %q, want %q", output.String(), colored)
+		}
+	})
+}
+
+func TestPipelineFilter(t *testing.T) {
+	plain := "Oops: true\n  at main()\nplain true\n  at main()\n\nfalse"
+	tests := []struct {
+		opts     config.Options
+		filtered string
+	}{
+		{config.Options{OnlyMatchingFormats: []string{"trace"}}, "Oops: true\n  at main()\n"},
+		{config.Options{MatchWords: []string{"good"}}, "Oops: true\nplain true\n"},
+		{config.Options{MatchWords: []string{"good"}, OnlyMatchingFormats: []string{"trace"}}, "Oops: true\n"},
+		{config.Options{MatchWords: []string{"friends"}}, ""},
+		// only the last line has a bad word
+		{config.Options{MinLevel: "error"}, "false"},
+		{config.Options{MinLevel: "info"}, plain},
+	}
+
+	for _, tt := range tests {
+		for _, jobs := range []int{1, 4} {
+			t.Run("TestPipelineFilter", func(t *testing.T) {
+				settings := pipelineSettings(t)
+				settings.Opts.OnlyMatchingFormats = tt.opts.OnlyMatchingFormats
+				settings.Opts.MatchWords = tt.opts.MatchWords
+				settings.Opts.MinLevel = tt.opts.MinLevel
+				settings.Opts.DryRun = true
+				settings.Opts.Jobs = jobs
+
+				output := bytes.Buffer{}
+				if err := Run(strings.NewReader(plain), &output, settings); err != nil {
+					t.Fatalf("Run() failed with this error: %s", err)
+				}
+
+				if output.String() != tt.filtered {
+					t.Errorf("got %q, want %q", output.String(), tt.filtered)
+				}
+			})
+		}
+	}
+}
+
+func TestPipelineTimeFilter(t *testing.T) {
+	plain := "before\n2024-02-17T06:00:00Z Oops: old\n  at main()\n" +
+		"Oops: 2024-02-17T07:00:00Z new\n  at main()\nplain\n2024-02-17T08:00:00Z late"
+	tests := []struct {
+		since, until string
+		filtered     string
+	}{
+		{"2024-02-17T06:30:00Z", "", "Oops: 2024-02-17T07:00:00Z new\n  at main()\nplain\n2024-02-17T08:00:00Z late"},
+		{"", "2024-02-17T07:30:00Z", "2024-02-17T06:00:00Z Oops: old\n  at main()\nOops: 2024-02-17T07:00:00Z new\n  at main()\nplain\n"},
+		{"2024-02-17T06:30:00Z", "2024-02-17T07:30:00Z", "Oops: 2024-02-17T07:00:00Z new\n  at main()\nplain\n"},
+	}
+
+	for _, tt := range tests {
+		for _, jobs := range []int{1, 4} {
+			t.Run("TestPipelineTimeFilter", func(t *testing.T) {
+				settings := pipelineSettings(t)
+				settings.Opts.Since = tt.since
+				settings.Opts.Until = tt.until
+				settings.Opts.DryRun = true
+				settings.Opts.Jobs = jobs
+
+				output := bytes.Buffer{}
+				if err := Run(strings.NewReader(plain), &output, settings); err != nil {
+					t.Fatalf("Run() failed with this error: %s", err)
+				}
+
+				if output.String() != tt.filtered {
+					t.Errorf("got %q, want %q", output.String(), tt.filtered)
+				}
+			})
+		}
+	}
+}
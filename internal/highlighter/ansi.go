@@ -0,0 +1,77 @@
+package highlighter
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/muesli/termenv"
+)
+
+// ansiRenderer colors lines with SGR sequences for the terminal
+type ansiRenderer struct {
+	profile termenv.Profile
+}
+
+func (r ansiRenderer) line(spans []span) string {
+	var out strings.Builder
+	for _, s := range spans {
+		// the colors of the spans under this one (see --highlight)
+		// must be reset too even if the span has no colors of its own
+		opened := false
+		for _, st := range slices.Concat(s.under, []spanStyle{s.spanStyle}) {
+			if seq := sgr(st, r.profile); seq != "" {
+				out.WriteString(seq)
+				opened = true
+			}
+		}
+		out.WriteString(s.text)
+		if opened {
+			out.WriteString(termenv.CSI + termenv.ResetSeq + "m")
+		}
+	}
+
+	return out.String()
+}
+
+func (ansiRenderer) document() (string, string) {
+	return "", ""
+}
+
+// sgr returns opening SGR sequence of the style
+// for the color profile or an empty string.
+// Ascii profile means that the output isn't colored
+// at all (see --color flag), so only colors of the input stay
+func sgr(st spanStyle, profile termenv.Profile) string {
+	if st.sgr != "" {
+		return st.sgr
+	}
+	if profile == termenv.Ascii {
+		return ""
+	}
+
+	termStyle := termenv.String()
+	if st.fg != "" {
+		termStyle = termStyle.Foreground(profile.Color(st.fg))
+	}
+	if st.bg != "" {
+		termStyle = termStyle.Background(profile.Color(st.bg))
+	}
+	switch st.style {
+	case "bold":
+		termStyle = termStyle.Bold()
+	case "faint":
+		termStyle = termStyle.Faint()
+	case "italic":
+		termStyle = termStyle.Italic()
+	case "underline":
+		termStyle = termStyle.Underline()
+	case "overline":
+		termStyle = termStyle.Overline()
+	case "crossout":
+		termStyle = termStyle.CrossOut()
+	case "reverse":
+		termStyle = termStyle.Reverse()
+	}
+
+	return strings.TrimSuffix(termStyle.Styled(""), termenv.CSI+termenv.ResetSeq+"m")
+}
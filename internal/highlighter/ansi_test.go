@@ -0,0 +1,76 @@
+package highlighter
+
+import (
+	"testing"
+
+	"github.com/muesli/termenv"
+)
+
+func TestANSILine(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile termenv.Profile
+		spans   []span
+		colored string
+	}{
+		{"Plain", termenv.TrueColor, plain("text"), "text"},
+		{
+			"Colors",
+			termenv.TrueColor,
+			[]span{{text: "red", spanStyle: spanStyle{fg: "#ff0000", bg: "21", style: "bold"}}, {text: " plain"}},
+			"\x1b[38;2;255;0;0;48;5;21;1mred\x1b[0m plain",
+		},
+		{
+			"ANSI256",
+			termenv.ANSI256,
+			[]span{{text: "red", spanStyle: spanStyle{fg: "#ff0000"}}},
+			"\x1b[38;5;196mred\x1b[0m",
+		},
+		// neither colors nor styles are shown without a color profile,
+		// but colors of the input are
+		{
+			"Ascii",
+			termenv.Ascii,
+			[]span{
+				{text: "red", spanStyle: spanStyle{fg: "#ff0000"}},
+				{text: " bold", spanStyle: spanStyle{style: "bold"}},
+				{text: " input", spanStyle: spanStyle{sgr: "\x1b[31m"}, origin: origin{kind: "input"}},
+			},
+			"red bold\x1b[31m input\x1b[0m",
+		},
+		{
+			"Input",
+			termenv.TrueColor,
+			[]span{{text: "input", spanStyle: spanStyle{sgr: "\x1b[31m\x1b[1m"}, origin: origin{kind: "input"}}},
+			"\x1b[31m\x1b[1minput\x1b[0m",
+		},
+		// the search is painted on top of the colors of the span
+		{
+			"Search",
+			termenv.TrueColor,
+			[]span{{text: "match", spanStyle: spanStyle{style: "reverse"}, under: []spanStyle{{fg: "#00ff00"}}}},
+			"\x1b[38;2;0;255;0m\x1b[7mmatch\x1b[0m",
+		},
+		// the colors under the span are reset even if the span itself isn't colored
+		{
+			"SearchWithoutStyle",
+			termenv.TrueColor,
+			[]span{{text: "match", under: []spanStyle{{fg: "#00ff00"}}}, {text: " plain"}},
+			"\x1b[38;2;0;255;0mmatch\x1b[0m plain",
+		},
+		{
+			"SearchAscii",
+			termenv.Ascii,
+			[]span{{text: "match", spanStyle: spanStyle{style: "reverse"}, under: []spanStyle{{sgr: "\x1b[31m"}}}, {text: " plain"}},
+			"\x1b[31mmatch\x1b[0m plain",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run("TestANSILine"+tt.name, func(t *testing.T) {
+			if colored := (ansiRenderer{profile: tt.profile}).line(tt.spans); colored != tt.colored {
+				t.Errorf("got %q, want %q", colored, tt.colored)
+			}
+		})
+	}
+}
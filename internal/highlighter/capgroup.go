@@ -3,7 +3,9 @@ package highlighter
 import (
 	"fmt"
 	"regexp"
+	"slices"
 	"strconv"
+	"strings"
 )
 
 // capGroup represents one capturing group in a config file
@@ -21,6 +23,21 @@ type capGroup struct {
 
 	Alternatives []capGroup `koanf:"alternatives"`
 
+	// Range lets alternatives be chosen by numeric value
+	// instead of (or in addition to) the regexp
+	Range numRange `koanf:",squash"`
+
+	// Gradient is the range of values for "gradient" style
+	Gradient gradient `koanf:"gradient"`
+
+	// Timestamp is the list of Go layouts of this group's part of
+	// the timestamp (see timeConverter). All the groups with this field
+	// in the list are parsed together as one timestamp.
+	Timestamp []string `koanf:"timestamp"`
+
+	// Redact hides the text of this group if user set --redact flag
+	Redact bool `koanf:"redact"`
+
 	RegExp *regexp.Regexp `koanf:"-"`
 }
 
@@ -50,13 +67,9 @@ func (cgl *capGroupList) init(isFormat bool) error {
 	}
 	cgl.fullRegExp = regexp.MustCompile(fullRegExp)
 
-	// build regexps for capturing groups' alternatives
-	for i, cg := range cgl.groups {
-		if len(cg.Alternatives) > 0 {
-			for j, alt := range cg.Alternatives {
-				cgl.groups[i].Alternatives[j].RegExp = regexp.MustCompile(alt.RegExpStr)
-			}
-		}
+	// build regexps and ranges for capturing groups' alternatives and gradients
+	for i := range cgl.groups {
+		cgl.groups[i].initValues()
 	}
 
 	if err := cgl.validateLinkTo(); err != nil {
@@ -96,69 +109,125 @@ func (cgl *capGroupList) validateLinkTo() error {
 	return nil
 }
 
-func (cgl *capGroupList) highlight(str string, h Highlighter) (coloredStr string) {
+func (cgl *capGroupList) highlight(str string, h Highlighter) (spans []span) {
 	matches := cgl.fullRegExp.FindStringSubmatch(str)
+	texts := cgl.texts(matches)
+	// timestamps are rewritten only if user set --tz or --time-format flags
+	if h.times != nil {
+		h.times.rewrite(cgl.groups, texts)
+	}
+
 	for i, cg := range cgl.groups {
-		match := matches[cgl.fullRegExp.SubexpIndex("capGroup"+strconv.Itoa(i))]
+		match := texts[i]
+		// the rest of the rewritten timestamp is in its first group
+		if match == "" && len(cg.Timestamp) > 0 {
+			continue
+		}
 
 		// If this group links to another, borrow that group's effective style.
-		if fg, bg, style, ok := cgl.linkedStyle(matches, cg); ok {
-			coloredStr += h.highlight(match, fg, bg, style)
+		if fg, bg, style, ok := cgl.linkedStyle(texts, cg); ok {
+			// and the gradient for "gradient" style
+			h.gradient = &cgl.groups[cgl.linkTarget(cg)].Gradient
+			spans = append(spans, claimGroup(h.highlightRedacted(match, h.redact(match, &cg), fg, bg, style), cg.Name, "")...)
 
 			continue
 		}
-		coloredStr += cg.highlight(match, h)
+		spans = append(spans, cg.highlight(match, strings.Join(texts[i+1:], ""), h)...)
 	}
 
-	return coloredStr
+	return spans
 }
 
-// highlight colorizes string and applies a style
-func (cg *capGroup) highlight(str string, h Highlighter) string {
-	if len(cg.Alternatives) > 0 {
-		for _, alt := range cg.Alternatives {
-			if alt.RegExp.MatchString(str) {
-				return h.highlight(str, alt.Foreground, alt.Background, alt.Style)
-			}
+// texts returns the text matched by every group
+func (cgl *capGroupList) texts(matches []string) []string {
+	texts := make([]string, len(cgl.groups))
+	for i := range cgl.groups {
+		texts[i] = matches[cgl.fullRegExp.SubexpIndex("capGroup"+strconv.Itoa(i))]
+	}
+
+	return texts
+}
+
+// highlight colorizes string and applies a style.
+// The style is chosen by the original text even if it's redacted.
+// rest is the text of the next groups (see alternative).
+func (cg *capGroup) highlight(str, rest string, h Highlighter) []span {
+	// the group and its alternatives share the gradient
+	h.gradient = &cg.Gradient
+	if alt := cg.alternative(str, rest); alt != nil {
+		return claimGroup(h.highlightRedacted(str, h.redact(str, cg), alt.Foreground, alt.Background, alt.Style), cg.Name, alt.Name)
+	}
+
+	return claimGroup(h.highlightRedacted(str, h.redact(str, cg), cg.Foreground, cg.Background, cg.Style), cg.Name, "")
+}
+
+// alternative returns the first alternative that matches the string or nil.
+// rest is the text right after the string. The unit of a number
+// for numeric ranges may be there (e.g. in the next capturing group).
+func (cg *capGroup) alternative(str, rest string) *capGroup {
+	for i := range cg.Alternatives {
+		if cg.Alternatives[i].matchAlternative(str, rest) {
+			return &cg.Alternatives[i]
 		}
 	}
 
-	return h.highlight(str, cg.Foreground, cg.Background, cg.Style)
+	return nil
+}
+
+// matchAlternative reports whether the string matches both
+// the regexp and the numeric range of the alternative (if they are set)
+func (alt *capGroup) matchAlternative(str, rest string) bool {
+	if alt.RegExp != nil && !alt.RegExp.MatchString(str) {
+		return false
+	}
+
+	return !alt.Range.isSet() || alt.Range.match(str, rest)
+}
+
+// initValues compiles regexps and parses numeric ranges of the
+// alternatives and the range of the gradient (they must be validated first)
+func (cg *capGroup) initValues() {
+	for j := range cg.Alternatives {
+		alt := &cg.Alternatives[j]
+		if alt.RegExpStr != "" {
+			alt.RegExp = regexp.MustCompile(alt.RegExpStr)
+		}
+		_ = alt.Range.init()
+	}
+	if cg.Gradient.isSet() {
+		_ = cg.Gradient.init()
+	}
 }
 
 // linkedStyle returns the effective style of the target group if cg.LinkTo is set:
 // - if the target has matching alternatives, return that alt's fg/bg/style
 // - else return the target's default fg/bg/style
-func (cgl *capGroupList) linkedStyle(matches []string, cg capGroup) (fg, bg, style string, ok bool) {
+func (cgl *capGroupList) linkedStyle(texts []string, cg capGroup) (fg, bg, style string, ok bool) {
 	if cg.LinkTo == "" || cgl.index == nil {
 		return "", "", "", false
 	}
-	// Follow chains (A->B->C). Stop on first non-link group
-	// Don't worry about cycle, they are already handled by validateLinkTo()
-	curIdx := cgl.index[cg.LinkTo]
-	for {
-
-		target := cgl.groups[curIdx]
-		// hop if chained link
-		if target.LinkTo != "" {
-			curIdx = cgl.index[target.LinkTo]
 
-			continue
-		}
+	// compute effective style of the terminal target
+	curIdx := cgl.linkTarget(cg)
+	target := cgl.groups[curIdx]
 
-		// compute effective style of the terminal target
-		match := matches[cgl.fullRegExp.SubexpIndex("capGroup"+strconv.Itoa(curIdx))]
+	if alt := target.alternative(texts[curIdx], strings.Join(texts[curIdx+1:], "")); alt != nil {
+		return alt.Foreground, alt.Background, alt.Style, true
+	}
 
-		if len(target.Alternatives) > 0 {
-			for _, alt := range target.Alternatives {
-				if alt.RegExp.MatchString(match) {
-					return alt.Foreground, alt.Background, alt.Style, true
-				}
-			}
-		}
+	return target.Foreground, target.Background, target.Style, true
+}
 
-		return target.Foreground, target.Background, target.Style, true
+// linkTarget returns the index of the group cg.LinkTo refers to.
+// Follow chains (A->B->C). Stop on first non-link group
+// Don't worry about cycle, they are already handled by validateLinkTo()
+func (cgl *capGroupList) linkTarget(cg capGroup) int {
+	curIdx := cgl.index[cg.LinkTo]
+	for cgl.groups[curIdx].LinkTo != "" {
+		curIdx = cgl.index[cgl.groups[curIdx].LinkTo]
 	}
+
+	return curIdx
 }
 
 func (cgl *capGroupList) validate() error {
@@ -182,6 +251,90 @@ func (cgl *capGroupList) validate() error {
 
 // validate checks one capturing group's fields match corresponding patterns
 func (cg *capGroup) validate() error {
+	if cg.Range.isSet() {
+		return fmt.Errorf(
+			"[capturing group: %s] only alternatives can have \"gt\", \"lt\" and \"between\" fields", cg.Name)
+	}
+
+	if err := cg.validateName(); err != nil {
+		return err
+	}
+	if err := cg.validateRegExp(); err != nil {
+		return err
+	}
+	if err := cg.validateStyle(); err != nil {
+		return err
+	}
+	if err := cg.validateGradient(); err != nil {
+		return err
+	}
+	if slices.Contains(cg.Timestamp, "") {
+		return fmt.Errorf("[capturing group: %s] \"timestamp\" field can't have empty layouts", cg.Name)
+	}
+
+	// check alternatives
+	for _, alt := range cg.Alternatives {
+		if err := alt.validateAlternative(); err != nil {
+			return fmt.Errorf("[capturing group: %s] %s", cg.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// validateAlternative does the same as validate for alternatives.
+// Alternatives with a numeric range don't have to have a regexp.
+func (alt *capGroup) validateAlternative() error {
+	if err := alt.validateName(); err != nil {
+		return err
+	}
+	if alt.RegExpStr != "" || !alt.Range.isSet() {
+		if err := alt.validateRegExp(); err != nil {
+			return err
+		}
+	}
+	if err := alt.Range.init(); err != nil {
+		return fmt.Errorf("[capturing group: %s] %s", alt.Name, err)
+	}
+	if alt.Gradient.isSet() {
+		return fmt.Errorf("[capturing group: %s] only capturing groups can have \"gradient\" field", alt.Name)
+	}
+	if len(alt.Timestamp) > 0 {
+		return fmt.Errorf("[capturing group: %s] only capturing groups can have \"timestamp\" field", alt.Name)
+	}
+	if alt.Redact {
+		return fmt.Errorf("[capturing group: %s] only capturing groups can have \"redact\" field", alt.Name)
+	}
+
+	return alt.validateStyle()
+}
+
+// validateGradient checks the range of the gradient and its colors
+// if the group or its alternatives are colored with "gradient" style
+func (cg *capGroup) validateGradient() error {
+	used := cg.Style == "gradient" ||
+		slices.ContainsFunc(cg.Alternatives, func(alt capGroup) bool { return alt.Style == "gradient" })
+
+	if !cg.Gradient.isSet() {
+		if used {
+			return fmt.Errorf("[capturing group: %s] \"gradient\" style requires \"gradient\" field", cg.Name)
+		}
+
+		return nil
+	}
+	if err := cg.Gradient.init(); err != nil {
+		return fmt.Errorf("[capturing group: %s] [gradient] %s", cg.Name, err)
+	}
+	if used {
+		if err := cg.Gradient.validateColors(); err != nil {
+			return fmt.Errorf("[capturing group: %s] [gradient] %s", cg.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (cg *capGroup) validateName() error {
 	// check name
 	if cg.Name == "" {
 		return fmt.Errorf("capturing group can't have empty \"name\" field")
@@ -192,6 +345,10 @@ func (cg *capGroup) validate() error {
 			cg.Name)
 	}
 
+	return nil
+}
+
+func (cg *capGroup) validateRegExp() error {
 	// check regexp
 	if cg.RegExpStr == "" {
 		return fmt.Errorf("[capturing group: %s] empty \"regexp\" field", cg.Name)
@@ -210,6 +367,10 @@ func (cg *capGroup) validate() error {
 			err)
 	}
 
+	return nil
+}
+
+func (cg *capGroup) validateStyle() error {
 	// check foreground
 	if !colorRegExp.MatchString(cg.Foreground) {
 		return fmt.Errorf(
@@ -231,14 +392,5 @@ func (cg *capGroup) validate() error {
 			cg.Name, cg.Style, styleRegExp)
 	}
 
-	// check alternatives
-	if len(cg.Alternatives) > 0 {
-		for _, alt := range cg.Alternatives {
-			if err := alt.validate(); err != nil {
-				return fmt.Errorf("[capturing group: %s] %s", cg.Name, err)
-			}
-		}
-	}
-
 	return nil
 }
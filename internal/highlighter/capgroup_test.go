@@ -3,6 +3,7 @@ package highlighter
 import (
 	"fmt"
 	"regexp"
+	"slices"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -21,6 +22,20 @@ func compareCapGroups(group1, group2 capGroup) error {
 	if len(group1.Alternatives) != len(group2.Alternatives) {
 		return fmt.Errorf("alternatives have different length")
 	}
+	if group1.Range.Gt != group2.Range.Gt || group1.Range.Lt != group2.Range.Lt ||
+		!cmp.Equal(group1.Range.Between, group2.Range.Between) {
+		return fmt.Errorf("numeric ranges aren't equal")
+	}
+	if group1.Gradient.From != group2.Gradient.From || group1.Gradient.To != group2.Gradient.To ||
+		group1.Gradient.Scale != group2.Gradient.Scale || !slices.Equal(group1.Gradient.Colors, group2.Gradient.Colors) {
+		return fmt.Errorf("gradients aren't equal")
+	}
+	if !slices.Equal(group1.Timestamp, group2.Timestamp) {
+		return fmt.Errorf("timestamp layouts aren't equal")
+	}
+	if group1.Redact != group2.Redact {
+		return fmt.Errorf("redact %t and %t are different", group1.Redact, group2.Redact)
+	}
 	if group1.LinkTo != group2.LinkTo {
 		return fmt.Errorf("link-to %s and %s are different", group1.LinkTo, group2.LinkTo)
 	}
@@ -73,20 +88,24 @@ func compareCapGroupLists(list1, list2 capGroupList) error {
 func TestCapGroupsListInitGood(t *testing.T) {
 	formatCapGroupList := &capGroupList{
 		[]capGroup{
-			{"one", `(\d{1,3}(\.\d{1,3}){3} )`, "", "", "", "", nil, nil},
-			{"two", `([^ ]+ )`, "", "", "", "one", nil, nil},
-			{"three", `(\[.+\] )`, "", "", "", "four", nil, nil},
-			{"four", `("[^"]+")`, "", "", "", "five", nil, nil},
+			{"one", `(\d{1,3}(\.\d{1,3}){3} )`, "", "", "", "", nil, numRange{}, gradient{}, nil, false, nil},
+			{"two", `([^ ]+ )`, "", "", "", "one", nil, numRange{}, gradient{}, nil, false, nil},
+			{"three", `(\[.+\] )`, "", "", "", "four", nil, numRange{}, gradient{}, nil, false, nil},
+			{"four", `("[^"]+")`, "", "", "", "five", nil, numRange{}, gradient{}, nil, false, nil},
 			{
 				"five",
 				`(\d\d\d)`, "", "", "", "",
 				[]capGroup{
-					{"alt1", `(1\d\d)`, "", "", "", "", nil, nil},
-					{"alt2", `(2\d\d)`, "", "", "", "", nil, nil},
-					{"alt3", `(3\d\d)`, "", "", "", "", nil, nil},
-					{"alt4", `(4\d\d)`, "", "", "", "", nil, nil},
-					{"alt5", `(5\d\d)`, "", "", "", "", nil, nil},
+					{"alt1", `(1\d\d)`, "", "", "", "", nil, numRange{}, gradient{}, nil, false, nil},
+					{"alt2", `(2\d\d)`, "", "", "", "", nil, numRange{}, gradient{}, nil, false, nil},
+					{"alt3", `(3\d\d)`, "", "", "", "", nil, numRange{}, gradient{}, nil, false, nil},
+					{"alt4", `(4\d\d)`, "", "", "", "", nil, numRange{}, gradient{}, nil, false, nil},
+					{"alt5", `(5\d\d)`, "", "", "", "", nil, numRange{}, gradient{}, nil, false, nil},
 				},
+				numRange{},
+				gradient{},
+				nil,
+				false,
 				nil,
 			},
 		},
@@ -96,20 +115,24 @@ func TestCapGroupsListInitGood(t *testing.T) {
 
 	correctFormatCapGroupList := capGroupList{
 		[]capGroup{
-			{"one", `(\d{1,3}(\.\d{1,3}){3} )`, "", "", "", "", nil, nil},
-			{"two", `([^ ]+ )`, "", "", "", "one", nil, nil},
-			{"three", `(\[.+\] )`, "", "", "", "four", nil, nil},
-			{"four", `("[^"]+")`, "", "", "", "five", nil, nil},
+			{"one", `(\d{1,3}(\.\d{1,3}){3} )`, "", "", "", "", nil, numRange{}, gradient{}, nil, false, nil},
+			{"two", `([^ ]+ )`, "", "", "", "one", nil, numRange{}, gradient{}, nil, false, nil},
+			{"three", `(\[.+\] )`, "", "", "", "four", nil, numRange{}, gradient{}, nil, false, nil},
+			{"four", `("[^"]+")`, "", "", "", "five", nil, numRange{}, gradient{}, nil, false, nil},
 			{
 				"five",
 				`(\d\d\d)`, "", "", "", "",
 				[]capGroup{
-					{"alt1", `(1\d\d)`, "", "", "", "", nil, regexp.MustCompile(`(1\d\d)`)},
-					{"alt2", `(2\d\d)`, "", "", "", "", nil, regexp.MustCompile(`(2\d\d)`)},
-					{"alt3", `(3\d\d)`, "", "", "", "", nil, regexp.MustCompile(`(3\d\d)`)},
-					{"alt4", `(4\d\d)`, "", "", "", "", nil, regexp.MustCompile(`(4\d\d)`)},
-					{"alt5", `(5\d\d)`, "", "", "", "", nil, regexp.MustCompile(`(5\d\d)`)},
+					{"alt1", `(1\d\d)`, "", "", "", "", nil, numRange{}, gradient{}, nil, false, regexp.MustCompile(`(1\d\d)`)},
+					{"alt2", `(2\d\d)`, "", "", "", "", nil, numRange{}, gradient{}, nil, false, regexp.MustCompile(`(2\d\d)`)},
+					{"alt3", `(3\d\d)`, "", "", "", "", nil, numRange{}, gradient{}, nil, false, regexp.MustCompile(`(3\d\d)`)},
+					{"alt4", `(4\d\d)`, "", "", "", "", nil, numRange{}, gradient{}, nil, false, regexp.MustCompile(`(4\d\d)`)},
+					{"alt5", `(5\d\d)`, "", "", "", "", nil, numRange{}, gradient{}, nil, false, regexp.MustCompile(`(5\d\d)`)},
 				},
+				numRange{},
+				gradient{},
+				nil,
+				false,
 				nil,
 			},
 		},
@@ -119,8 +142,8 @@ func TestCapGroupsListInitGood(t *testing.T) {
 
 	patternCapGroupList := &capGroupList{
 		[]capGroup{
-			{"one", `(\d{1,3}(\.\d{1,3}){3})`, "", "", "", "two", nil, nil},
-			{"two", `(.*)`, "", "", "", "", nil, nil},
+			{"one", `(\d{1,3}(\.\d{1,3}){3})`, "", "", "", "two", nil, numRange{}, gradient{}, nil, false, nil},
+			{"two", `(.*)`, "", "", "", "", nil, numRange{}, gradient{}, nil, false, nil},
 		},
 		nil,
 		nil,
@@ -128,8 +151,8 @@ func TestCapGroupsListInitGood(t *testing.T) {
 
 	correctPatternCapGroupList := capGroupList{
 		[]capGroup{
-			{"one", `(\d{1,3}(\.\d{1,3}){3})`, "", "", "", "two", nil, nil},
-			{"two", `(.*)`, "", "", "", "", nil, nil},
+			{"one", `(\d{1,3}(\.\d{1,3}){3})`, "", "", "", "two", nil, numRange{}, gradient{}, nil, false, nil},
+			{"two", `(.*)`, "", "", "", "", nil, numRange{}, gradient{}, nil, false, nil},
 		},
 		regexp.MustCompile(`(?P<capGroup0>(?:\d{1,3}(\.\d{1,3}){3}))(?P<capGroup1>(?:.*))`),
 		map[string]int{"one": 0, "two": 1},
@@ -163,9 +186,9 @@ func TestCapGroupsListInitBad(t *testing.T) {
 			`[capturing group: one] link-to "hello" refers to unknown capturing group`,
 			capGroupList{
 				[]capGroup{
-					{"one", `(\d+:)`, "", "", "", "hello", []capGroup{}, nil},
-					{"two", `(\d+:)`, "", "", "", "two", []capGroup{}, nil},
-					{"three", `(\d+:)`, "", "", "", "", []capGroup{}, nil},
+					{"one", `(\d+:)`, "", "", "", "hello", []capGroup{}, numRange{}, gradient{}, nil, false, nil},
+					{"two", `(\d+:)`, "", "", "", "two", []capGroup{}, numRange{}, gradient{}, nil, false, nil},
+					{"three", `(\d+:)`, "", "", "", "", []capGroup{}, numRange{}, gradient{}, nil, false, nil},
 				},
 				nil,
 				nil,
@@ -191,9 +214,9 @@ func TestCapGroupsValidateLinkTo(t *testing.T) {
 			`[capturing group: one] link-to "hello" refers to unknown capturing group`,
 			capGroupList{
 				[]capGroup{
-					{"one", `(\d+:)`, "", "", "", "hello", []capGroup{}, nil},
-					{"two", `(\d+:)`, "", "", "", "two", []capGroup{}, nil},
-					{"three", `(\d+:)`, "", "", "", "", []capGroup{}, nil},
+					{"one", `(\d+:)`, "", "", "", "hello", []capGroup{}, numRange{}, gradient{}, nil, false, nil},
+					{"two", `(\d+:)`, "", "", "", "two", []capGroup{}, numRange{}, gradient{}, nil, false, nil},
+					{"three", `(\d+:)`, "", "", "", "", []capGroup{}, numRange{}, gradient{}, nil, false, nil},
 				},
 				nil,
 				nil,
@@ -203,8 +226,8 @@ func TestCapGroupsValidateLinkTo(t *testing.T) {
 			`[capturing group: one] cyclic link-to detected`,
 			capGroupList{
 				[]capGroup{
-					{"one", `(\d+:)`, "", "", "", "two", []capGroup{}, nil},
-					{"two", `(\d+:)`, "", "", "", "one", []capGroup{}, nil},
+					{"one", `(\d+:)`, "", "", "", "two", []capGroup{}, numRange{}, gradient{}, nil, false, nil},
+					{"two", `(\d+:)`, "", "", "", "one", []capGroup{}, numRange{}, gradient{}, nil, false, nil},
 				},
 				nil,
 				nil,
@@ -214,9 +237,9 @@ func TestCapGroupsValidateLinkTo(t *testing.T) {
 			`[capturing group: one] cyclic link-to detected`,
 			capGroupList{
 				[]capGroup{
-					{"one", `(\d+:)`, "", "", "", "two", []capGroup{}, nil},
-					{"two", `(\d+:)`, "", "", "", "three", []capGroup{}, nil},
-					{"three", `(\d+:)`, "", "", "", "one", []capGroup{}, nil},
+					{"one", `(\d+:)`, "", "", "", "two", []capGroup{}, numRange{}, gradient{}, nil, false, nil},
+					{"two", `(\d+:)`, "", "", "", "three", []capGroup{}, numRange{}, gradient{}, nil, false, nil},
+					{"three", `(\d+:)`, "", "", "", "one", []capGroup{}, numRange{}, gradient{}, nil, false, nil},
 				},
 				nil,
 				nil,
@@ -313,16 +336,20 @@ func TestCapGroupsLinkedStyle(t *testing.T) {
 
 	cgl := &capGroupList{
 		[]capGroup{
-			{"one", `(hello )`, "", "", "", "three", nil, nil},
-			{"two", `(--- )`, "", "", "", "one", nil, nil},
+			{"one", `(hello )`, "", "", "", "three", nil, numRange{}, gradient{}, nil, false, nil},
+			{"two", `(--- )`, "", "", "", "one", nil, numRange{}, gradient{}, nil, false, nil},
 			{
 				"three",
 				`(\d\d\d)`, "#ffffff", "", "", "",
 				[]capGroup{
-					{"alt1", `(1\d\d)`, "#ff0000", "", "", "", nil, nil},
-					{"alt2", `(2\d\d)`, "", "#00ff00", "", "", nil, nil},
-					{"alt3", `(3\d\d)`, "", "", "bold", "", nil, nil},
+					{"alt1", `(1\d\d)`, "#ff0000", "", "", "", nil, numRange{}, gradient{}, nil, false, nil},
+					{"alt2", `(2\d\d)`, "", "#00ff00", "", "", nil, numRange{}, gradient{}, nil, false, nil},
+					{"alt3", `(3\d\d)`, "", "", "bold", "", nil, numRange{}, gradient{}, nil, false, nil},
 				},
+				numRange{},
+				gradient{},
+				nil,
+				false,
 				nil,
 			},
 		},
@@ -336,8 +363,8 @@ func TestCapGroupsLinkedStyle(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run("TestCapGroupsLinkedStyle"+tt.str, func(t *testing.T) {
-			matches := cgl.fullRegExp.FindStringSubmatch(tt.str)
-			fg, bg, style, ok := cgl.linkedStyle(matches, cgl.groups[tt.cgIndex])
+			texts := cgl.texts(cgl.fullRegExp.FindStringSubmatch(tt.str))
+			fg, bg, style, ok := cgl.linkedStyle(texts, cgl.groups[tt.cgIndex])
 			if fg != tt.fg || bg != tt.bg || style != tt.style || ok != tt.ok {
 				t.Errorf("got (%s, %s, %s, %v), want (%s, %s, %s, %v)", fg, bg, style, ok, tt.fg, tt.bg, tt.style, tt.ok)
 			}
@@ -354,20 +381,20 @@ func TestCapGroupsListCheck(t *testing.T) {
 			"%!s(<nil>)",
 			capGroupList{
 				[]capGroup{
-					{"1", `(\d+:)`, "", "", "", "", []capGroup{}, nil},
-					{"2", `(\d+:)`, "", "", "bold", "", []capGroup{}, nil},
-					{"3", `(\d+:)`, "", "#ff00ff", "", "", []capGroup{}, nil},
-					{"4", `(\d+:)`, "", "#ff0000", "underline", "", []capGroup{}, nil},
-					{"5", `(\d+:)`, "#0f0f0f", "", "", "", []capGroup{}, nil},
-					{"6", `(\d+:)`, "#0f0f0f", "", "faint", "", []capGroup{}, nil},
-					{"7", `(\d+:)`, "#0f0f0f", "#ff00ff", "", "", []capGroup{}, nil},
-					{"8", `(\d+:)`, "#0f0f0f", "#ff0000", "italic", "", []capGroup{}, nil},
-					{"9", `(\d+:)`, "#0f0f0f", "1", "overline", "", []capGroup{}, nil},
-					{"10", `(\d+:)`, "37", "#ff0000", "crossout", "", []capGroup{}, nil},
-					{"11", `(\d+:)`, "214", "15", "reverse", "", []capGroup{}, nil},
-					{"12", `(\d+:)`, "#0f0f0f", "#ff0000", "patterns", "", []capGroup{}, nil},
-					{"13", `(\d+:)`, "#0f0f0f", "#ff0000", "words", "", []capGroup{}, nil},
-					{"14", `(\d+:)`, "#0f0f0f", "#ff0000", "patterns-and-words", "", []capGroup{}, nil},
+					{"1", `(\d+:)`, "", "", "", "", []capGroup{}, numRange{}, gradient{}, nil, false, nil},
+					{"2", `(\d+:)`, "", "", "bold", "", []capGroup{}, numRange{}, gradient{}, nil, false, nil},
+					{"3", `(\d+:)`, "", "#ff00ff", "", "", []capGroup{}, numRange{}, gradient{}, nil, false, nil},
+					{"4", `(\d+:)`, "", "#ff0000", "underline", "", []capGroup{}, numRange{}, gradient{}, nil, false, nil},
+					{"5", `(\d+:)`, "#0f0f0f", "", "", "", []capGroup{}, numRange{}, gradient{}, nil, false, nil},
+					{"6", `(\d+:)`, "#0f0f0f", "", "faint", "", []capGroup{}, numRange{}, gradient{}, nil, false, nil},
+					{"7", `(\d+:)`, "#0f0f0f", "#ff00ff", "", "", []capGroup{}, numRange{}, gradient{}, nil, false, nil},
+					{"8", `(\d+:)`, "#0f0f0f", "#ff0000", "italic", "", []capGroup{}, numRange{}, gradient{}, nil, false, nil},
+					{"9", `(\d+:)`, "#0f0f0f", "1", "overline", "", []capGroup{}, numRange{}, gradient{}, nil, false, nil},
+					{"10", `(\d+:)`, "37", "#ff0000", "crossout", "", []capGroup{}, numRange{}, gradient{}, nil, false, nil},
+					{"11", `(\d+:)`, "214", "15", "reverse", "", []capGroup{}, numRange{}, gradient{}, nil, false, nil},
+					{"12", `(\d+:)`, "#0f0f0f", "#ff0000", "patterns", "", []capGroup{}, numRange{}, gradient{}, nil, false, nil},
+					{"13", `(\d+:)`, "#0f0f0f", "#ff0000", "words", "", []capGroup{}, numRange{}, gradient{}, nil, false, nil},
+					{"14", `(\d+:)`, "#0f0f0f", "#ff0000", "patterns-and-words", "", []capGroup{}, numRange{}, gradient{}, nil, false, nil},
 				},
 				nil,
 				nil,
@@ -377,7 +404,7 @@ func TestCapGroupsListCheck(t *testing.T) {
 			`capturing group can't have empty "name" field`,
 			capGroupList{
 				[]capGroup{
-					{"", `(.*)`, "", "", "", "", []capGroup{}, nil},
+					{"", `(.*)`, "", "", "", "", []capGroup{}, numRange{}, gradient{}, nil, false, nil},
 				},
 				nil,
 				nil,
@@ -387,7 +414,7 @@ func TestCapGroupsListCheck(t *testing.T) {
 			`[capturing group: fg] capturing group cannot be named "fg", "bg", "style", or "link-to"`,
 			capGroupList{
 				[]capGroup{
-					{"fg", `(.*)`, "", "", "", "", []capGroup{}, nil},
+					{"fg", `(.*)`, "", "", "", "", []capGroup{}, numRange{}, gradient{}, nil, false, nil},
 				},
 				nil,
 				nil,
@@ -397,9 +424,9 @@ func TestCapGroupsListCheck(t *testing.T) {
 			`[capturing group: one] capturing group names must be unique`,
 			capGroupList{
 				[]capGroup{
-					{"one", `(.*)`, "", "", "", "", []capGroup{}, nil},
-					{"two", `(.*)`, "", "", "", "", []capGroup{}, nil},
-					{"one", `(.*)`, "", "", "", "", []capGroup{}, nil},
+					{"one", `(.*)`, "", "", "", "", []capGroup{}, numRange{}, gradient{}, nil, false, nil},
+					{"two", `(.*)`, "", "", "", "", []capGroup{}, numRange{}, gradient{}, nil, false, nil},
+					{"one", `(.*)`, "", "", "", "", []capGroup{}, numRange{}, gradient{}, nil, false, nil},
 				},
 				nil,
 				nil,
@@ -409,7 +436,7 @@ func TestCapGroupsListCheck(t *testing.T) {
 			`[capturing group: one] regexp () must start with ( and end with )`,
 			capGroupList{
 				[]capGroup{
-					{"one", `()`, "", "", "", "", []capGroup{}, nil},
+					{"one", `()`, "", "", "", "", []capGroup{}, numRange{}, gradient{}, nil, false, nil},
 				},
 				nil,
 				nil,
@@ -419,7 +446,7 @@ func TestCapGroupsListCheck(t *testing.T) {
 			`[capturing group: one] empty "regexp" field`,
 			capGroupList{
 				[]capGroup{
-					{"one", ``, "", "", "", "", []capGroup{}, nil},
+					{"one", ``, "", "", "", "", []capGroup{}, numRange{}, gradient{}, nil, false, nil},
 				},
 				nil,
 				nil,
@@ -429,7 +456,7 @@ func TestCapGroupsListCheck(t *testing.T) {
 			`[capturing group: one] regexp ) must start with ( and end with )`,
 			capGroupList{
 				[]capGroup{
-					{"one", `)`, "", "", "", "", []capGroup{}, nil},
+					{"one", `)`, "", "", "", "", []capGroup{}, numRange{}, gradient{}, nil, false, nil},
 				},
 				nil,
 				nil,
@@ -439,7 +466,7 @@ func TestCapGroupsListCheck(t *testing.T) {
 			`[capturing group: one] regexp (\d\d-\d\d-\d\d must start with ( and end with )`,
 			capGroupList{
 				[]capGroup{
-					{"one", `(\d\d-\d\d-\d\d`, "", "", "", "", []capGroup{}, nil},
+					{"one", `(\d\d-\d\d-\d\d`, "", "", "", "", []capGroup{}, numRange{}, gradient{}, nil, false, nil},
 				},
 				nil,
 				nil,
@@ -449,7 +476,7 @@ func TestCapGroupsListCheck(t *testing.T) {
 			fmt.Sprintf(`[capturing group: one] foreground color ff00df doesn't match %s regexp`, colorRegExp),
 			capGroupList{
 				[]capGroup{
-					{"one", `(\d+)`, "ff00df", "", "", "", []capGroup{}, nil},
+					{"one", `(\d+)`, "ff00df", "", "", "", []capGroup{}, numRange{}, gradient{}, nil, false, nil},
 				},
 				nil,
 				nil,
@@ -459,7 +486,7 @@ func TestCapGroupsListCheck(t *testing.T) {
 			fmt.Sprintf(`[capturing group: one] background color 7000 doesn't match %s regexp`, colorRegExp),
 			capGroupList{
 				[]capGroup{
-					{"one", `(\d+)`, "", "7000", "", "", []capGroup{}, nil},
+					{"one", `(\d+)`, "", "7000", "", "", []capGroup{}, numRange{}, gradient{}, nil, false, nil},
 				},
 				nil,
 				nil,
@@ -469,7 +496,7 @@ func TestCapGroupsListCheck(t *testing.T) {
 			fmt.Sprintf(`[capturing group: one] style NotAStyle doesn't match %s regexp`, styleRegExp),
 			capGroupList{
 				[]capGroup{
-					{"one", `(\d+)`, "", "", "NotAStyle", "", []capGroup{}, nil},
+					{"one", `(\d+)`, "", "", "NotAStyle", "", []capGroup{}, numRange{}, gradient{}, nil, false, nil},
 				},
 				nil,
 				nil,
@@ -479,7 +506,7 @@ func TestCapGroupsListCheck(t *testing.T) {
 			`[capturing group: one] [capturing group: alt1] regexp hello must start with ( and end with )`,
 			capGroupList{
 				[]capGroup{
-					{"one", `(\d+)`, "", "", "", "", []capGroup{{"alt1", "hello", "", "", "", "", nil, nil}}, nil},
+					{"one", `(\d+)`, "", "", "", "", []capGroup{{"alt1", "hello", "", "", "", "", nil, numRange{}, gradient{}, nil, false, nil}}, numRange{}, gradient{}, nil, false, nil},
 				},
 				nil,
 				nil,
@@ -489,7 +516,7 @@ func TestCapGroupsListCheck(t *testing.T) {
 			"[capturing group: one] error parsing regexp: unexpected ): `\\d+)(\\d+`\nCheck that the \"regexp\" starts with an opening bracket ( and ends with a paired closing bracket )\nThat is, your \"regexp\" must be within one large capturing group and contain a valid regular expression",
 			capGroupList{
 				[]capGroup{
-					{"one", `(\d+)(\d+)`, "", "", "", "", nil, nil},
+					{"one", `(\d+)(\d+)`, "", "", "", "", nil, numRange{}, gradient{}, nil, false, nil},
 				},
 				nil,
 				nil,
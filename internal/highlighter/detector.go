@@ -0,0 +1,126 @@
+package highlighter
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// formatDetector finds the format of a line.
+//
+// Logs are usually homogeneous (e.g. the whole stream is one nginx access
+// log), so the format that matched most recently is tried first and
+// the rest of the formats are tried only if it doesn't match. Formats with
+// higher priority than the recent one are still tried before it.
+//
+// The detector is shared by all copies of the highlighter,
+// so it's safe for concurrent use.
+type formatDetector struct {
+	// index of the recently matched format or -1
+	recent atomic.Int64
+
+	// statistics for --debug
+	collectStats bool
+	lines        atomic.Int64
+	recentHits   atomic.Int64
+	matches      []atomic.Int64
+}
+
+func newFormatDetector(formats formatList, collectStats bool) *formatDetector {
+	d := &formatDetector{collectStats: collectStats}
+	d.recent.Store(-1)
+	if collectStats {
+		d.matches = make([]atomic.Int64, len(formats))
+	}
+
+	return d
+}
+
+// detect returns the index of the format that matches the line or -1
+func (d *formatDetector) detect(line string, formats formatList) int {
+	if d == nil {
+		return formats.detect(line, -1)
+	}
+
+	if d.collectStats {
+		d.lines.Add(1)
+	}
+
+	recent := int(d.recent.Load())
+	var i int
+	if recent >= 0 && formats[recent].match(line) {
+		// formats with higher priority win anyway
+		// (formats are sorted by priority)
+		i = recent
+		for j := 0; j < recent && formats[j].Priority > formats[recent].Priority; j++ {
+			if formats[j].match(line) {
+				i = j
+
+				break
+			}
+		}
+		if i == recent && d.collectStats {
+			d.recentHits.Add(1)
+		}
+	} else {
+		i = formats.detect(line, recent)
+	}
+
+	if i >= 0 {
+		// don't touch the shared value if it's the same
+		if i != recent {
+			d.recent.Store(int64(i))
+		}
+		if d.collectStats {
+			d.matches[i].Add(1)
+		}
+	}
+
+	return i
+}
+
+// stats returns the statistics of the format detection
+// for formats in the order they are tried
+func (d *formatDetector) stats(formats formatList) string {
+	if d == nil || !d.collectStats {
+		return ""
+	}
+
+	lines := d.lines.Load()
+	var b strings.Builder
+	fmt.Fprintf(&b, "[debug] format detection: %d lines, recently matched format matched %d of them%s\n",
+		lines, d.recentHits.Load(), percent(d.recentHits.Load(), lines))
+	for i, lf := range formats {
+		matches := d.matches[i].Load()
+		fmt.Fprintf(&b, "[debug] format %s (priority %d): %d matches%s\n",
+			lf.Name, lf.Priority, matches, percent(matches, lines))
+	}
+	unmatched := lines
+	for i := range d.matches {
+		unmatched -= d.matches[i].Load()
+	}
+	fmt.Fprintf(&b, "[debug] no format: %d lines%s\n", unmatched, percent(unmatched, lines))
+
+	return b.String()
+}
+
+// percent returns " (n%)" where n is part of total
+func percent(part, total int64) string {
+	if total == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(" (%.1f%%)", float64(part)*100/float64(total))
+}
+
+// detect returns the index of the first format that matches the line
+// or -1. The format at index skip isn't tried.
+func (fl formatList) detect(line string, skip int) int {
+	for i := range fl {
+		if i != skip && fl[i].match(line) {
+			return i
+		}
+	}
+
+	return -1
+}
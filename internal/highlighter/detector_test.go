@@ -0,0 +1,143 @@
+package highlighter
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/deponian/logalize/internal/config"
+)
+
+// debugInfoRegExp matches debug info added by --debug flag
+var debugInfoRegExp = regexp.MustCompile(`\x1b\[7m\[[fpw]\(/?[^)]*\)\]\x1b\[0m`)
+
+func TestFormatDetectorDetect(t *testing.T) {
+	tests := []struct {
+		plain   string
+		colored string
+	}{
+		{"first 1", "\x1b[38;2;255;0;0mfirst\x1b[0m\x1b[38;2;0;255;0m 1\x1b[0m"},
+		{"first 2", "\x1b[38;2;255;0;0mfirst\x1b[0m\x1b[38;2;0;255;0m 2\x1b[0m"},
+		{"second 3", "\x1b[38;2;0;0;255msecond\x1b[0m\x1b[38;2;0;255;0m 3\x1b[0m"},
+		{"second 4", "\x1b[38;2;0;0;255msecond\x1b[0m\x1b[38;2;0;255;0m 4\x1b[0m"},
+		{"third 5", "third \x1b[38;2;255;255;0m5\x1b[0m"},
+		{"second 6", "\x1b[38;2;0;0;255msecond\x1b[0m\x1b[38;2;0;255;0m 6\x1b[0m"},
+		{"first 7", "\x1b[38;2;255;0;0mfirst\x1b[0m\x1b[38;2;0;255;0m 7\x1b[0m"},
+	}
+
+	hl, err := newTestHighlighter(t, "./testdata/detector/detect/01_main.yaml", config.Options{Debug: true})
+	if err != nil {
+		t.Fatalf("NewHighlighter() failed with this error: %s", err)
+	}
+
+	for _, tt := range tests {
+		// remove debug info to compare the colors only
+		colored := hl.Colorize(tt.plain)
+		colored = debugInfoRegExp.ReplaceAllString(colored, "")
+		if colored != tt.colored {
+			t.Errorf("got %q, want %q", colored, tt.colored)
+		}
+	}
+
+	stats := "[debug] format detection: 7 lines, recently matched format matched 3 of them (42.9%)\n" +
+		"[debug] format first (priority 0): 3 matches (42.9%)\n" +
+		"[debug] format second (priority 0): 3 matches (42.9%)\n" +
+		"[debug] no format: 1 lines (14.3%)\n"
+	if got := hl.DebugStats(); got != stats {
+		t.Errorf("got %q, want %q", got, stats)
+	}
+}
+
+func TestFormatDetectorPriority(t *testing.T) {
+	tests := []struct {
+		plain   string
+		colored string
+	}{
+		{"plain text", "\x1b[38;2;255;0;0mplain \x1b[0mtext"},
+		// "generic" matches too and it's the recent one,
+		// but "special" has higher priority
+		{"special text", "\x1b[38;2;0;0;255mspecial \x1b[0mtext"},
+		{"special text", "\x1b[38;2;0;0;255mspecial \x1b[0mtext"},
+		{"plain text", "\x1b[38;2;255;0;0mplain \x1b[0mtext"},
+	}
+
+	hl, err := newTestHighlighter(t, "./testdata/detector/detect/02_priority.yaml", config.Options{Debug: true})
+	if err != nil {
+		t.Fatalf("NewHighlighter() failed with this error: %s", err)
+	}
+
+	for _, tt := range tests {
+		colored := debugInfoRegExp.ReplaceAllString(hl.Colorize(tt.plain), "")
+		if colored != tt.colored {
+			t.Errorf("got %q, want %q", colored, tt.colored)
+		}
+	}
+
+	stats := "[debug] format detection: 4 lines, recently matched format matched 1 of them (25.0%)\n" +
+		"[debug] format special (priority 10): 2 matches (50.0%)\n" +
+		"[debug] format generic (priority 0): 2 matches (50.0%)\n" +
+		"[debug] no format: 0 lines (0.0%)\n"
+	if got := hl.DebugStats(); got != stats {
+		t.Errorf("got %q, want %q", got, stats)
+	}
+}
+
+func TestFormatDetectorNoStats(t *testing.T) {
+	hl, err := newTestHighlighter(t, "./testdata/detector/detect/01_main.yaml", config.Options{})
+	if err != nil {
+		t.Fatalf("NewHighlighter() failed with this error: %s", err)
+	}
+
+	hl.Colorize("first 1")
+	if stats := hl.DebugStats(); stats != "" {
+		t.Errorf("got %q, want empty statistics", stats)
+	}
+}
+
+// lines are detected once even if their time is needed for --since
+func TestFormatDetectorTimeRange(t *testing.T) {
+	hl, err := newTestHighlighter(t, "./testdata/detector/detect/01_main.yaml", config.Options{Debug: true, Since: "1h"})
+	if err != nil {
+		t.Fatalf("NewHighlighter() failed with this error: %s", err)
+	}
+
+	tracker := hl.NewRecordTracker()
+	for _, line := range []string{"first 1", "second 2", "third 3"} {
+		hl.ColorizeRecord(line, tracker.Track(line))
+	}
+
+	stats := "[debug] format detection: 3 lines, recently matched format matched 0 of them (0.0%)\n" +
+		"[debug] format first (priority 0): 1 matches (33.3%)\n" +
+		"[debug] format second (priority 0): 1 matches (33.3%)\n" +
+		"[debug] no format: 1 lines (33.3%)\n"
+	if got := hl.DebugStats(); got != stats {
+		t.Errorf("got %q, want %q", got, stats)
+	}
+}
+
+func TestFormatDetectorOnlyFormat(t *testing.T) {
+	tests := []struct {
+		plain   string
+		colored string
+	}{
+		{"second 1", "\x1b[38;2;0;0;255msecond\x1b[0m\x1b[38;2;0;255;0m 1\x1b[0m"},
+		// other formats aren't detected
+		{"first 2", "first \x1b[38;2;255;255;0m2\x1b[0m"},
+	}
+
+	hl, err := newTestHighlighter(t, "./testdata/detector/detect/01_main.yaml", config.Options{Format: "second"})
+	if err != nil {
+		t.Fatalf("NewHighlighter() failed with this error: %s", err)
+	}
+
+	for _, tt := range tests {
+		if colored := hl.Colorize(tt.plain); colored != tt.colored {
+			t.Errorf("got %q, want %q", colored, tt.colored)
+		}
+	}
+}
+
+func TestFormatDetectorUnknownFormat(t *testing.T) {
+	if _, err := newTestHighlighter(t, "./testdata/detector/detect/01_main.yaml", config.Options{Format: "third"}); err == nil {
+		t.Errorf("NewHighlighter() should have failed")
+	}
+}
@@ -2,13 +2,24 @@ package highlighter
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/knadh/koanf/v2"
 )
 
 type format struct {
-	Name      string
+	Name string
+	// Priority sets the order in which formats are tried
+	// (formats with higher priority go first)
+	Priority  int
 	CapGroups *capGroupList
+	// JSON is set instead of CapGroups for JSON formats
+	JSON *jsonFormat
+	// Multiline is set for formats whose records span several lines
+	// (CapGroups describe the first line of a record in this case)
+	Multiline []continuation
+	// Severity tells how to find the level of a line
+	Severity *severity
 }
 
 type formatList []format
@@ -31,6 +42,12 @@ func newFormats(config *koanf.Koanf, theme string) (formatList, error) {
 		}
 	}
 
+	// formats with the same priority keep the order of their names
+	// to make detection deterministic when one line matches several formats
+	sort.SliceStable(formats, func(i, j int) bool {
+		return formats[i].Priority > formats[j].Priority
+	})
+
 	return formats, nil
 }
 
@@ -40,9 +57,42 @@ func collectFormats(config *koanf.Koanf) (formatList, error) {
 	for _, formatName := range config.MapKeys("formats") {
 		var format format
 		format.Name = formatName
+		format.Priority = config.Int("formats." + formatName + ".priority")
+		if config.Exists("formats." + formatName + ".severity") {
+			severity, err := collectSeverity(config, formatName)
+			if err != nil {
+				return nil, err
+			}
+			format.Severity = severity
+		}
+		if config.Exists("formats." + formatName + ".json") {
+			jf, err := collectJSONFormat(config, formatName)
+			if err != nil {
+				return nil, err
+			}
+			format.JSON = jf
+			formats = append(formats, format)
+
+			continue
+		}
+
 		format.CapGroups = &capGroupList{}
-		if err := config.Unmarshal("formats."+formatName, &format.CapGroups.groups); err != nil {
-			return nil, err
+		if config.Exists("formats." + formatName + ".regexps") {
+			if err := config.Unmarshal("formats."+formatName+".regexps", &format.CapGroups.groups); err != nil {
+				return nil, err
+			}
+		} else {
+			if err := config.Unmarshal("formats."+formatName, &format.CapGroups.groups); err != nil {
+				return nil, err
+			}
+		}
+
+		if config.Exists("formats." + formatName + ".multiline") {
+			multiline, err := collectMultiline(config, formatName)
+			if err != nil {
+				return nil, err
+			}
+			format.Multiline = multiline
 		}
 		formats = append(formats, format)
 	}
@@ -51,43 +101,103 @@ func collectFormats(config *koanf.Koanf) (formatList, error) {
 }
 
 func initFormat(lf *format, config *koanf.Koanf, theme string) error {
+	if lf.JSON != nil {
+		if err := lf.JSON.init(config, "themes."+theme+".formats."+lf.Name); err != nil {
+			return fmt.Errorf("[format: %s] %s", lf.Name, err)
+		}
+
+		return initSeverity(lf)
+	}
+
 	// set colors and style from the theme
-	for i, cg := range lf.CapGroups.groups {
-		path := "themes." + theme + ".formats." + lf.Name + "." + cg.Name
-		cgReal := &lf.CapGroups.groups[i]
+	setCapGroupStyles(lf.CapGroups, config, "themes."+theme+".formats."+lf.Name)
+
+	// init capgroups
+	if err := lf.CapGroups.init(true); err != nil {
+		return fmt.Errorf("[format: %s] %s", lf.Name, err)
+	}
 
-		cgReal.Foreground = config.String(path + ".fg")
-		cgReal.Background = config.String(path + ".bg")
-		cgReal.Style = config.String(path + ".style")
-		cgReal.LinkTo = config.String(path + ".link-to")
+	// init continuation rules of multiline records
+	if err := initMultiline(lf, config, theme); err != nil {
+		return fmt.Errorf("[format: %s] %s", lf.Name, err)
+	}
+
+	return initSeverity(lf)
+}
+
+func initSeverity(lf *format) error {
+	if lf.Severity == nil {
+		return nil
+	}
+	if err := lf.Severity.init(lf); err != nil {
+		return fmt.Errorf("[format: %s] %s", lf.Name, err)
+	}
+
+	return nil
+}
+
+// setCapGroupStyles sets colors and styles of capturing groups
+// and their alternatives from the path in a theme
+func setCapGroupStyles(cgl *capGroupList, config *koanf.Koanf, path string) {
+	for i, cg := range cgl.groups {
+		cgPath := path + "." + cg.Name
+		cgReal := &cgl.groups[i]
+
+		cgReal.Foreground = config.String(cgPath + ".fg")
+		cgReal.Background = config.String(cgPath + ".bg")
+		cgReal.Style = config.String(cgPath + ".style")
+		cgReal.LinkTo = config.String(cgPath + ".link-to")
+		cgReal.Gradient.Colors = config.Strings(cgPath + ".gradient")
 
 		if len(cg.Alternatives) > 0 {
 			for j, alt := range cg.Alternatives {
-				altReal := &lf.CapGroups.groups[i].Alternatives[j]
-				altReal.Foreground = config.String(path + "." + alt.Name + ".fg")
-				altReal.Background = config.String(path + "." + alt.Name + ".bg")
-				altReal.Style = config.String(path + "." + alt.Name + ".style")
+				altReal := &cgl.groups[i].Alternatives[j]
+				altReal.Foreground = config.String(cgPath + "." + alt.Name + ".fg")
+				altReal.Background = config.String(cgPath + "." + alt.Name + ".bg")
+				altReal.Style = config.String(cgPath + "." + alt.Name + ".style")
 			}
 		}
 	}
+}
 
-	// init capgroups
-	if err := lf.CapGroups.init(true); err != nil {
-		return fmt.Errorf("[format: %s] %s", lf.Name, err)
+// only returns the list with the only format with the name
+func (fl formatList) only(name string) (formatList, error) {
+	for _, lf := range fl {
+		if lf.Name == name {
+			return formatList{lf}, nil
+		}
 	}
 
-	return nil
+	return nil, fmt.Errorf("format %q is not defined. Use -C/--print-config flag to see the full configuration", name)
 }
 
-func (lf format) highlight(str string, h Highlighter) (coloredStr string) {
-	str = lf.CapGroups.highlight(str, h)
+func (lf format) highlight(str string, h Highlighter) (spans []span) {
+	if h.match != nil {
+		h.match.Level = lf.level(str)
+	}
+	if lf.JSON != nil {
+		spans = lf.JSON.highlight(str, h)
+	} else {
+		spans = lf.CapGroups.highlight(str, h)
+	}
+	spans = claim(spans, "format", lf.Name)
 	if h.settings.Opts.Debug {
-		str = h.addDebugInfo(str, lf)
+		spans = h.addDebugInfo(spans, lf)
 	}
+	h.addMatch(lf)
+
+	return spans
+}
 
-	return str
+// level returns the level of the line of the format
+func (lf format) level(str string) Level {
+	return lf.Severity.level(str, lf)
 }
 
 func (lf format) match(str string) bool {
+	if lf.JSON != nil {
+		return lf.JSON.match(str)
+	}
+
 	return lf.CapGroups.fullRegExp.MatchString(str)
 }
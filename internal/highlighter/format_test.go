@@ -17,10 +17,40 @@ func compareFormats(format1, format2 format) error {
 		return fmt.Errorf("[format1: %s, format2: %s] names aren't equal", format1.Name, format2.Name)
 	}
 
+	if format1.Priority != format2.Priority {
+		return fmt.Errorf("[format1: %s, format2: %s] priorities aren't equal", format1.Name, format2.Name)
+	}
+
+	if (format1.JSON == nil) != (format2.JSON == nil) {
+		return fmt.Errorf("[format1: %s, format2: %s] only one of the formats is a JSON format", format1.Name, format2.Name)
+	}
+
+	if format1.JSON != nil {
+		if err := compareJSONFormats(*format1.JSON, *format2.JSON); err != nil {
+			return fmt.Errorf("[format1: %s, format2: %s] %s", format1.Name, format2.Name, err)
+		}
+
+		return nil
+	}
+
 	if err := compareCapGroupLists(*format1.CapGroups, *format2.CapGroups); err != nil {
 		return fmt.Errorf("[format1: %s, format2: %s] %s", format1.Name, format2.Name, err)
 	}
 
+	if len(format1.Multiline) != len(format2.Multiline) {
+		return fmt.Errorf("[format1: %s, format2: %s] continuation rules have different length", format1.Name, format2.Name)
+	}
+
+	for i := range format1.Multiline {
+		rule1, rule2 := format1.Multiline[i], format2.Multiline[i]
+		if rule1.Name != rule2.Name || rule1.Last != rule2.Last {
+			return fmt.Errorf("[format1: %s, format2: %s] continuation rules aren't equal", format1.Name, format2.Name)
+		}
+		if err := compareCapGroupLists(*rule1.CapGroups, *rule2.CapGroups); err != nil {
+			return fmt.Errorf("[format1: %s, format2: %s, continuation: %s] %s", format1.Name, format2.Name, rule1.Name, err)
+		}
+	}
+
 	return nil
 }
 
@@ -40,28 +70,35 @@ func compareFormatLists(fl1, fl2 formatList) error {
 
 func TestFormatsNewGood(t *testing.T) {
 	correctFormat := format{
-		"test", &capGroupList{
+		"test", 0, &capGroupList{
 			[]capGroup{
-				{"one", `(\d{1,3}(\.\d{1,3}){3} )`, "#f5ce42", "", "", "", nil, nil},
-				{"two", `([^ ]+ )`, "", "#764a9e", "", "", nil, nil},
-				{"three", `(\[.+\] )`, "", "", "bold", "", nil, nil},
-				{"four", `("[^"]+")`, "#9daf99", "#76fb99", "underline", "", nil, nil},
+				{"one", `(\d{1,3}(\.\d{1,3}){3} )`, "#f5ce42", "", "", "", nil, numRange{}, gradient{}, nil, false, nil},
+				{"two", `([^ ]+ )`, "", "#764a9e", "", "", nil, numRange{}, gradient{}, nil, false, nil},
+				{"three", `(\[.+\] )`, "", "", "bold", "", nil, numRange{}, gradient{}, nil, false, nil},
+				{"four", `("[^"]+")`, "#9daf99", "#76fb99", "underline", "", nil, numRange{}, gradient{}, nil, false, nil},
 				{
 					"five",
 					`(\d\d\d)`, "", "", "", "",
 					[]capGroup{
-						{"1", `(1\d\d)`, "#505050", "", "", "", nil, regexp.MustCompile(`(1\d\d)`)},
-						{"2", `(2\d\d)`, "#00ff00", "", "overline", "", nil, regexp.MustCompile(`(2\d\d)`)},
-						{"3", `(3\d\d)`, "#00ffff", "", "crossout", "", nil, regexp.MustCompile(`(3\d\d)`)},
-						{"4", `(4\d\d)`, "#ff0000", "", "reverse", "", nil, regexp.MustCompile(`(4\d\d)`)},
-						{"5", `(5\d\d)`, "#ff00ff", "", "", "", nil, regexp.MustCompile(`(5\d\d)`)},
+						{"1", `(1\d\d)`, "#505050", "", "", "", nil, numRange{}, gradient{}, nil, false, regexp.MustCompile(`(1\d\d)`)},
+						{"2", `(2\d\d)`, "#00ff00", "", "overline", "", nil, numRange{}, gradient{}, nil, false, regexp.MustCompile(`(2\d\d)`)},
+						{"3", `(3\d\d)`, "#00ffff", "", "crossout", "", nil, numRange{}, gradient{}, nil, false, regexp.MustCompile(`(3\d\d)`)},
+						{"4", `(4\d\d)`, "#ff0000", "", "reverse", "", nil, numRange{}, gradient{}, nil, false, regexp.MustCompile(`(4\d\d)`)},
+						{"5", `(5\d\d)`, "#ff00ff", "", "", "", nil, numRange{}, gradient{}, nil, false, regexp.MustCompile(`(5\d\d)`)},
 					},
+					numRange{},
+					gradient{},
+					nil,
+					false,
 					nil,
 				},
 			},
 			regexp.MustCompile(`^(?P<capGroup0>(?:\d{1,3}(\.\d{1,3}){3} ))(?P<capGroup1>(?:[^ ]+ ))(?P<capGroup2>(?:\[.+\] ))(?P<capGroup3>(?:"[^"]+"))(?P<capGroup4>(?:\d\d\d))$`),
 			map[string]int{"one": 0, "two": 1, "three": 2, "four": 3, "five": 4},
 		},
+		nil,
+		nil,
+		nil,
 	}
 
 	cfg := koanf.New(".")
@@ -110,6 +147,34 @@ func TestFormatsNewBadRegExp(t *testing.T) {
 	})
 }
 
+func TestFormatsNewPriority(t *testing.T) {
+	cfg := koanf.New(".")
+	err := cfg.Load(file.Provider("./testdata/formats/newFormats/04_priority.yaml"), yaml.Parser())
+	if err != nil {
+		t.Fatalf("cfg.Load(...) failed with this error: %s", err)
+	}
+
+	t.Run("TestFormatsNewPriority", func(t *testing.T) {
+		formats, err := newFormats(cfg, "test")
+		if err != nil {
+			t.Fatalf("newFormats() failed with this error: %s", err)
+		}
+
+		// higher priority goes first, the same priority is sorted by name
+		correctOrder := []string{"delta", "echo", "alpha", "charlie", "bravo"}
+		correctPriorities := []int{100, 100, 0, 0, -5}
+		if len(formats) != len(correctOrder) {
+			t.Fatalf("got %d formats, want %d", len(formats), len(correctOrder))
+		}
+		for i, lf := range formats {
+			if lf.Name != correctOrder[i] || lf.Priority != correctPriorities[i] {
+				t.Errorf("got %s (priority %d) at index %d, want %s (priority %d)",
+					lf.Name, lf.Priority, i, correctOrder[i], correctPriorities[i])
+			}
+		}
+	})
+}
+
 func TestFormatsHighlight(t *testing.T) {
 	tests := []struct {
 		plain   string
@@ -140,7 +205,7 @@ func TestFormatsHighlight(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run("TestPatternsHighlight"+tt.plain, func(t *testing.T) {
-			colored := formats[0].highlight(tt.plain, hl)
+			colored := hl.renderer.line(formats[0].highlight(tt.plain, hl))
 			if colored != tt.colored {
 				t.Errorf("got %v, want %v", colored, tt.colored)
 			}
@@ -175,65 +240,81 @@ func TestFormatsBuiltins(t *testing.T) {
 			`127.0.0.1 - - [16/Feb/2024:00:01:01 +0000] "GET / HTTP/1.1" 503 162 "-" "Mozilla/5.0 (iPhone; CPU iPhone OS 16_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/16.1 Mobile/15E148 Safari/604.1"`,
 			"\x1b[38;2;238;204;159m127.0.0.1 \x1b[0m\x1b[38;2;130;139;184m- \x1b[0m\x1b[38;2;79;214;190m- \x1b[0m\x1b[38;2;192;153;255m[16/Feb/2024:00:01:01 +0000] \x1b[0m\x1b[38;2;195;232;141m\"GET / HTTP/1.1\" \x1b[0m\x1b[38;2;255;0;255;1m503 \x1b[0m\x1b[38;2;99;109;166m162 \x1b[0m\x1b[38;2;252;167;234m\"-\" \x1b[0m\x1b[38;2;130;170;255m\"Mozilla/5.0 (iPhone; CPU iPhone OS 16_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/16.1 Mobile/15E148 Safari/604.1\"\x1b[0m",
 		},
+		{
+			`127.0.0.1 - - [16/Feb/2024:00:01:01 +0000] "GET / HTTP/1.1" 200 2500000 "-" "curl/8.5.0"`,
+			"\x1b[38;2;238;204;159m127.0.0.1 \x1b[0m\x1b[38;2;130;139;184m- \x1b[0m\x1b[38;2;79;214;190m- \x1b[0m\x1b[38;2;192;153;255m[16/Feb/2024:00:01:01 +0000] \x1b[0m\x1b[38;2;195;232;141m\"GET / HTTP/1.1\" \x1b[0m\x1b[38;2;0;255;0;1m200 \x1b[0m\x1b[38;2;255;199;119m2500000 \x1b[0m\x1b[38;2;252;167;234m\"-\" \x1b[0m\x1b[38;2;130;170;255m\"curl/8.5.0\"\x1b[0m",
+		},
+		{
+			`127.0.0.1 - - [16/Feb/2024:00:01:01 +0000] "GET / HTTP/1.1" 200 25000000 "-" "curl/8.5.0"`,
+			"\x1b[38;2;238;204;159m127.0.0.1 \x1b[0m\x1b[38;2;130;139;184m- \x1b[0m\x1b[38;2;79;214;190m- \x1b[0m\x1b[38;2;192;153;255m[16/Feb/2024:00:01:01 +0000] \x1b[0m\x1b[38;2;195;232;141m\"GET / HTTP/1.1\" \x1b[0m\x1b[38;2;0;255;0;1m200 \x1b[0m\x1b[38;2;255;117;127m25000000 \x1b[0m\x1b[38;2;252;167;234m\"-\" \x1b[0m\x1b[38;2;130;170;255m\"curl/8.5.0\"\x1b[0m",
+		},
 
 		// nginx-ingress-controller
 		{
 			`127.0.0.102 - - [27/Jun/2023:07:13:16 +0000] "GET /language/en-GB/en-GB.xml HTTP/1.1" 100 9 "-" "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/79.0.3945.130 Safari/537.36" 619 0.003 [imgproxy-imgproxy-imgproxy-80] [] 10.64.6.9:8080 9 0.003 403 07d2cd60741517a6d8222f40757b94c4`,
-			"\x1b[38;2;238;204;159m127.0.0.102 \x1b[0m\x1b[38;2;130;139;184m- \x1b[0m\x1b[38;2;79;214;190m- \x1b[0m\x1b[38;2;192;153;255m[27/Jun/2023:07:13:16 +0000] \x1b[0m\x1b[38;2;195;232;141m\"GET /language/en-GB/en-GB.xml HTTP/1.1\" \x1b[0m\x1b[38;2;0;0;255;1m100 \x1b[0m\x1b[38;2;99;109;166m9 \x1b[0m\x1b[38;2;252;167;234m\"-\" \x1b[0m\x1b[38;2;130;170;255m\"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/79.0.3945.130 Safari/537.36\" \x1b[0m\x1b[38;2;65;166;181m619 \x1b[0m\x1b[38;2;195;232;141m0.003 \x1b[0m\x1b[38;2;101;188;255m[imgproxy-imgproxy-imgproxy-80] \x1b[0m\x1b[38;2;99;109;166m[] \x1b[0m\x1b[38;2;238;204;159m10.64.6.9:8080 \x1b[0m\x1b[38;2;192;153;255m9 \x1b[0m\x1b[38;2;100;198;213m0.003 \x1b[0m\x1b[38;2;255;0;0;1m403 \x1b[0m\x1b[38;2;99;109;166m07d2cd60741517a6d8222f40757b94c4\x1b[0m",
+			"\x1b[38;2;238;204;159m127.0.0.102 \x1b[0m\x1b[38;2;130;139;184m- \x1b[0m\x1b[38;2;79;214;190m- \x1b[0m\x1b[38;2;192;153;255m[27/Jun/2023:07:13:16 +0000] \x1b[0m\x1b[38;2;195;232;141m\"GET /language/en-GB/en-GB.xml HTTP/1.1\" \x1b[0m\x1b[38;2;0;0;255;1m100 \x1b[0m\x1b[38;2;99;109;166m9 \x1b[0m\x1b[38;2;252;167;234m\"-\" \x1b[0m\x1b[38;2;130;170;255m\"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/79.0.3945.130 Safari/537.36\" \x1b[0m\x1b[38;2;65;166;181m619 \x1b[0m\x1b[38;2;195;232;141m0.003 \x1b[0m\x1b[38;2;101;188;255m[imgproxy-imgproxy-imgproxy-80] \x1b[0m\x1b[38;2;99;109;166m[] \x1b[0m\x1b[38;2;238;204;159m10.64.6.9:8080 \x1b[0m\x1b[38;2;192;153;255m9 \x1b[0m\x1b[38;2;211;225;136m0.003 \x1b[0m\x1b[38;2;255;0;0;1m403 \x1b[0m\x1b[38;2;99;109;166m07d2cd60741517a6d8222f40757b94c4\x1b[0m",
 		},
 		{
 			`127.0.0.102 - - [27/Jun/2023:07:13:16 +0000] "GET /language/en-GB/en-GB.xml HTTP/1.1" 200 9 "-" "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/79.0.3945.130 Safari/537.36" 619 0.003 [imgproxy-imgproxy-imgproxy-80] [] 10.64.6.9:8080 9 0.003 403 07d2cd60741517a6d8222f40757b94c4`,
-			"\x1b[38;2;238;204;159m127.0.0.102 \x1b[0m\x1b[38;2;130;139;184m- \x1b[0m\x1b[38;2;79;214;190m- \x1b[0m\x1b[38;2;192;153;255m[27/Jun/2023:07:13:16 +0000] \x1b[0m\x1b[38;2;195;232;141m\"GET /language/en-GB/en-GB.xml HTTP/1.1\" \x1b[0m\x1b[38;2;0;255;0;1m200 \x1b[0m\x1b[38;2;99;109;166m9 \x1b[0m\x1b[38;2;252;167;234m\"-\" \x1b[0m\x1b[38;2;130;170;255m\"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/79.0.3945.130 Safari/537.36\" \x1b[0m\x1b[38;2;65;166;181m619 \x1b[0m\x1b[38;2;195;232;141m0.003 \x1b[0m\x1b[38;2;101;188;255m[imgproxy-imgproxy-imgproxy-80] \x1b[0m\x1b[38;2;99;109;166m[] \x1b[0m\x1b[38;2;238;204;159m10.64.6.9:8080 \x1b[0m\x1b[38;2;192;153;255m9 \x1b[0m\x1b[38;2;100;198;213m0.003 \x1b[0m\x1b[38;2;255;0;0;1m403 \x1b[0m\x1b[38;2;99;109;166m07d2cd60741517a6d8222f40757b94c4\x1b[0m",
+			"\x1b[38;2;238;204;159m127.0.0.102 \x1b[0m\x1b[38;2;130;139;184m- \x1b[0m\x1b[38;2;79;214;190m- \x1b[0m\x1b[38;2;192;153;255m[27/Jun/2023:07:13:16 +0000] \x1b[0m\x1b[38;2;195;232;141m\"GET /language/en-GB/en-GB.xml HTTP/1.1\" \x1b[0m\x1b[38;2;0;255;0;1m200 \x1b[0m\x1b[38;2;99;109;166m9 \x1b[0m\x1b[38;2;252;167;234m\"-\" \x1b[0m\x1b[38;2;130;170;255m\"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/79.0.3945.130 Safari/537.36\" \x1b[0m\x1b[38;2;65;166;181m619 \x1b[0m\x1b[38;2;195;232;141m0.003 \x1b[0m\x1b[38;2;101;188;255m[imgproxy-imgproxy-imgproxy-80] \x1b[0m\x1b[38;2;99;109;166m[] \x1b[0m\x1b[38;2;238;204;159m10.64.6.9:8080 \x1b[0m\x1b[38;2;192;153;255m9 \x1b[0m\x1b[38;2;211;225;136m0.003 \x1b[0m\x1b[38;2;255;0;0;1m403 \x1b[0m\x1b[38;2;99;109;166m07d2cd60741517a6d8222f40757b94c4\x1b[0m",
 		},
 		{
 			`127.0.0.102 - - [27/Jun/2023:07:13:16 +0000] "GET /language/en-GB/en-GB.xml HTTP/1.1" 302 9 "-" "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/79.0.3945.130 Safari/537.36" 619 0.003 [imgproxy-imgproxy-imgproxy-80] [] 10.64.6.9:8080 9 0.003 403 07d2cd60741517a6d8222f40757b94c4`,
-			"\x1b[38;2;238;204;159m127.0.0.102 \x1b[0m\x1b[38;2;130;139;184m- \x1b[0m\x1b[38;2;79;214;190m- \x1b[0m\x1b[38;2;192;153;255m[27/Jun/2023:07:13:16 +0000] \x1b[0m\x1b[38;2;195;232;141m\"GET /language/en-GB/en-GB.xml HTTP/1.1\" \x1b[0m\x1b[38;2;0;255;255;1m302 \x1b[0m\x1b[38;2;99;109;166m9 \x1b[0m\x1b[38;2;252;167;234m\"-\" \x1b[0m\x1b[38;2;130;170;255m\"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/79.0.3945.130 Safari/537.36\" \x1b[0m\x1b[38;2;65;166;181m619 \x1b[0m\x1b[38;2;195;232;141m0.003 \x1b[0m\x1b[38;2;101;188;255m[imgproxy-imgproxy-imgproxy-80] \x1b[0m\x1b[38;2;99;109;166m[] \x1b[0m\x1b[38;2;238;204;159m10.64.6.9:8080 \x1b[0m\x1b[38;2;192;153;255m9 \x1b[0m\x1b[38;2;100;198;213m0.003 \x1b[0m\x1b[38;2;255;0;0;1m403 \x1b[0m\x1b[38;2;99;109;166m07d2cd60741517a6d8222f40757b94c4\x1b[0m",
+			"\x1b[38;2;238;204;159m127.0.0.102 \x1b[0m\x1b[38;2;130;139;184m- \x1b[0m\x1b[38;2;79;214;190m- \x1b[0m\x1b[38;2;192;153;255m[27/Jun/2023:07:13:16 +0000] \x1b[0m\x1b[38;2;195;232;141m\"GET /language/en-GB/en-GB.xml HTTP/1.1\" \x1b[0m\x1b[38;2;0;255;255;1m302 \x1b[0m\x1b[38;2;99;109;166m9 \x1b[0m\x1b[38;2;252;167;234m\"-\" \x1b[0m\x1b[38;2;130;170;255m\"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/79.0.3945.130 Safari/537.36\" \x1b[0m\x1b[38;2;65;166;181m619 \x1b[0m\x1b[38;2;195;232;141m0.003 \x1b[0m\x1b[38;2;101;188;255m[imgproxy-imgproxy-imgproxy-80] \x1b[0m\x1b[38;2;99;109;166m[] \x1b[0m\x1b[38;2;238;204;159m10.64.6.9:8080 \x1b[0m\x1b[38;2;192;153;255m9 \x1b[0m\x1b[38;2;211;225;136m0.003 \x1b[0m\x1b[38;2;255;0;0;1m403 \x1b[0m\x1b[38;2;99;109;166m07d2cd60741517a6d8222f40757b94c4\x1b[0m",
 		},
 		{
 			`127.0.0.102 - - [27/Jun/2023:07:13:16 +0000] "GET /language/en-GB/en-GB.xml HTTP/1.1" 404 9 "-" "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/79.0.3945.130 Safari/537.36" 619 0.003 [imgproxy-imgproxy-imgproxy-80] [] 10.64.6.9:8080 9 0.003 403 07d2cd60741517a6d8222f40757b94c4`,
-			"\x1b[38;2;238;204;159m127.0.0.102 \x1b[0m\x1b[38;2;130;139;184m- \x1b[0m\x1b[38;2;79;214;190m- \x1b[0m\x1b[38;2;192;153;255m[27/Jun/2023:07:13:16 +0000] \x1b[0m\x1b[38;2;195;232;141m\"GET /language/en-GB/en-GB.xml HTTP/1.1\" \x1b[0m\x1b[38;2;255;0;0;1m404 \x1b[0m\x1b[38;2;99;109;166m9 \x1b[0m\x1b[38;2;252;167;234m\"-\" \x1b[0m\x1b[38;2;130;170;255m\"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/79.0.3945.130 Safari/537.36\" \x1b[0m\x1b[38;2;65;166;181m619 \x1b[0m\x1b[38;2;195;232;141m0.003 \x1b[0m\x1b[38;2;101;188;255m[imgproxy-imgproxy-imgproxy-80] \x1b[0m\x1b[38;2;99;109;166m[] \x1b[0m\x1b[38;2;238;204;159m10.64.6.9:8080 \x1b[0m\x1b[38;2;192;153;255m9 \x1b[0m\x1b[38;2;100;198;213m0.003 \x1b[0m\x1b[38;2;255;0;0;1m403 \x1b[0m\x1b[38;2;99;109;166m07d2cd60741517a6d8222f40757b94c4\x1b[0m",
+			"\x1b[38;2;238;204;159m127.0.0.102 \x1b[0m\x1b[38;2;130;139;184m- \x1b[0m\x1b[38;2;79;214;190m- \x1b[0m\x1b[38;2;192;153;255m[27/Jun/2023:07:13:16 +0000] \x1b[0m\x1b[38;2;195;232;141m\"GET /language/en-GB/en-GB.xml HTTP/1.1\" \x1b[0m\x1b[38;2;255;0;0;1m404 \x1b[0m\x1b[38;2;99;109;166m9 \x1b[0m\x1b[38;2;252;167;234m\"-\" \x1b[0m\x1b[38;2;130;170;255m\"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/79.0.3945.130 Safari/537.36\" \x1b[0m\x1b[38;2;65;166;181m619 \x1b[0m\x1b[38;2;195;232;141m0.003 \x1b[0m\x1b[38;2;101;188;255m[imgproxy-imgproxy-imgproxy-80] \x1b[0m\x1b[38;2;99;109;166m[] \x1b[0m\x1b[38;2;238;204;159m10.64.6.9:8080 \x1b[0m\x1b[38;2;192;153;255m9 \x1b[0m\x1b[38;2;211;225;136m0.003 \x1b[0m\x1b[38;2;255;0;0;1m403 \x1b[0m\x1b[38;2;99;109;166m07d2cd60741517a6d8222f40757b94c4\x1b[0m",
 		},
 		{
 			`127.0.0.102 - - [27/Jun/2023:07:13:16 +0000] "GET /language/en-GB/en-GB.xml HTTP/1.1" 503 9 "-" "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/79.0.3945.130 Safari/537.36" 619 0.003 [imgproxy-imgproxy-imgproxy-80] [] 10.64.6.9:8080 9 0.003 403 07d2cd60741517a6d8222f40757b94c4`,
-			"\x1b[38;2;238;204;159m127.0.0.102 \x1b[0m\x1b[38;2;130;139;184m- \x1b[0m\x1b[38;2;79;214;190m- \x1b[0m\x1b[38;2;192;153;255m[27/Jun/2023:07:13:16 +0000] \x1b[0m\x1b[38;2;195;232;141m\"GET /language/en-GB/en-GB.xml HTTP/1.1\" \x1b[0m\x1b[38;2;255;0;255;1m503 \x1b[0m\x1b[38;2;99;109;166m9 \x1b[0m\x1b[38;2;252;167;234m\"-\" \x1b[0m\x1b[38;2;130;170;255m\"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/79.0.3945.130 Safari/537.36\" \x1b[0m\x1b[38;2;65;166;181m619 \x1b[0m\x1b[38;2;195;232;141m0.003 \x1b[0m\x1b[38;2;101;188;255m[imgproxy-imgproxy-imgproxy-80] \x1b[0m\x1b[38;2;99;109;166m[] \x1b[0m\x1b[38;2;238;204;159m10.64.6.9:8080 \x1b[0m\x1b[38;2;192;153;255m9 \x1b[0m\x1b[38;2;100;198;213m0.003 \x1b[0m\x1b[38;2;255;0;0;1m403 \x1b[0m\x1b[38;2;99;109;166m07d2cd60741517a6d8222f40757b94c4\x1b[0m",
+			"\x1b[38;2;238;204;159m127.0.0.102 \x1b[0m\x1b[38;2;130;139;184m- \x1b[0m\x1b[38;2;79;214;190m- \x1b[0m\x1b[38;2;192;153;255m[27/Jun/2023:07:13:16 +0000] \x1b[0m\x1b[38;2;195;232;141m\"GET /language/en-GB/en-GB.xml HTTP/1.1\" \x1b[0m\x1b[38;2;255;0;255;1m503 \x1b[0m\x1b[38;2;99;109;166m9 \x1b[0m\x1b[38;2;252;167;234m\"-\" \x1b[0m\x1b[38;2;130;170;255m\"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/79.0.3945.130 Safari/537.36\" \x1b[0m\x1b[38;2;65;166;181m619 \x1b[0m\x1b[38;2;195;232;141m0.003 \x1b[0m\x1b[38;2;101;188;255m[imgproxy-imgproxy-imgproxy-80] \x1b[0m\x1b[38;2;99;109;166m[] \x1b[0m\x1b[38;2;238;204;159m10.64.6.9:8080 \x1b[0m\x1b[38;2;192;153;255m9 \x1b[0m\x1b[38;2;211;225;136m0.003 \x1b[0m\x1b[38;2;255;0;0;1m403 \x1b[0m\x1b[38;2;99;109;166m07d2cd60741517a6d8222f40757b94c4\x1b[0m",
 		},
 		{
 			`127.0.0.102 - - [27/Jun/2023:07:13:16 +0000] "GET /language/en-GB/en-GB.xml HTTP/1.1" 403 9 "-" "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/79.0.3945.130 Safari/537.36" 619 0.003 [imgproxy-imgproxy-imgproxy-80] [] 10.64.6.9:8080 9 0.003 100 07d2cd60741517a6d8222f40757b94c4`,
-			"\x1b[38;2;238;204;159m127.0.0.102 \x1b[0m\x1b[38;2;130;139;184m- \x1b[0m\x1b[38;2;79;214;190m- \x1b[0m\x1b[38;2;192;153;255m[27/Jun/2023:07:13:16 +0000] \x1b[0m\x1b[38;2;195;232;141m\"GET /language/en-GB/en-GB.xml HTTP/1.1\" \x1b[0m\x1b[38;2;255;0;0;1m403 \x1b[0m\x1b[38;2;99;109;166m9 \x1b[0m\x1b[38;2;252;167;234m\"-\" \x1b[0m\x1b[38;2;130;170;255m\"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/79.0.3945.130 Safari/537.36\" \x1b[0m\x1b[38;2;65;166;181m619 \x1b[0m\x1b[38;2;195;232;141m0.003 \x1b[0m\x1b[38;2;101;188;255m[imgproxy-imgproxy-imgproxy-80] \x1b[0m\x1b[38;2;99;109;166m[] \x1b[0m\x1b[38;2;238;204;159m10.64.6.9:8080 \x1b[0m\x1b[38;2;192;153;255m9 \x1b[0m\x1b[38;2;100;198;213m0.003 \x1b[0m\x1b[38;2;0;0;255;1m100 \x1b[0m\x1b[38;2;99;109;166m07d2cd60741517a6d8222f40757b94c4\x1b[0m",
+			"\x1b[38;2;238;204;159m127.0.0.102 \x1b[0m\x1b[38;2;130;139;184m- \x1b[0m\x1b[38;2;79;214;190m- \x1b[0m\x1b[38;2;192;153;255m[27/Jun/2023:07:13:16 +0000] \x1b[0m\x1b[38;2;195;232;141m\"GET /language/en-GB/en-GB.xml HTTP/1.1\" \x1b[0m\x1b[38;2;255;0;0;1m403 \x1b[0m\x1b[38;2;99;109;166m9 \x1b[0m\x1b[38;2;252;167;234m\"-\" \x1b[0m\x1b[38;2;130;170;255m\"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/79.0.3945.130 Safari/537.36\" \x1b[0m\x1b[38;2;65;166;181m619 \x1b[0m\x1b[38;2;195;232;141m0.003 \x1b[0m\x1b[38;2;101;188;255m[imgproxy-imgproxy-imgproxy-80] \x1b[0m\x1b[38;2;99;109;166m[] \x1b[0m\x1b[38;2;238;204;159m10.64.6.9:8080 \x1b[0m\x1b[38;2;192;153;255m9 \x1b[0m\x1b[38;2;211;225;136m0.003 \x1b[0m\x1b[38;2;0;0;255;1m100 \x1b[0m\x1b[38;2;99;109;166m07d2cd60741517a6d8222f40757b94c4\x1b[0m",
 		},
 		{
 			`127.0.0.102 - - [27/Jun/2023:07:13:16 +0000] "GET /language/en-GB/en-GB.xml HTTP/1.1" 403 9 "-" "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/79.0.3945.130 Safari/537.36" 619 0.003 [imgproxy-imgproxy-imgproxy-80] [] 10.64.6.9:8080 9 0.003 200 07d2cd60741517a6d8222f40757b94c4`,
-			"\x1b[38;2;238;204;159m127.0.0.102 \x1b[0m\x1b[38;2;130;139;184m- \x1b[0m\x1b[38;2;79;214;190m- \x1b[0m\x1b[38;2;192;153;255m[27/Jun/2023:07:13:16 +0000] \x1b[0m\x1b[38;2;195;232;141m\"GET /language/en-GB/en-GB.xml HTTP/1.1\" \x1b[0m\x1b[38;2;255;0;0;1m403 \x1b[0m\x1b[38;2;99;109;166m9 \x1b[0m\x1b[38;2;252;167;234m\"-\" \x1b[0m\x1b[38;2;130;170;255m\"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/79.0.3945.130 Safari/537.36\" \x1b[0m\x1b[38;2;65;166;181m619 \x1b[0m\x1b[38;2;195;232;141m0.003 \x1b[0m\x1b[38;2;101;188;255m[imgproxy-imgproxy-imgproxy-80] \x1b[0m\x1b[38;2;99;109;166m[] \x1b[0m\x1b[38;2;238;204;159m10.64.6.9:8080 \x1b[0m\x1b[38;2;192;153;255m9 \x1b[0m\x1b[38;2;100;198;213m0.003 \x1b[0m\x1b[38;2;0;255;0;1m200 \x1b[0m\x1b[38;2;99;109;166m07d2cd60741517a6d8222f40757b94c4\x1b[0m",
+			"\x1b[38;2;238;204;159m127.0.0.102 \x1b[0m\x1b[38;2;130;139;184m- \x1b[0m\x1b[38;2;79;214;190m- \x1b[0m\x1b[38;2;192;153;255m[27/Jun/2023:07:13:16 +0000] \x1b[0m\x1b[38;2;195;232;141m\"GET /language/en-GB/en-GB.xml HTTP/1.1\" \x1b[0m\x1b[38;2;255;0;0;1m403 \x1b[0m\x1b[38;2;99;109;166m9 \x1b[0m\x1b[38;2;252;167;234m\"-\" \x1b[0m\x1b[38;2;130;170;255m\"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/79.0.3945.130 Safari/537.36\" \x1b[0m\x1b[38;2;65;166;181m619 \x1b[0m\x1b[38;2;195;232;141m0.003 \x1b[0m\x1b[38;2;101;188;255m[imgproxy-imgproxy-imgproxy-80] \x1b[0m\x1b[38;2;99;109;166m[] \x1b[0m\x1b[38;2;238;204;159m10.64.6.9:8080 \x1b[0m\x1b[38;2;192;153;255m9 \x1b[0m\x1b[38;2;211;225;136m0.003 \x1b[0m\x1b[38;2;0;255;0;1m200 \x1b[0m\x1b[38;2;99;109;166m07d2cd60741517a6d8222f40757b94c4\x1b[0m",
 		},
 		{
 			`127.0.0.102 - - [27/Jun/2023:07:13:16 +0000] "GET /language/en-GB/en-GB.xml HTTP/1.1" 403 9 "-" "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/79.0.3945.130 Safari/537.36" 619 0.003 [imgproxy-imgproxy-imgproxy-80] [] 10.64.6.9:8080 9 0.003 302 07d2cd60741517a6d8222f40757b94c4`,
-			"\x1b[38;2;238;204;159m127.0.0.102 \x1b[0m\x1b[38;2;130;139;184m- \x1b[0m\x1b[38;2;79;214;190m- \x1b[0m\x1b[38;2;192;153;255m[27/Jun/2023:07:13:16 +0000] \x1b[0m\x1b[38;2;195;232;141m\"GET /language/en-GB/en-GB.xml HTTP/1.1\" \x1b[0m\x1b[38;2;255;0;0;1m403 \x1b[0m\x1b[38;2;99;109;166m9 \x1b[0m\x1b[38;2;252;167;234m\"-\" \x1b[0m\x1b[38;2;130;170;255m\"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/79.0.3945.130 Safari/537.36\" \x1b[0m\x1b[38;2;65;166;181m619 \x1b[0m\x1b[38;2;195;232;141m0.003 \x1b[0m\x1b[38;2;101;188;255m[imgproxy-imgproxy-imgproxy-80] \x1b[0m\x1b[38;2;99;109;166m[] \x1b[0m\x1b[38;2;238;204;159m10.64.6.9:8080 \x1b[0m\x1b[38;2;192;153;255m9 \x1b[0m\x1b[38;2;100;198;213m0.003 \x1b[0m\x1b[38;2;0;255;255;1m302 \x1b[0m\x1b[38;2;99;109;166m07d2cd60741517a6d8222f40757b94c4\x1b[0m",
+			"\x1b[38;2;238;204;159m127.0.0.102 \x1b[0m\x1b[38;2;130;139;184m- \x1b[0m\x1b[38;2;79;214;190m- \x1b[0m\x1b[38;2;192;153;255m[27/Jun/2023:07:13:16 +0000] \x1b[0m\x1b[38;2;195;232;141m\"GET /language/en-GB/en-GB.xml HTTP/1.1\" \x1b[0m\x1b[38;2;255;0;0;1m403 \x1b[0m\x1b[38;2;99;109;166m9 \x1b[0m\x1b[38;2;252;167;234m\"-\" \x1b[0m\x1b[38;2;130;170;255m\"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/79.0.3945.130 Safari/537.36\" \x1b[0m\x1b[38;2;65;166;181m619 \x1b[0m\x1b[38;2;195;232;141m0.003 \x1b[0m\x1b[38;2;101;188;255m[imgproxy-imgproxy-imgproxy-80] \x1b[0m\x1b[38;2;99;109;166m[] \x1b[0m\x1b[38;2;238;204;159m10.64.6.9:8080 \x1b[0m\x1b[38;2;192;153;255m9 \x1b[0m\x1b[38;2;211;225;136m0.003 \x1b[0m\x1b[38;2;0;255;255;1m302 \x1b[0m\x1b[38;2;99;109;166m07d2cd60741517a6d8222f40757b94c4\x1b[0m",
 		},
 		{
 			`127.0.0.102 - - [27/Jun/2023:07:13:16 +0000] "GET /language/en-GB/en-GB.xml HTTP/1.1" 403 9 "-" "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/79.0.3945.130 Safari/537.36" 619 0.003 [imgproxy-imgproxy-imgproxy-80] [] 10.64.6.9:8080 9 0.003 404 07d2cd60741517a6d8222f40757b94c4`,
-			"\x1b[38;2;238;204;159m127.0.0.102 \x1b[0m\x1b[38;2;130;139;184m- \x1b[0m\x1b[38;2;79;214;190m- \x1b[0m\x1b[38;2;192;153;255m[27/Jun/2023:07:13:16 +0000] \x1b[0m\x1b[38;2;195;232;141m\"GET /language/en-GB/en-GB.xml HTTP/1.1\" \x1b[0m\x1b[38;2;255;0;0;1m403 \x1b[0m\x1b[38;2;99;109;166m9 \x1b[0m\x1b[38;2;252;167;234m\"-\" \x1b[0m\x1b[38;2;130;170;255m\"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/79.0.3945.130 Safari/537.36\" \x1b[0m\x1b[38;2;65;166;181m619 \x1b[0m\x1b[38;2;195;232;141m0.003 \x1b[0m\x1b[38;2;101;188;255m[imgproxy-imgproxy-imgproxy-80] \x1b[0m\x1b[38;2;99;109;166m[] \x1b[0m\x1b[38;2;238;204;159m10.64.6.9:8080 \x1b[0m\x1b[38;2;192;153;255m9 \x1b[0m\x1b[38;2;100;198;213m0.003 \x1b[0m\x1b[38;2;255;0;0;1m404 \x1b[0m\x1b[38;2;99;109;166m07d2cd60741517a6d8222f40757b94c4\x1b[0m",
+			"\x1b[38;2;238;204;159m127.0.0.102 \x1b[0m\x1b[38;2;130;139;184m- \x1b[0m\x1b[38;2;79;214;190m- \x1b[0m\x1b[38;2;192;153;255m[27/Jun/2023:07:13:16 +0000] \x1b[0m\x1b[38;2;195;232;141m\"GET /language/en-GB/en-GB.xml HTTP/1.1\" \x1b[0m\x1b[38;2;255;0;0;1m403 \x1b[0m\x1b[38;2;99;109;166m9 \x1b[0m\x1b[38;2;252;167;234m\"-\" \x1b[0m\x1b[38;2;130;170;255m\"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/79.0.3945.130 Safari/537.36\" \x1b[0m\x1b[38;2;65;166;181m619 \x1b[0m\x1b[38;2;195;232;141m0.003 \x1b[0m\x1b[38;2;101;188;255m[imgproxy-imgproxy-imgproxy-80] \x1b[0m\x1b[38;2;99;109;166m[] \x1b[0m\x1b[38;2;238;204;159m10.64.6.9:8080 \x1b[0m\x1b[38;2;192;153;255m9 \x1b[0m\x1b[38;2;211;225;136m0.003 \x1b[0m\x1b[38;2;255;0;0;1m404 \x1b[0m\x1b[38;2;99;109;166m07d2cd60741517a6d8222f40757b94c4\x1b[0m",
 		},
 		{
 			`127.0.0.102 - - [27/Jun/2023:07:13:16 +0000] "GET /language/en-GB/en-GB.xml HTTP/1.1" 403 9 "-" "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/79.0.3945.130 Safari/537.36" 619 0.003 [imgproxy-imgproxy-imgproxy-80] [] 10.64.6.9:8080 9 0.003 503 07d2cd60741517a6d8222f40757b94c4`,
-			"\x1b[38;2;238;204;159m127.0.0.102 \x1b[0m\x1b[38;2;130;139;184m- \x1b[0m\x1b[38;2;79;214;190m- \x1b[0m\x1b[38;2;192;153;255m[27/Jun/2023:07:13:16 +0000] \x1b[0m\x1b[38;2;195;232;141m\"GET /language/en-GB/en-GB.xml HTTP/1.1\" \x1b[0m\x1b[38;2;255;0;0;1m403 \x1b[0m\x1b[38;2;99;109;166m9 \x1b[0m\x1b[38;2;252;167;234m\"-\" \x1b[0m\x1b[38;2;130;170;255m\"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/79.0.3945.130 Safari/537.36\" \x1b[0m\x1b[38;2;65;166;181m619 \x1b[0m\x1b[38;2;195;232;141m0.003 \x1b[0m\x1b[38;2;101;188;255m[imgproxy-imgproxy-imgproxy-80] \x1b[0m\x1b[38;2;99;109;166m[] \x1b[0m\x1b[38;2;238;204;159m10.64.6.9:8080 \x1b[0m\x1b[38;2;192;153;255m9 \x1b[0m\x1b[38;2;100;198;213m0.003 \x1b[0m\x1b[38;2;255;0;255;1m503 \x1b[0m\x1b[38;2;99;109;166m07d2cd60741517a6d8222f40757b94c4\x1b[0m",
+			"\x1b[38;2;238;204;159m127.0.0.102 \x1b[0m\x1b[38;2;130;139;184m- \x1b[0m\x1b[38;2;79;214;190m- \x1b[0m\x1b[38;2;192;153;255m[27/Jun/2023:07:13:16 +0000] \x1b[0m\x1b[38;2;195;232;141m\"GET /language/en-GB/en-GB.xml HTTP/1.1\" \x1b[0m\x1b[38;2;255;0;0;1m403 \x1b[0m\x1b[38;2;99;109;166m9 \x1b[0m\x1b[38;2;252;167;234m\"-\" \x1b[0m\x1b[38;2;130;170;255m\"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/79.0.3945.130 Safari/537.36\" \x1b[0m\x1b[38;2;65;166;181m619 \x1b[0m\x1b[38;2;195;232;141m0.003 \x1b[0m\x1b[38;2;101;188;255m[imgproxy-imgproxy-imgproxy-80] \x1b[0m\x1b[38;2;99;109;166m[] \x1b[0m\x1b[38;2;238;204;159m10.64.6.9:8080 \x1b[0m\x1b[38;2;192;153;255m9 \x1b[0m\x1b[38;2;211;225;136m0.003 \x1b[0m\x1b[38;2;255;0;255;1m503 \x1b[0m\x1b[38;2;99;109;166m07d2cd60741517a6d8222f40757b94c4\x1b[0m",
+		},
+		{
+			`127.0.0.102 - - [27/Jun/2023:07:13:16 +0000] "GET /language/en-GB/en-GB.xml HTTP/1.1" 200 9 "-" "curl/8.5.0" 619 0.300 [imgproxy-imgproxy-imgproxy-80] [] 10.64.6.9:8080 9 0.300 200 07d2cd60741517a6d8222f40757b94c4`,
+			"\x1b[38;2;238;204;159m127.0.0.102 \x1b[0m\x1b[38;2;130;139;184m- \x1b[0m\x1b[38;2;79;214;190m- \x1b[0m\x1b[38;2;192;153;255m[27/Jun/2023:07:13:16 +0000] \x1b[0m\x1b[38;2;195;232;141m\"GET /language/en-GB/en-GB.xml HTTP/1.1\" \x1b[0m\x1b[38;2;0;255;0;1m200 \x1b[0m\x1b[38;2;99;109;166m9 \x1b[0m\x1b[38;2;252;167;234m\"-\" \x1b[0m\x1b[38;2;130;170;255m\"curl/8.5.0\" \x1b[0m\x1b[38;2;65;166;181m619 \x1b[0m\x1b[38;2;255;199;119m0.300 \x1b[0m\x1b[38;2;101;188;255m[imgproxy-imgproxy-imgproxy-80] \x1b[0m\x1b[38;2;99;109;166m[] \x1b[0m\x1b[38;2;238;204;159m10.64.6.9:8080 \x1b[0m\x1b[38;2;192;153;255m9 \x1b[0m\x1b[38;2;255;181;121m0.300 \x1b[0m\x1b[38;2;0;255;0;1m200 \x1b[0m\x1b[38;2;99;109;166m07d2cd60741517a6d8222f40757b94c4\x1b[0m",
+		},
+		{
+			`127.0.0.102 - - [27/Jun/2023:07:13:16 +0000] "GET /language/en-GB/en-GB.xml HTTP/1.1" 200 9 "-" "curl/8.5.0" 619 1.500 [imgproxy-imgproxy-imgproxy-80] [] 10.64.6.9:8080 9 1.500 200 07d2cd60741517a6d8222f40757b94c4`,
+			"\x1b[38;2;238;204;159m127.0.0.102 \x1b[0m\x1b[38;2;130;139;184m- \x1b[0m\x1b[38;2;79;214;190m- \x1b[0m\x1b[38;2;192;153;255m[27/Jun/2023:07:13:16 +0000] \x1b[0m\x1b[38;2;195;232;141m\"GET /language/en-GB/en-GB.xml HTTP/1.1\" \x1b[0m\x1b[38;2;0;255;0;1m200 \x1b[0m\x1b[38;2;99;109;166m9 \x1b[0m\x1b[38;2;252;167;234m\"-\" \x1b[0m\x1b[38;2;130;170;255m\"curl/8.5.0\" \x1b[0m\x1b[38;2;65;166;181m619 \x1b[0m\x1b[38;2;255;117;127m1.500 \x1b[0m\x1b[38;2;101;188;255m[imgproxy-imgproxy-imgproxy-80] \x1b[0m\x1b[38;2;99;109;166m[] \x1b[0m\x1b[38;2;238;204;159m10.64.6.9:8080 \x1b[0m\x1b[38;2;192;153;255m9 \x1b[0m\x1b[38;2;255;153;124m1.500 \x1b[0m\x1b[38;2;0;255;0;1m200 \x1b[0m\x1b[38;2;99;109;166m07d2cd60741517a6d8222f40757b94c4\x1b[0m",
 		},
 
 		// klog
 		{
 			`I0410 23:18:43.650599       1 controller.go:175] "starting healthz server" logger="cert-manager.controller" address="[::]:9403"`,
-			"\x1b[38;2;130;170;255;1mI0410 \x1b[0m\x1b[38;2;252;167;234m23:18:43.650599\x1b[0m\x1b[38;2;99;109;166m       1 \x1b[0m\x1b[38;2;137;221;255mcontroller.go\x1b[0m\x1b[38;2;99;109;166m:175\x1b[0m\x1b[38;2;255;150;108m] \x1b[0m\"starting healthz server\" logger=\"cert-manager.controller\" address=\"[::]:9403\"",
+			"\x1b[38;2;130;170;255;1mI\x1b[0m\x1b[38;2;130;170;255;1m0410 \x1b[0m\x1b[38;2;252;167;234m23:18:43.650599\x1b[0m\x1b[38;2;99;109;166m       1 \x1b[0m\x1b[38;2;137;221;255mcontroller.go\x1b[0m\x1b[38;2;99;109;166m:175\x1b[0m\x1b[38;2;255;150;108m] \x1b[0m\"starting healthz server\" logger=\"cert-manager.controller\" address=\"[::]:9403\"",
 		},
 		{
 			`W0704 20:01:06.932182       1 warnings.go:70] annotation "kubernetes.io/ingress.class" is deprecated, please use 'spec.ingressClassName' instead`,
-			"\x1b[38;2;255;199;119;1mW0704 \x1b[0m\x1b[38;2;252;167;234m20:01:06.932182\x1b[0m\x1b[38;2;99;109;166m       1 \x1b[0m\x1b[38;2;137;221;255mwarnings.go\x1b[0m\x1b[38;2;99;109;166m:70\x1b[0m\x1b[38;2;255;150;108m] \x1b[0mannotation \"kubernetes.io/ingress.class\" is deprecated, please use 'spec.ingressClassName' instead",
+			"\x1b[38;2;255;199;119;1mW\x1b[0m\x1b[38;2;255;199;119;1m0704 \x1b[0m\x1b[38;2;252;167;234m20:01:06.932182\x1b[0m\x1b[38;2;99;109;166m       1 \x1b[0m\x1b[38;2;137;221;255mwarnings.go\x1b[0m\x1b[38;2;99;109;166m:70\x1b[0m\x1b[38;2;255;150;108m] \x1b[0mannotation \"kubernetes.io/ingress.class\" is deprecated, please use 'spec.ingressClassName' instead",
 		},
 		{
 			`E0714 16:12:36.594249       1 controller.go:104] "Unhandled Error" err="ingress 'menetekel/main' in work queue no longer exists" logger="UnhandledError"`,
-			"\x1b[38;2;255;117;127;1mE0714 \x1b[0m\x1b[38;2;252;167;234m16:12:36.594249\x1b[0m\x1b[38;2;99;109;166m       1 \x1b[0m\x1b[38;2;137;221;255mcontroller.go\x1b[0m\x1b[38;2;99;109;166m:104\x1b[0m\x1b[38;2;255;150;108m] \x1b[0m\"Unhandled Error\" err=\"ingress 'menetekel/main' in work queue no longer exists\" logger=\"UnhandledError\"",
+			"\x1b[38;2;255;117;127;1mE\x1b[0m\x1b[38;2;255;117;127;1m0714 \x1b[0m\x1b[38;2;252;167;234m16:12:36.594249\x1b[0m\x1b[38;2;99;109;166m       1 \x1b[0m\x1b[38;2;137;221;255mcontroller.go\x1b[0m\x1b[38;2;99;109;166m:104\x1b[0m\x1b[38;2;255;150;108m] \x1b[0m\"Unhandled Error\" err=\"ingress 'menetekel/main' in work queue no longer exists\" logger=\"UnhandledError\"",
 		},
 		{
 			`F0123 00:12:34.567890       1 controller.go:4] "Fatal Error" err="fatal error"`,
-			"\x1b[38;2;197;59;83;1mF0123 \x1b[0m\x1b[38;2;252;167;234m00:12:34.567890\x1b[0m\x1b[38;2;99;109;166m       1 \x1b[0m\x1b[38;2;137;221;255mcontroller.go\x1b[0m\x1b[38;2;99;109;166m:4\x1b[0m\x1b[38;2;255;150;108m] \x1b[0m\"Fatal Error\" err=\"fatal error\"",
+			"\x1b[38;2;197;59;83;1mF\x1b[0m\x1b[38;2;197;59;83;1m0123 \x1b[0m\x1b[38;2;252;167;234m00:12:34.567890\x1b[0m\x1b[38;2;99;109;166m       1 \x1b[0m\x1b[38;2;137;221;255mcontroller.go\x1b[0m\x1b[38;2;99;109;166m:4\x1b[0m\x1b[38;2;255;150;108m] \x1b[0m\"Fatal Error\" err=\"fatal error\"",
 		},
 
 		// redis
@@ -287,6 +368,28 @@ func TestFormatsBuiltins(t *testing.T) {
 			`<123>Jul 13 10:20:04 menetekel systemd[1]: Finished Certbot.`,
 			"\x1b[38;2;65;166;181m<123>\x1b[0m\x1b[38;2;192;153;255mJul 13 \x1b[0m\x1b[38;2;252;167;234m10:20:04 \x1b[0m\x1b[38;2;137;221;255mmenetekel \x1b[0m\x1b[38;2;130;170;255msystemd\x1b[0m\x1b[38;2;238;204;159m[1]\x1b[0m\x1b[38;2;99;109;166m: \x1b[0mFinished Certbot.",
 		},
+
+		// json
+		{
+			`{"level":"info","ts":1718000000.123,"caller":"main.go:42","msg":"request served","status":200,"cached":false}`,
+			"\x1b[38;2;99;109;166m{\x1b[0m\x1b[38;2;154;173;236m\"level\"\x1b[0m\x1b[38;2;99;109;166m:\x1b[0m\x1b[38;2;130;170;255;1m\"info\"\x1b[0m\x1b[38;2;99;109;166m,\x1b[0m\x1b[38;2;154;173;236m\"ts\"\x1b[0m\x1b[38;2;99;109;166m:\x1b[0m\x1b[38;2;252;167;234m1718000000.123\x1b[0m\x1b[38;2;99;109;166m,\x1b[0m\x1b[38;2;154;173;236m\"caller\"\x1b[0m\x1b[38;2;99;109;166m:\x1b[0m\x1b[38;2;195;232;141m\"main.go:42\"\x1b[0m\x1b[38;2;99;109;166m,\x1b[0m\x1b[38;2;154;173;236m\"msg\"\x1b[0m\x1b[38;2;99;109;166m:\x1b[0m\"request served\"\x1b[38;2;99;109;166m,\x1b[0m\x1b[38;2;154;173;236m\"status\"\x1b[0m\x1b[38;2;99;109;166m:\x1b[0m\x1b[38;2;255;150;108m200\x1b[0m\x1b[38;2;99;109;166m,\x1b[0m\x1b[38;2;154;173;236m\"cached\"\x1b[0m\x1b[38;2;99;109;166m:\x1b[0m\x1b[38;2;192;153;255mfalse\x1b[0m\x1b[38;2;99;109;166m}\x1b[0m",
+		},
+		{
+			`{"time":"2024-06-10T12:00:00Z","level":"WARN","msg":"slow query","duration":"1.5s","user":null}`,
+			"\x1b[38;2;99;109;166m{\x1b[0m\x1b[38;2;154;173;236m\"time\"\x1b[0m\x1b[38;2;99;109;166m:\x1b[0m\x1b[38;2;252;167;234m\"2024-06-10T12:00:00Z\"\x1b[0m\x1b[38;2;99;109;166m,\x1b[0m\x1b[38;2;154;173;236m\"level\"\x1b[0m\x1b[38;2;99;109;166m:\x1b[0m\x1b[38;2;255;199;119;1m\"WARN\"\x1b[0m\x1b[38;2;99;109;166m,\x1b[0m\x1b[38;2;154;173;236m\"msg\"\x1b[0m\x1b[38;2;99;109;166m:\x1b[0m\"slow query\"\x1b[38;2;99;109;166m,\x1b[0m\x1b[38;2;154;173;236m\"duration\"\x1b[0m\x1b[38;2;99;109;166m:\x1b[0m\x1b[38;2;195;232;141m\"1.5s\"\x1b[0m\x1b[38;2;99;109;166m,\x1b[0m\x1b[38;2;154;173;236m\"user\"\x1b[0m\x1b[38;2;99;109;166m:\x1b[0m\x1b[38;2;130;139;184mnull\x1b[0m\x1b[38;2;99;109;166m}\x1b[0m",
+		},
+		{
+			`{"name":"api","hostname":"web-1","pid":12,"level":50,"msg":"db down","time":"2024-06-10T12:00:00.000Z","v":0}`,
+			"\x1b[38;2;99;109;166m{\x1b[0m\x1b[38;2;154;173;236m\"name\"\x1b[0m\x1b[38;2;99;109;166m:\x1b[0m\x1b[38;2;195;232;141m\"api\"\x1b[0m\x1b[38;2;99;109;166m,\x1b[0m\x1b[38;2;154;173;236m\"hostname\"\x1b[0m\x1b[38;2;99;109;166m:\x1b[0m\x1b[38;2;195;232;141m\"web-1\"\x1b[0m\x1b[38;2;99;109;166m,\x1b[0m\x1b[38;2;154;173;236m\"pid\"\x1b[0m\x1b[38;2;99;109;166m:\x1b[0m\x1b[38;2;255;150;108m12\x1b[0m\x1b[38;2;99;109;166m,\x1b[0m\x1b[38;2;154;173;236m\"level\"\x1b[0m\x1b[38;2;99;109;166m:\x1b[0m\x1b[38;2;255;117;127;1m50\x1b[0m\x1b[38;2;99;109;166m,\x1b[0m\x1b[38;2;154;173;236m\"msg\"\x1b[0m\x1b[38;2;99;109;166m:\x1b[0m\"db down\"\x1b[38;2;99;109;166m,\x1b[0m\x1b[38;2;154;173;236m\"time\"\x1b[0m\x1b[38;2;99;109;166m:\x1b[0m\x1b[38;2;252;167;234m\"2024-06-10T12:00:00.000Z\"\x1b[0m\x1b[38;2;99;109;166m,\x1b[0m\x1b[38;2;154;173;236m\"v\"\x1b[0m\x1b[38;2;99;109;166m:\x1b[0m\x1b[38;2;255;150;108m0\x1b[0m\x1b[38;2;99;109;166m}\x1b[0m",
+		},
+		{
+			`{"level":"debug","msg":"tags","tags":["a","b"],"ctx":{"id":7}}`,
+			"\x1b[38;2;99;109;166m{\x1b[0m\x1b[38;2;154;173;236m\"level\"\x1b[0m\x1b[38;2;99;109;166m:\x1b[0m\x1b[38;2;99;109;166;1m\"debug\"\x1b[0m\x1b[38;2;99;109;166m,\x1b[0m\x1b[38;2;154;173;236m\"msg\"\x1b[0m\x1b[38;2;99;109;166m:\x1b[0m\"tags\"\x1b[38;2;99;109;166m,\x1b[0m\x1b[38;2;154;173;236m\"tags\"\x1b[0m\x1b[38;2;99;109;166m:\x1b[0m\x1b[38;2;99;109;166m[\x1b[0m\x1b[38;2;195;232;141m\"a\"\x1b[0m\x1b[38;2;99;109;166m,\x1b[0m\x1b[38;2;195;232;141m\"b\"\x1b[0m\x1b[38;2;99;109;166m]\x1b[0m\x1b[38;2;99;109;166m,\x1b[0m\x1b[38;2;154;173;236m\"ctx\"\x1b[0m\x1b[38;2;99;109;166m:\x1b[0m\x1b[38;2;99;109;166m{\x1b[0m\x1b[38;2;154;173;236m\"id\"\x1b[0m\x1b[38;2;99;109;166m:\x1b[0m\x1b[38;2;255;150;108m7\x1b[0m\x1b[38;2;99;109;166m}\x1b[0m\x1b[38;2;99;109;166m}\x1b[0m",
+		},
+		{
+			`{"level":"fatal","msg":"bye"}`,
+			"\x1b[38;2;99;109;166m{\x1b[0m\x1b[38;2;154;173;236m\"level\"\x1b[0m\x1b[38;2;99;109;166m:\x1b[0m\x1b[38;2;197;59;83;1m\"fatal\"\x1b[0m\x1b[38;2;99;109;166m,\x1b[0m\x1b[38;2;154;173;236m\"msg\"\x1b[0m\x1b[38;2;99;109;166m:\x1b[0m\"bye\"\x1b[38;2;99;109;166m}\x1b[0m",
+		},
 	}
 
 	cfg := koanf.New(".")
@@ -315,7 +418,7 @@ func TestFormatsBuiltins(t *testing.T) {
 		t.Run("TestFormatsHighlight"+tt.plain, func(t *testing.T) {
 			for _, lf := range formats {
 				if lf.match(tt.plain) {
-					colored := lf.highlight(tt.plain, hl)
+					colored := hl.renderer.line(lf.highlight(tt.plain, hl))
 					if colored != tt.colored {
 						t.Errorf("got %v, want %v", colored, tt.colored)
 					}
@@ -0,0 +1,114 @@
+package highlighter
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/lucasb-eyer/go-colorful"
+	"github.com/muesli/termenv"
+)
+
+// gradient colors numbers with "gradient" style. The first number
+// in the string is placed between From and To (values outside
+// of the range are clamped) and gets the color from the same place
+// of the line going through Colors.
+//
+// Scale is "linear" (the default) or "log". The latter is useful
+// for values like latencies that span several orders of magnitude.
+// From and To can have units just like numeric alternatives.
+type gradient struct {
+	From  string `koanf:"from"`
+	To    string `koanf:"to"`
+	Scale string `koanf:"scale"`
+
+	// Colors are set from the theme
+	Colors []string `koanf:"-"`
+
+	from, to quantity
+}
+
+// isSet reports whether the gradient has a range
+func (g gradient) isSet() bool {
+	return g.From != "" || g.To != "" || g.Scale != ""
+}
+
+// init validates and parses the range of the gradient
+func (g *gradient) init() error {
+	if g.From == "" || g.To == "" {
+		return fmt.Errorf("must have both \"from\" and \"to\" fields")
+	}
+
+	var err error
+	if g.from, err = parseQuantity(g.From); err != nil {
+		return err
+	}
+	if g.to, err = parseQuantity(g.To); err != nil {
+		return err
+	}
+	if g.from.dimension != g.to.dimension {
+		return fmt.Errorf("bounds %q and %q have units of different kinds", g.From, g.To)
+	}
+	if g.from.value >= g.to.value {
+		return fmt.Errorf("\"from\" %q must be less than \"to\" %q", g.From, g.To)
+	}
+
+	switch g.Scale {
+	case "", "linear":
+	case "log":
+		if g.from.value <= 0 {
+			return fmt.Errorf("\"from\" %q must be positive for log scale", g.From)
+		}
+	default:
+		return fmt.Errorf("scale %q must be \"linear\" or \"log\"", g.Scale)
+	}
+
+	return nil
+}
+
+// validateColors checks the colors of the gradient from the theme
+func (g gradient) validateColors() error {
+	if len(g.Colors) < 2 {
+		return fmt.Errorf("theme must have at least two colors of the gradient")
+	}
+	// only hex colors can be mixed
+	for _, color := range g.Colors {
+		if !hexColorRegExp.MatchString(color) {
+			return fmt.Errorf("color %q doesn't match %s pattern", color, hexColorRegExp)
+		}
+	}
+
+	return nil
+}
+
+// color returns the color of the first number in the string.
+// Colors in between are mixed only for profiles that can show
+// them. With 16 colors the nearest color of the theme is used.
+// It returns an empty string if there is no suitable number.
+func (g gradient) color(str string, profile termenv.Profile) string {
+	q, ok := firstQuantity(str, "", g.from.dimension)
+	if !ok || len(g.Colors) < 2 || g.from.value >= g.to.value {
+		return ""
+	}
+
+	var pos float64
+	if g.Scale == "log" {
+		if q.value <= 0 {
+			return g.Colors[0]
+		}
+		pos = math.Log(q.value/g.from.value) / math.Log(g.to.value/g.from.value)
+	} else {
+		pos = (q.value - g.from.value) / (g.to.value - g.from.value)
+	}
+	pos = max(0, min(1, pos)) * float64(len(g.Colors)-1)
+
+	if profile == termenv.ANSI {
+		return g.Colors[int(math.Round(pos))]
+	}
+
+	i := min(int(pos), len(g.Colors)-2)
+	// colors are validated in validateColors
+	from, _ := colorful.Hex(g.Colors[i])
+	to, _ := colorful.Hex(g.Colors[i+1])
+
+	return from.BlendLab(to, pos-float64(i)).Clamped().Hex()
+}
@@ -0,0 +1,200 @@
+package highlighter
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/deponian/logalize/internal/config"
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/v2"
+	"github.com/muesli/termenv"
+)
+
+func TestGradientInit(t *testing.T) {
+	colors := []string{"#00ff00", "#ff0000"}
+
+	tests := []struct {
+		name string
+		g    gradient
+		err  string
+	}{
+		{"good", gradient{From: "0", To: "100", Colors: colors}, "%!s(<nil>)"},
+		{"good-log", gradient{From: "1ms", To: "1m", Scale: "log", Colors: colors}, "%!s(<nil>)"},
+		{"no-to", gradient{From: "0", Colors: colors}, `must have both "from" and "to" fields`},
+		{"bad-from", gradient{From: "x", To: "1", Colors: colors}, `"x" is not a number`},
+		{"bad-to", gradient{From: "1", To: "1y", Colors: colors}, `unknown unit "y"`},
+		{"dimensions", gradient{From: "1s", To: "1MB", Colors: colors}, `bounds "1s" and "1MB" have units of different kinds`},
+		{"order", gradient{From: "1s", To: "1ms", Colors: colors}, `"from" "1s" must be less than "to" "1ms"`},
+		{"log-zero", gradient{From: "0", To: "1", Scale: "log", Colors: colors}, `"from" "0" must be positive for log scale`},
+		{"scale", gradient{From: "0", To: "1", Scale: "exp", Colors: colors}, `scale "exp" must be "linear" or "log"`},
+	}
+
+	for _, tt := range tests {
+		t.Run("TestGradientInit"+tt.name, func(t *testing.T) {
+			if err := fmt.Sprintf("%s", tt.g.init()); err != tt.err {
+				t.Errorf("got %s, want %s", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestGradientValidateColors(t *testing.T) {
+	tests := []struct {
+		colors []string
+		err    string
+	}{
+		{[]string{"#00ff00", "#ff0000"}, "%!s(<nil>)"},
+		{[]string{"#00ff00", "#ffff00", "#ff0000"}, "%!s(<nil>)"},
+		{nil, "theme must have at least two colors of the gradient"},
+		{[]string{"#00ff00"}, "theme must have at least two colors of the gradient"},
+		{[]string{"#00ff00", "9"}, fmt.Sprintf(`color "9" doesn't match %s pattern`, hexColorRegExp)},
+		{[]string{"#00ff00", ""}, fmt.Sprintf(`color "" doesn't match %s pattern`, hexColorRegExp)},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("TestGradientValidateColors%v", tt.colors), func(t *testing.T) {
+			if err := fmt.Sprintf("%s", gradient{Colors: tt.colors}.validateColors()); err != tt.err {
+				t.Errorf("got %s, want %s", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestGradientColor(t *testing.T) {
+	linear := gradient{From: "0", To: "100", Colors: []string{"#000000", "#ffffff"}}
+	log := gradient{From: "10ms", To: "10s", Scale: "log", Colors: []string{"#00ff00", "#ffff00", "#ff0000"}}
+	for _, g := range []*gradient{&linear, &log} {
+		if err := g.init(); err != nil {
+			t.Fatalf("init() failed with this error: %s", err)
+		}
+	}
+
+	tests := []struct {
+		g       gradient
+		str     string
+		profile termenv.Profile
+		color   string
+	}{
+		{linear, "0", termenv.TrueColor, "#000000"},
+		{linear, "-5", termenv.TrueColor, "#000000"},
+		{linear, "100%", termenv.TrueColor, "#ffffff"},
+		{linear, "250", termenv.TrueColor, "#ffffff"},
+		{linear, "50", termenv.TrueColor, "#777777"},
+		{linear, "50", termenv.ANSI256, "#777777"},
+		{linear, "40", termenv.ANSI, "#000000"},
+		{linear, "60", termenv.ANSI, "#ffffff"},
+		{linear, "none", termenv.TrueColor, ""},
+		{log, "10ms", termenv.TrueColor, "#00ff00"},
+		{log, "0.01", termenv.TrueColor, "#00ff00"},
+		{log, "316ms", termenv.TrueColor, "#ffff00"},
+		{log, "10s", termenv.TrueColor, "#ff0000"},
+		{log, "1m", termenv.TrueColor, "#ff0000"},
+		{log, "0s", termenv.TrueColor, "#00ff00"},
+		{log, "5s", termenv.ANSI, "#ff0000"},
+		{log, "100ms", termenv.ANSI, "#ffff00"},
+		{log, "1MB", termenv.TrueColor, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("TestGradientColor%s%s%d", tt.g.Scale, tt.str, tt.profile), func(t *testing.T) {
+			if color := tt.g.color(tt.str, tt.profile); color != tt.color {
+				t.Errorf("got %q, want %q", color, tt.color)
+			}
+		})
+	}
+}
+
+func TestGradientHighlight(t *testing.T) {
+	tests := []struct {
+		plain   string
+		profile termenv.Profile
+		colored string
+	}{
+		{"1s 50", termenv.TrueColor, "\x1b[38;2;255;194;0m1s \x1b[0m\x1b[38;2;119;119;119m50\x1b[0m"},
+		{"- 100", termenv.TrueColor, "\x1b[2m- \x1b[0m\x1b[38;2;255;255;255m100\x1b[0m"},
+		{"10s 0", termenv.ANSI256, "\x1b[38;5;196m10s \x1b[0m\x1b[38;5;16m0\x1b[0m"},
+		{"10ms 0", termenv.ANSI, "\x1b[92m10ms \x1b[0m\x1b[30m0\x1b[0m"},
+		{"10ms 0", termenv.Ascii, "10ms 0"},
+	}
+
+	cfg := koanf.New(".")
+	err := cfg.Load(file.Provider("./testdata/gradient/newFormats/01_good.yaml"), yaml.Parser())
+	if err != nil {
+		t.Fatalf("cfg.Load(...) failed with this error: %s", err)
+	}
+
+	for _, tt := range tests {
+		settings := config.Settings{Config: cfg, Opts: config.Options{Theme: "test"}, ColorProfile: tt.profile}
+		hl, err := NewHighlighter(settings)
+		if err != nil {
+			t.Fatalf("NewHighlighter() failed with this error: %s", err)
+		}
+
+		t.Run(fmt.Sprintf("TestGradientHighlight%s%d", tt.plain, tt.profile), func(t *testing.T) {
+			if colored := hl.Colorize(tt.plain); colored != tt.colored {
+				t.Errorf("got %q, want %q", colored, tt.colored)
+			}
+		})
+	}
+}
+
+func TestGradientHighlightLinkTo(t *testing.T) {
+	tests := []struct {
+		plain   string
+		colored string
+	}{
+		// linked groups use the gradient of the target with their own values
+		{"50 0 100", "\x1b[38;2;119;119;119m50 \x1b[0m\x1b[38;2;0;0;0m0 \x1b[0m\x1b[38;2;255;255;255m100\x1b[0m"},
+		{"0 100 50", "\x1b[38;2;0;0;0m0 \x1b[0m\x1b[38;2;255;255;255m100 \x1b[0m\x1b[38;2;119;119;119m50\x1b[0m"},
+	}
+
+	hl, err := newTestHighlighter(t, "./testdata/gradient/newFormats/09_link_to.yaml", config.Options{})
+	if err != nil {
+		t.Fatalf("NewHighlighter() failed with this error: %s", err)
+	}
+
+	for _, tt := range tests {
+		t.Run("TestGradientHighlightLinkTo"+tt.plain, func(t *testing.T) {
+			if colored := hl.Colorize(tt.plain); colored != tt.colored {
+				t.Errorf("got %q, want %q", colored, tt.colored)
+			}
+		})
+	}
+}
+
+func TestGradientNewFormatsUnused(t *testing.T) {
+	cfg := koanf.New(".")
+	err := cfg.Load(file.Provider("./testdata/gradient/newFormats/08_unused.yaml"), yaml.Parser())
+	if err != nil {
+		t.Fatalf("cfg.Load(...) failed with this error: %s", err)
+	}
+
+	// themes don't have to set colors of the gradient they don't use
+	if _, err := newFormats(cfg, "test"); err != nil {
+		t.Errorf("newFormats() failed with this error: %s", err)
+	}
+}
+
+func TestGradientNewFormatsBad(t *testing.T) {
+	for _, path := range []string{
+		"./testdata/gradient/newFormats/02_no_gradient.yaml",
+		"./testdata/gradient/newFormats/03_alternative.yaml",
+		"./testdata/gradient/newFormats/04_alternative_style.yaml",
+		"./testdata/gradient/newFormats/05_one_color.yaml",
+		"./testdata/gradient/newFormats/06_bad_color.yaml",
+		"./testdata/gradient/newFormats/07_bad_range.yaml",
+	} {
+		cfg := koanf.New(".")
+		err := cfg.Load(file.Provider(path), yaml.Parser())
+		if err != nil {
+			t.Fatalf("cfg.Load(...) failed with this error: %s", err)
+		}
+
+		t.Run("TestGradientNewFormatsBad"+path, func(t *testing.T) {
+			if _, err := newFormats(cfg, "test"); err == nil {
+				t.Errorf("newFormats() should have failed")
+			}
+		})
+	}
+}
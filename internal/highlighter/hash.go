@@ -0,0 +1,49 @@
+package highlighter
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	"github.com/knadh/koanf/v2"
+)
+
+// palette is a list of colors for "hash" style.
+// Every distinct value colored with this style gets one of these colors.
+type palette []string
+
+// newPalette returns the palette of the theme from *koanf.Koanf configuration
+func newPalette(config *koanf.Koanf, theme string) (palette, error) {
+	if config == nil {
+		return palette{}, nil
+	}
+
+	var p palette
+	if err := config.Unmarshal("themes."+theme+".palette", &p); err != nil {
+		return nil, fmt.Errorf("[theme: %s] [palette] %s", theme, err)
+	}
+
+	for _, color := range p {
+		if color == "" || !colorRegExp.MatchString(color) {
+			return nil, fmt.Errorf(
+				"[theme: %s] [palette] color %q doesn't match %s pattern",
+				theme, color, colorRegExp)
+		}
+	}
+
+	return p, nil
+}
+
+// color picks a color for the value by its hash, so the same
+// value always gets the same color. Surrounding whitespace
+// is ignored. It returns an empty string if the palette is empty.
+func (p palette) color(value string) string {
+	if len(p) == 0 {
+		return ""
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(strings.TrimSpace(value)))
+
+	return p[h.Sum32()%uint32(len(p))]
+}
@@ -0,0 +1,164 @@
+package highlighter
+
+import (
+	"testing"
+
+	"github.com/deponian/logalize/internal/config"
+	"github.com/google/go-cmp/cmp"
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/v2"
+	"github.com/muesli/termenv"
+)
+
+func TestHashNewPalette(t *testing.T) {
+	cfg := koanf.New(".")
+	err := cfg.Load(file.Provider("./testdata/hash/newPalette/01_good.yaml"), yaml.Parser())
+	if err != nil {
+		t.Fatalf("cfg.Load(...) failed with this error: %s", err)
+	}
+
+	t.Run("TestHashNewPaletteGood", func(t *testing.T) {
+		p, err := newPalette(cfg, "test")
+		if err != nil {
+			t.Errorf("newPalette() failed with this error: %s", err)
+		}
+		want := palette{"#ff0000", "#00ff00", "#0000ff", "42"}
+		if !cmp.Equal(p, want) {
+			t.Errorf("got: %v, want: %v", p, want)
+		}
+	})
+
+	t.Run("TestHashNewPaletteEmpty", func(t *testing.T) {
+		p, err := newPalette(cfg, "no-palette")
+		if err != nil {
+			t.Errorf("newPalette() failed with this error: %s", err)
+		}
+		if len(p) != 0 {
+			t.Errorf("palette has to be empty, got: %v", p)
+		}
+	})
+
+	for _, path := range []string{
+		"./testdata/hash/newPalette/02_bad_color.yaml",
+		"./testdata/hash/newPalette/03_bad_yaml.yaml",
+	} {
+		cfg := koanf.New(".")
+		err := cfg.Load(file.Provider(path), yaml.Parser())
+		if err != nil {
+			t.Fatalf("cfg.Load(...) failed with this error: %s", err)
+		}
+
+		t.Run("TestHashNewPaletteBad"+path, func(t *testing.T) {
+			settings := config.Settings{Config: cfg, Opts: config.Options{Theme: "test"}}
+			if _, err := NewHighlighter(settings); err == nil {
+				t.Errorf("NewHighlighter() should have failed")
+			}
+		})
+	}
+}
+
+func TestHashColor(t *testing.T) {
+	p := palette{"#ff0000", "#00ff00", "#0000ff", "42"}
+
+	tests := []struct {
+		value string
+		color string
+	}{
+		{"3f2a9c", "#00ff00"},
+		{"3f2a9c ", "#00ff00"},
+		{"user-1", "#ff0000"},
+		{"user-2", "#00ff00"},
+		{"user-3", "#0000ff"},
+		{"user-4", "42"},
+	}
+
+	for _, tt := range tests {
+		t.Run("TestHashColor"+tt.value, func(t *testing.T) {
+			if color := p.color(tt.value); color != tt.color {
+				t.Errorf("got %q, want %q", color, tt.color)
+			}
+		})
+	}
+
+	if color := (palette{}).color("user-1"); color != "" {
+		t.Errorf("got %q, want empty color", color)
+	}
+}
+
+func TestHashColorize(t *testing.T) {
+	cfg := koanf.New(".")
+	err := cfg.Load(file.Provider("./testdata/hash/newPalette/01_good.yaml"), yaml.Parser())
+	if err != nil {
+		t.Fatalf("cfg.Load(...) failed with this error: %s", err)
+	}
+
+	tests := []struct {
+		theme   string
+		plain   string
+		colored string
+	}{
+		{
+			"test",
+			"3f2a9c user-1 and user-2",
+			"\x1b[38;2;0;255;0;48;2;17;17;17m3f2a9c \x1b[0m\x1b[38;2;255;0;0muser-1\x1b[0m and \x1b[38;2;0;255;0muser-2\x1b[0m",
+		},
+		{
+			"test",
+			"user-1 again",
+			"\x1b[38;2;255;0;0muser-1\x1b[0m again",
+		},
+		// fg is used if the theme doesn't have a palette
+		{
+			"no-palette",
+			"user-1 again",
+			"\x1b[38;2;255;255;255muser-1\x1b[0m again",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run("TestHashColorize"+tt.theme+tt.plain, func(t *testing.T) {
+			settings := config.Settings{Config: cfg, Opts: config.Options{Theme: tt.theme}, ColorProfile: termenv.TrueColor}
+			hl, err := NewHighlighter(settings)
+			if err != nil {
+				t.Fatalf("NewHighlighter() failed with this error: %s", err)
+			}
+			if colored := hl.Colorize(tt.plain); colored != tt.colored {
+				t.Errorf("got %q, want %q", colored, tt.colored)
+			}
+		})
+	}
+}
+
+// colors of redacted values are the colors of the original ones
+func TestHashColorizeRedacted(t *testing.T) {
+	tests := []struct {
+		plain   string
+		colored string
+	}{
+		{
+			"user-1 and user-2",
+			"\x1b[38;2;255;0;0m***\x1b[0m and \x1b[38;2;0;255;0m***\x1b[0m",
+		},
+		{
+			`{"user":"user-3"}`,
+			"{\"user\":\x1b[38;2;255;0;0m\"***\"\x1b[0m}",
+		},
+		{
+			`{"user":"user-1"}`,
+			"{\"user\":\x1b[38;2;0;0;255m\"***\"\x1b[0m}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run("TestHashColorizeRedacted"+tt.plain, func(t *testing.T) {
+			hl, err := newTestHighlighter(t, "./testdata/hash/newPalette/04_redact.yaml", config.Options{Redact: "mask"})
+			if err != nil {
+				t.Fatalf("NewHighlighter() failed with this error: %s", err)
+			}
+			if colored := hl.Colorize(tt.plain); colored != tt.colored {
+				t.Errorf("got %q, want %q", colored, tt.colored)
+			}
+		})
+	}
+}
@@ -2,8 +2,10 @@
 package highlighter
 
 import (
+	"cmp"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/deponian/logalize/internal/config"
 	"github.com/muesli/termenv"
@@ -12,7 +14,8 @@ import (
 // Highlighter applies colorization.
 //
 // It detects formats, patterns and word groups defined in
-// configuration, then renders the result using the terminal's color profile.
+// configuration, then renders the result in the output format
+// (e.g. using the terminal's color profile).
 type Highlighter struct {
 	settings config.Settings
 
@@ -23,6 +26,41 @@ type Highlighter struct {
 	formats  formatList
 	patterns patternList
 	words    wordGroups
+
+	detector *formatDetector
+
+	// match collects what was found in the line (see ColorizeMatch)
+	match *Match
+
+	// lines below this level are filtered out
+	minLevel Level
+
+	prefixes prefixList
+
+	// colors for "hash" style
+	palette palette
+
+	// gradient of the capturing group that is being colored
+	gradient *gradient
+
+	// rules of whole-line styling and the rule applied to the current line
+	lines lineRuleList
+	line  *lineRule
+
+	// regexps from --highlight flag
+	searches searchList
+
+	// converter of timestamps from --tz and --time-format flags
+	times *timeConverter
+
+	// lines outside of this range are filtered out
+	timeRange *timeRange
+
+	// redactor of capturing groups from --redact flag
+	redactor *redactor
+
+	// output format from --output flag
+	renderer renderer
 }
 
 // NewHighlighter creates a Highlighter configured from the provided settings.
@@ -31,6 +69,7 @@ type Highlighter struct {
 // formats, patterns, and word groups for the selected theme. If the
 // settings restrict highlighting to only certain categories (formats,
 // patterns, or words), the other categories are initialized empty.
+// If settings.Opts.Format is set, it's the only format that is used.
 // It returns an error if configuration cannot be loaded or parsed.
 func NewHighlighter(settings config.Settings) (Highlighter, error) {
 	h := Highlighter{settings: settings}
@@ -50,6 +89,70 @@ func NewHighlighter(settings config.Settings) (Highlighter, error) {
 		return Highlighter{}, err
 	}
 
+	h.prefixes, err = newPrefixes(settings.Config, settings.Opts.Theme)
+	if err != nil {
+		return Highlighter{}, err
+	}
+
+	h.palette, err = newPalette(settings.Config, settings.Opts.Theme)
+	if err != nil {
+		return Highlighter{}, err
+	}
+
+	if err := validateFilters(settings.Opts, formats, patterns, words); err != nil {
+		return Highlighter{}, err
+	}
+	if settings.Opts.MinLevel != "" {
+		h.minLevel, err = ParseLevel(settings.Opts.MinLevel)
+		if err != nil {
+			return Highlighter{}, err
+		}
+	}
+
+	h.lines, err = newLineRules(settings.Config, settings.Opts.Theme, formats, patterns, words)
+	if err != nil {
+		return Highlighter{}, err
+	}
+
+	h.searches, err = newSearches(settings.Opts.Highlights, settings.Opts.HighlightColors, settings.Config, settings.Opts.Theme)
+	if err != nil {
+		return Highlighter{}, err
+	}
+
+	h.times, err = newTimeConverter(settings.Opts)
+	if err != nil {
+		return Highlighter{}, err
+	}
+	h.timeRange, err = newTimeRange(settings.Opts, time.Now())
+	if err != nil {
+		return Highlighter{}, err
+	}
+	h.redactor, err = newRedactor(settings.Opts.Redact)
+	if err != nil {
+		return Highlighter{}, err
+	}
+
+	page, err := newPageColors(settings.Config, settings.Opts.Theme)
+	if err != nil {
+		return Highlighter{}, err
+	}
+	h.renderer, err = newRenderer(settings.Opts, page, settings.ColorProfile)
+	if err != nil {
+		return Highlighter{}, err
+	}
+	// other outputs use exact colors of the theme
+	if _, ok := h.renderer.(ansiRenderer); !ok {
+		h.settings.ColorProfile = termenv.TrueColor
+	}
+
+	// skip format detection if user set --format flag
+	if settings.Opts.Format != "" {
+		formats, err = formats.only(settings.Opts.Format)
+		if err != nil {
+			return Highlighter{}, err
+		}
+	}
+
 	// keep in the highlighter only things we want to colorize
 	if settings.Opts.HighlightOnlyFormats || settings.Opts.HighlightOnlyPatterns || settings.Opts.HighlightOnlyWords {
 		// init with the empty config all the things we don't need
@@ -76,6 +179,8 @@ func NewHighlighter(settings config.Settings) (Highlighter, error) {
 		h.words = words
 	}
 
+	h.detector = newFormatDetector(h.formats, settings.Opts.Debug)
+
 	// set default color
 	if settings.Config != nil {
 		defaultColor := settings.Config.StringMap("themes." + settings.Opts.Theme + ".default")
@@ -90,132 +195,227 @@ func NewHighlighter(settings config.Settings) (Highlighter, error) {
 // Colorize detects formats, patterns and words in the input string
 // and returns colored result string.
 func (h Highlighter) Colorize(line string) string {
+	return h.ColorizeRecord(line, Record{})
+}
+
+// ColorizeRecord does the same as Colorize but lines of multiline records
+// (see RecordTracker) are colorized by the format of their record.
+func (h Highlighter) ColorizeRecord(line string, record Record) string {
 	// don't alter the input in any way if user set --dry-run flag
 	if h.settings.Opts.DryRun {
-		return line
+		return h.renderer.line(plain(line))
 	}
 
+	return h.renderer.line(h.colorize(line, record))
+}
+
+func (h Highlighter) colorize(line string, record Record) []span {
 	// remove all ANSI escape sequences from the input by default
 	if !h.settings.Opts.NoANSIEscapeSequencesStripping {
 		line = allANSIEscapeSequencesRegExp.ReplaceAllString(line, "")
 	}
 
-	// try one of the formats
-	for _, format := range h.formats {
-		if format.match(line) {
-			return format.highlight(line, h)
+	spans := h.colorizeWithLineRules(line, record)
+
+	// matches of --highlight regexps are painted on top of everything
+	if len(h.searches) > 0 {
+		spans = h.searches.highlight(spans, h)
+	}
+
+	return spans
+}
+
+func (h Highlighter) colorizeWithLineRules(line string, record Record) []span {
+	format := h.detectFormat(line, record)
+	if len(h.lines) == 0 {
+		return h.colorizeLine(line, record, format)
+	}
+
+	// whole-line styles depend on what is found in the line,
+	// so the style is applied to the colorized line
+	if h.match == nil {
+		h.match = &Match{}
+	}
+	spans := h.colorizeLine(line, record, format)
+	h.match.resolveLevel()
+
+	h.line = h.lines.find(line, *h.match)
+	if h.line == nil {
+		return spans
+	}
+
+	return h.applyLineStyle(spans)
+}
+
+// detectFormat returns the index of the format of the line or -1.
+// Lines of multiline records aren't detected, they have the format
+// of their record. The format may be already detected by RecordTracker.
+func (h Highlighter) detectFormat(line string, record Record) int {
+	if record.format != nil {
+		return -1
+	}
+	if record.detected {
+		return record.detectedFormat
+	}
+
+	return h.detector.detect(line, h.formats)
+}
+
+// colorizeLine colorizes the line with the format of its record
+// or with the format detected by detectFormat
+func (h Highlighter) colorizeLine(line string, record Record, format int) []span {
+	if record.format != nil {
+		if h.match != nil {
+			h.match.Level = record.level
 		}
+
+		return record.format.highlightRecordLine(line, record.rule, h)
+	}
+
+	if format >= 0 {
+		return h.formats[format].highlight(line, h)
 	}
 
 	// if format wasn't detected highlight patterns and words
 	// and then apply default color to the rest
-	line = h.patterns.highlight(line, h)
-	line = h.words.highlight(line, h)
-	line = h.applyDefaultColor(line)
+	spans := h.patterns.highlight(plain(line), h)
+	spans = h.words.highlight(spans, h)
+	spans = h.applyDefaultColor(spans)
 
-	return line
+	return spans
+}
+
+// DebugStats returns statistics of the format detection
+// collected so far. It's empty unless debug mode is on.
+func (h Highlighter) DebugStats() string {
+	stats := h.detector.stats(h.formats)
+	if stats == "" {
+		return ""
+	}
+
+	// every line of the statistics is rendered on its own,
+	// so it's a separate record of JSON output
+	var out strings.Builder
+	for line := range strings.Lines(stats) {
+		text := strings.TrimSuffix(line, "\n")
+		out.WriteString(h.renderer.line(plain(text)) + h.LineTerminator(line[len(text):]))
+	}
+
+	return out.String()
 }
 
 // highlight colorizes string and applies a style.
-func (h Highlighter) highlight(str, fg, bg, style string) string {
+func (h Highlighter) highlight(str, fg, bg, style string) []span {
 	if style == "patterns-and-words" {
-		str = h.patterns.highlight(str, h)
-		str = h.words.highlight(str, h)
-		str = h.applyDefaultColor(str)
+		spans := h.patterns.highlight(plain(str), h)
+		spans = h.words.highlight(spans, h)
 
-		return str
+		return h.applyDefaultColor(spans)
 	}
 	if style == "patterns" {
-		str = h.patterns.highlight(str, h)
-		str = h.applyDefaultColor(str)
-
-		return str
+		return h.applyDefaultColor(h.patterns.highlight(plain(str), h))
 	}
 	if style == "words" {
-		str = h.words.highlight(str, h)
-		str = h.applyDefaultColor(str)
-
-		return str
+		return h.applyDefaultColor(h.words.highlight(plain(str), h))
 	}
-
-	coloredStr := termenv.String(str)
-	if fg != "" {
-		coloredStr = coloredStr.Foreground(h.settings.ColorProfile.Color(fg))
+	// every distinct value gets its own color from the palette
+	if style == "hash" {
+		if color := h.palette.color(str); color != "" {
+			fg = color
+		}
+		style = ""
 	}
-	if bg != "" {
-		coloredStr = coloredStr.Background(h.settings.ColorProfile.Color(bg))
+
+	// numbers get colors from the gradient of their capturing group
+	if style == "gradient" {
+		if h.gradient != nil {
+			if color := h.gradient.color(str, h.settings.ColorProfile); color != "" {
+				fg = color
+			}
+		}
+		style = ""
 	}
-	switch style {
-	case "bold":
-		coloredStr = coloredStr.Bold()
-	case "faint":
-		coloredStr = coloredStr.Faint()
-	case "italic":
-		coloredStr = coloredStr.Italic()
-	case "underline":
-		coloredStr = coloredStr.Underline()
-	case "overline":
-		coloredStr = coloredStr.Overline()
-	case "crossout":
-		coloredStr = coloredStr.CrossOut()
-	case "reverse":
-		coloredStr = coloredStr.Reverse()
+
+	// whole-line style fills in everything the span doesn't set
+	if h.line != nil {
+		fg = cmp.Or(fg, h.line.Foreground)
+		bg = cmp.Or(bg, h.line.Background)
+		style = cmp.Or(style, h.line.Style)
 	}
 
-	return coloredStr.String()
+	return []span{{text: str, spanStyle: spanStyle{fg: fg, bg: bg, style: style}}}
 }
 
 // applyDefaultColor applies default color to all non-colored parts of the input.
-func (h Highlighter) applyDefaultColor(str string) string {
-	return walkNonSGR(str, func(part string) string {
-		if part == "" {
-			return part
+// Whole-line style of the current line (see lineRule) overrides the default color.
+func (h Highlighter) applyDefaultColor(spans []span) []span {
+	fg, bg, style := h.defaultFg, h.defaultBg, h.defaultStyle
+	if h.line != nil {
+		fg, bg, style = h.line.Foreground, h.line.Background, h.line.Style
+	}
+
+	return walkUncolored(spans, func(part string) []span {
+		spans := h.highlight(part, fg, bg, style)
+		for i := range spans {
+			spans[i].byDefault = true
 		}
 
-		return h.highlight(part, h.defaultFg, h.defaultBg, h.defaultStyle)
+		return spans
 	})
 }
 
-func (h Highlighter) addDebugInfo(str string, kind any) string {
+// applyLineStyle applies the whole-line style of the current line
+// (see lineRule) to its spans. Text of the default color gets the style
+// of the line, other spans get the colors and the style they don't have.
+// Colors of the input are left untouched.
+func (h Highlighter) applyLineStyle(spans []span) []span {
+	styled := make([]span, len(spans))
+	for i, s := range spans {
+		switch {
+		case s.sgr != "" || !s.colored() && s.kind == "" && s.group == "":
+			// plain text is colored by applyDefaultColor below
+		case s.byDefault:
+			s.fg, s.bg, s.style = h.line.Foreground, h.line.Background, h.line.Style
+		default:
+			s.fg = cmp.Or(s.fg, h.line.Foreground)
+			s.bg = cmp.Or(s.bg, h.line.Background)
+			s.style = cmp.Or(s.style, h.line.Style)
+		}
+		styled[i] = s
+	}
+
+	// formats leave some parts of the line (e.g. whitespace in JSON) uncolored
+	return h.applyDefaultColor(styled)
+}
+
+// addDebugInfo surrounds the spans with the name of what colored them
+func (h Highlighter) addDebugInfo(spans []span, kind any) []span {
 	opening := ""
 	closing := ""
+	name := ""
 
 	switch k := kind.(type) {
 	case format:
 		opening = fmt.Sprintf("[f(%s)]", k.Name)
 		closing = fmt.Sprintf("[f(/%s)]", k.Name)
+		name = k.Name
 	case pattern:
 		opening = fmt.Sprintf("[p(%s)]", k.Name)
 		closing = fmt.Sprintf("[p(/%s)]", k.Name)
+		name = k.Name
 	case wordGroup:
 		opening = fmt.Sprintf("[w(%s)]", k.Name)
 		closing = fmt.Sprintf("[w(/%s)]", k.Name)
+		name = k.Name
 	}
 
-	opening = h.highlight(opening, "", "", "reverse")
-	closing = h.highlight(closing, "", "", "reverse")
-
-	return opening + str + closing
-}
+	// markers are reversed to stand out from the text
+	marker := func(text string) []span {
+		spans := h.highlight(text, "", "", "reverse")
+		spans[0].origin = origin{kind: "debug", name: name}
 
-// walkNonSGR applies f to every non-colored part and keeps SGR segments untouched
-func walkNonSGR(str string, f func(string) string) string {
-	if str == "" {
-		return str
+		return spans
 	}
-	out := strings.Builder{}
-	for {
-		loc := sgrANSIEscapeSequenceRegExp.FindStringIndex(str)
-		if loc == nil {
-			out.WriteString(f(str))
 
-			return out.String()
-		}
-		// color left part
-		out.WriteString(f(str[:loc[0]]))
-		// copy already colored part verbatim
-		out.WriteString(str[loc[0]:loc[1]])
-		// process right part
-		str = str[loc[1]:]
-	}
+	return append(append(marker(opening), spans...), marker(closing)...)
 }
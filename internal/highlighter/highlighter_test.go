@@ -13,6 +13,29 @@ import (
 	"github.com/muesli/termenv"
 )
 
+// newTestHighlighter creates a highlighter from the configuration file
+// with true colors and "test" theme if opts doesn't set another one
+func newTestHighlighter(t *testing.T, path string, opts config.Options) (Highlighter, error) {
+	t.Helper()
+
+	cfg := koanf.New(".")
+	err := cfg.Load(file.Provider(path), yaml.Parser())
+	if err != nil {
+		t.Fatalf("cfg.Load(...) failed with this error: %s", err)
+	}
+
+	if opts.Theme == "" {
+		opts.Theme = "test"
+	}
+	settings := config.Settings{
+		Config:       cfg,
+		Opts:         opts,
+		ColorProfile: termenv.TrueColor,
+	}
+
+	return NewHighlighter(settings)
+}
+
 func compareHighlighters(hl1, hl2 Highlighter) error {
 	if err := compareFormatLists(hl1.formats, hl2.formats); err != nil {
 		return fmt.Errorf("formats are different: %v", err)
@@ -32,28 +55,35 @@ func compareHighlighters(hl1, hl2 Highlighter) error {
 func TestHighlighterNewGood(t *testing.T) {
 	correctFormats := formatList{
 		{
-			"test", &capGroupList{
+			"test", 0, &capGroupList{
 				[]capGroup{
-					{"one", `(\d{1,3}(\.\d{1,3}){3} )`, "#f5ce42", "", "", "", nil, nil},
-					{"two", `([^ ]+ )`, "", "#764a9e", "", "", nil, nil},
-					{"three", `(\[.+\] )`, "", "", "bold", "", nil, nil},
-					{"four", `("[^"]+")`, "#9daf99", "#76fb99", "underline", "", nil, nil},
+					{"one", `(\d{1,3}(\.\d{1,3}){3} )`, "#f5ce42", "", "", "", nil, numRange{}, gradient{}, nil, false, nil},
+					{"two", `([^ ]+ )`, "", "#764a9e", "", "", nil, numRange{}, gradient{}, nil, false, nil},
+					{"three", `(\[.+\] )`, "", "", "bold", "", nil, numRange{}, gradient{}, nil, false, nil},
+					{"four", `("[^"]+")`, "#9daf99", "#76fb99", "underline", "", nil, numRange{}, gradient{}, nil, false, nil},
 					{
 						"five",
 						`(\d\d\d)`, "", "", "", "",
 						[]capGroup{
-							{"alt1", `(1\d\d)`, "#505050", "", "", "", nil, regexp.MustCompile(`(1\d\d)`)},
-							{"alt2", `(2\d\d)`, "#00ff00", "", "overline", "", nil, regexp.MustCompile(`(2\d\d)`)},
-							{"alt3", `(3\d\d)`, "#00ffff", "", "crossout", "", nil, regexp.MustCompile(`(3\d\d)`)},
-							{"alt4", `(4\d\d)`, "#ff0000", "", "reverse", "", nil, regexp.MustCompile(`(4\d\d)`)},
-							{"alt5", `(5\d\d)`, "#ff00ff", "", "", "", nil, regexp.MustCompile(`(5\d\d)`)},
+							{"alt1", `(1\d\d)`, "#505050", "", "", "", nil, numRange{}, gradient{}, nil, false, regexp.MustCompile(`(1\d\d)`)},
+							{"alt2", `(2\d\d)`, "#00ff00", "", "overline", "", nil, numRange{}, gradient{}, nil, false, regexp.MustCompile(`(2\d\d)`)},
+							{"alt3", `(3\d\d)`, "#00ffff", "", "crossout", "", nil, numRange{}, gradient{}, nil, false, regexp.MustCompile(`(3\d\d)`)},
+							{"alt4", `(4\d\d)`, "#ff0000", "", "reverse", "", nil, numRange{}, gradient{}, nil, false, regexp.MustCompile(`(4\d\d)`)},
+							{"alt5", `(5\d\d)`, "#ff00ff", "", "", "", nil, numRange{}, gradient{}, nil, false, regexp.MustCompile(`(5\d\d)`)},
 						},
+						numRange{},
+						gradient{},
+						nil,
+						false,
 						nil,
 					},
 				},
 				regexp.MustCompile(`^(?P<capGroup0>(?:\d{1,3}(\.\d{1,3}){3} ))(?P<capGroup1>(?:[^ ]+ ))(?P<capGroup2>(?:\[.+\] ))(?P<capGroup3>(?:"[^"]+"))(?P<capGroup4>(?:\d\d\d))$`),
 				map[string]int{"one": 0, "two": 1, "three": 2, "four": 3, "five": 4},
 			},
+			nil,
+			nil,
+			nil,
 		},
 	}
 
@@ -61,33 +91,33 @@ func TestHighlighterNewGood(t *testing.T) {
 		{"string", 500, &capGroupList{
 			[]capGroup{
 				{
-					"string", `("[^"]+"|'[^']+')`, "#00ff00", "", "", "", nil, nil,
+					"string", `("[^"]+"|'[^']+')`, "#00ff00", "", "", "", nil, numRange{}, gradient{}, nil, false, nil,
 				},
 			},
 			regexp.MustCompile(`(?P<capGroup0>(?:"[^"]+"|'[^']+'))`),
 			map[string]int{"string": 0},
-		}},
+		}, nil},
 		{"ipv4-address", 0, &capGroupList{
 			[]capGroup{
 				{
-					"one", `(\d\d\d(\.\d\d\d){3})`, "#ffc777", "", "", "", nil, nil,
+					"one", `(\d\d\d(\.\d\d\d){3})`, "#ffc777", "", "", "", nil, numRange{}, gradient{}, nil, false, nil,
 				},
 				{
-					"two", `((:\d{1,5})?)`, "#ff966c", "", "", "", nil, nil,
+					"two", `((:\d{1,5})?)`, "#ff966c", "", "", "", nil, numRange{}, gradient{}, nil, false, nil,
 				},
 			},
 			regexp.MustCompile(`(?P<capGroup0>(?:\d\d\d(\.\d\d\d){3}))(?P<capGroup1>(?:(:\d{1,5})?))`),
 			map[string]int{"one": 0, "two": 1},
-		}},
+		}, nil},
 		{"number", 0, &capGroupList{
 			[]capGroup{
 				{
-					"number", `(\d+)`, "", "#00ffff", "bold", "", nil, nil,
+					"number", `(\d+)`, "", "#00ffff", "bold", "", nil, numRange{}, gradient{}, nil, false, nil,
 				},
 			},
 			regexp.MustCompile(`(?P<capGroup0>(?:\d+))`),
 			map[string]int{"number": 0},
-		}},
+		}, nil},
 	}
 
 	correctWords := wordGroups{
@@ -185,28 +215,35 @@ func TestHighlighterNewBadWords(t *testing.T) {
 func TestHighlighterNewHighlightOnlyFormats(t *testing.T) {
 	correctFormats := formatList{
 		{
-			"test", &capGroupList{
+			"test", 0, &capGroupList{
 				[]capGroup{
-					{"one", `(\d{1,3}(\.\d{1,3}){3} )`, "#f5ce42", "", "", "", nil, nil},
-					{"two", `([^ ]+ )`, "", "#764a9e", "", "", nil, nil},
-					{"three", `(\[.+\] )`, "", "", "bold", "", nil, nil},
-					{"four", `("[^"]+")`, "#9daf99", "#76fb99", "underline", "", nil, nil},
+					{"one", `(\d{1,3}(\.\d{1,3}){3} )`, "#f5ce42", "", "", "", nil, numRange{}, gradient{}, nil, false, nil},
+					{"two", `([^ ]+ )`, "", "#764a9e", "", "", nil, numRange{}, gradient{}, nil, false, nil},
+					{"three", `(\[.+\] )`, "", "", "bold", "", nil, numRange{}, gradient{}, nil, false, nil},
+					{"four", `("[^"]+")`, "#9daf99", "#76fb99", "underline", "", nil, numRange{}, gradient{}, nil, false, nil},
 					{
 						"five",
 						`(\d\d\d)`, "", "", "", "",
 						[]capGroup{
-							{"alt1", `(1\d\d)`, "#505050", "", "", "", nil, regexp.MustCompile(`(1\d\d)`)},
-							{"alt2", `(2\d\d)`, "#00ff00", "", "overline", "", nil, regexp.MustCompile(`(2\d\d)`)},
-							{"alt3", `(3\d\d)`, "#00ffff", "", "crossout", "", nil, regexp.MustCompile(`(3\d\d)`)},
-							{"alt4", `(4\d\d)`, "#ff0000", "", "reverse", "", nil, regexp.MustCompile(`(4\d\d)`)},
-							{"alt5", `(5\d\d)`, "#ff00ff", "", "", "", nil, regexp.MustCompile(`(5\d\d)`)},
+							{"alt1", `(1\d\d)`, "#505050", "", "", "", nil, numRange{}, gradient{}, nil, false, regexp.MustCompile(`(1\d\d)`)},
+							{"alt2", `(2\d\d)`, "#00ff00", "", "overline", "", nil, numRange{}, gradient{}, nil, false, regexp.MustCompile(`(2\d\d)`)},
+							{"alt3", `(3\d\d)`, "#00ffff", "", "crossout", "", nil, numRange{}, gradient{}, nil, false, regexp.MustCompile(`(3\d\d)`)},
+							{"alt4", `(4\d\d)`, "#ff0000", "", "reverse", "", nil, numRange{}, gradient{}, nil, false, regexp.MustCompile(`(4\d\d)`)},
+							{"alt5", `(5\d\d)`, "#ff00ff", "", "", "", nil, numRange{}, gradient{}, nil, false, regexp.MustCompile(`(5\d\d)`)},
 						},
+						numRange{},
+						gradient{},
+						nil,
+						false,
 						nil,
 					},
 				},
 				regexp.MustCompile(`^(?P<capGroup0>(?:\d{1,3}(\.\d{1,3}){3} ))(?P<capGroup1>(?:[^ ]+ ))(?P<capGroup2>(?:\[.+\] ))(?P<capGroup3>(?:"[^"]+"))(?P<capGroup4>(?:\d\d\d))$`),
 				map[string]int{"one": 0, "two": 1, "three": 2, "four": 3, "five": 4},
 			},
+			nil,
+			nil,
+			nil,
 		},
 	}
 
@@ -249,33 +286,33 @@ func TestHighlighterNewHighlightOnlyPatterns(t *testing.T) {
 		{"string", 500, &capGroupList{
 			[]capGroup{
 				{
-					"string", `("[^"]+"|'[^']+')`, "#00ff00", "", "", "", nil, nil,
+					"string", `("[^"]+"|'[^']+')`, "#00ff00", "", "", "", nil, numRange{}, gradient{}, nil, false, nil,
 				},
 			},
 			regexp.MustCompile(`(?P<capGroup0>(?:"[^"]+"|'[^']+'))`),
 			map[string]int{"string": 0},
-		}},
+		}, nil},
 		{"ipv4-address", 0, &capGroupList{
 			[]capGroup{
 				{
-					"one", `(\d\d\d(\.\d\d\d){3})`, "#ffc777", "", "", "", nil, nil,
+					"one", `(\d\d\d(\.\d\d\d){3})`, "#ffc777", "", "", "", nil, numRange{}, gradient{}, nil, false, nil,
 				},
 				{
-					"two", `((:\d{1,5})?)`, "#ff966c", "", "", "", nil, nil,
+					"two", `((:\d{1,5})?)`, "#ff966c", "", "", "", nil, numRange{}, gradient{}, nil, false, nil,
 				},
 			},
 			regexp.MustCompile(`(?P<capGroup0>(?:\d\d\d(\.\d\d\d){3}))(?P<capGroup1>(?:(:\d{1,5})?))`),
 			map[string]int{"one": 0, "two": 1},
-		}},
+		}, nil},
 		{"number", 0, &capGroupList{
 			[]capGroup{
 				{
-					"number", `(\d+)`, "", "#00ffff", "bold", "", nil, nil,
+					"number", `(\d+)`, "", "#00ffff", "bold", "", nil, numRange{}, gradient{}, nil, false, nil,
 				},
 			},
 			regexp.MustCompile(`(?P<capGroup0>(?:\d+))`),
 			map[string]int{"number": 0},
-		}},
+		}, nil},
 	}
 
 	cfg := koanf.New(".")
@@ -0,0 +1,110 @@
+package highlighter
+
+import (
+	"cmp"
+	"html"
+	"strings"
+)
+
+// htmlRenderer renders lines as HTML with the colors of the theme.
+// Every part of a line with its own style becomes a <span>.
+type htmlRenderer struct {
+	page pageColors
+	// fragment is only <pre> element without the rest of the document
+	fragment bool
+}
+
+func (r htmlRenderer) line(spans []span) string {
+	var parts []styledText
+	for _, s := range spans {
+		// the text may have escape sequences of the input
+		parts = append(parts, splitStyles(s.text, spanTextStyle(s))...)
+	}
+
+	var out strings.Builder
+	for _, part := range parts {
+		text := html.EscapeString(part.text)
+		css := r.css(part.style)
+		if css == "" {
+			out.WriteString(text)
+
+			continue
+		}
+		out.WriteString(`<span style="` + css + `">` + text + `</span>`)
+	}
+
+	return out.String()
+}
+
+func (r htmlRenderer) document() (string, string) {
+	var colors []string
+	if r.page.Foreground != "" {
+		colors = append(colors, "color:"+r.page.Foreground)
+	}
+	if r.page.Background != "" {
+		colors = append(colors, "background-color:"+r.page.Background)
+	}
+	css := append([]string{"margin:0", "padding:1em", "font-family:ui-monospace,monospace"}, colors...)
+	pre := `<pre class="logalize" style="` + strings.Join(css, ";") + `">`
+
+	if r.fragment {
+		return pre, "</pre>\n"
+	}
+
+	header := "<!DOCTYPE html>\n" +
+		"<html>\n" +
+		"<head>\n" +
+		"<meta charset=\"utf-8\">\n" +
+		"<meta name=\"generator\" content=\"logalize\">\n" +
+		"<title>logalize</title>\n" +
+		"</head>\n" +
+		"<body style=\"" + strings.Join(append([]string{"margin:0"}, colors...), ";") + "\">\n" +
+		pre
+	footer := "</pre>\n" +
+		"</body>\n" +
+		"</html>\n"
+
+	return header, footer
+}
+
+// css returns inline CSS of the style. Reversed text without
+// its own colors gets the colors of the page (or the browser).
+func (r htmlRenderer) css(s textStyle) string {
+	fg, bg := s.fg, s.bg
+	if s.reverse {
+		fg, bg = cmp.Or(bg, r.page.Background, "Canvas"), cmp.Or(fg, r.page.Foreground, "CanvasText")
+	}
+
+	var css []string
+	if fg != "" {
+		css = append(css, "color:"+fg)
+	}
+	if bg != "" {
+		css = append(css, "background-color:"+bg)
+	}
+	if s.bold {
+		css = append(css, "font-weight:bold")
+	}
+	if s.faint {
+		css = append(css, "opacity:0.6")
+	}
+	if s.italic {
+		css = append(css, "font-style:italic")
+	}
+
+	var lines []string
+	if s.underline {
+		lines = append(lines, "underline")
+	}
+	if s.overline {
+		lines = append(lines, "overline")
+	}
+	if s.crossout {
+		lines = append(lines, "line-through")
+	}
+	if len(lines) > 0 {
+		css = append(css, "text-decoration:"+strings.Join(lines, " "))
+	}
+
+	return strings.Join(css, ";")
+}
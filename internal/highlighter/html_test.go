@@ -0,0 +1,133 @@
+package highlighter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/deponian/logalize/internal/config"
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/v2"
+	"github.com/muesli/termenv"
+)
+
+func TestHTMLLine(t *testing.T) {
+	r := htmlRenderer{page: pageColors{"#ffffff", "#000000"}}
+
+	tests := []struct {
+		name  string
+		spans []span
+		html  string
+	}{
+		{"Escape", plain("<a & b>"), "&lt;a &amp; b&gt;"},
+		{
+			"Colors",
+			[]span{{text: "red", spanStyle: spanStyle{fg: "#ff0000", style: "bold"}}, {text: ` "plain"`}},
+			`<span style="color:#ff0000;font-weight:bold">red</span> &#34;plain&#34;`,
+		},
+		{
+			"ANSIColors",
+			[]span{{text: "blue", spanStyle: spanStyle{bg: "21", style: "italic"}}},
+			`<span style="background-color:#0000ff;font-style:italic">blue</span>`,
+		},
+		{
+			"Search",
+			[]span{{text: "lines", spanStyle: spanStyle{style: "crossout"}, under: []spanStyle{{style: "underline"}}}},
+			`<span style="text-decoration:underline line-through">lines</span>`,
+		},
+		{
+			"Input",
+			[]span{{text: "dim\x1b[0m plain", spanStyle: spanStyle{sgr: "\x1b[2;53m"}}},
+			`<span style="opacity:0.6;text-decoration:overline">dim</span> plain`,
+		},
+		// reversed text without colors gets the colors of the page
+		{
+			"Reverse",
+			[]span{{text: "reverse", spanStyle: spanStyle{style: "reverse"}}},
+			`<span style="color:#000000;background-color:#ffffff">reverse</span>`,
+		},
+		{
+			"ReverseColor",
+			[]span{{text: "reverse", spanStyle: spanStyle{fg: "1", style: "reverse"}}},
+			`<span style="color:#000000;background-color:#800000">reverse</span>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run("TestHTMLLine"+tt.name, func(t *testing.T) {
+			if html := r.line(tt.spans); html != tt.html {
+				t.Errorf("got %q, want %q", html, tt.html)
+			}
+		})
+	}
+
+	t.Run("TestHTMLLineReverseWithoutPage", func(t *testing.T) {
+		html := htmlRenderer{}.line([]span{{text: "reverse", spanStyle: spanStyle{style: "reverse"}}})
+		if want := `<span style="color:Canvas;background-color:CanvasText">reverse</span>`; html != want {
+			t.Errorf("got %q, want %q", html, want)
+		}
+	})
+}
+
+func TestHTMLDocument(t *testing.T) {
+	pre := `<pre class="logalize" style="margin:0;padding:1em;font-family:ui-monospace,monospace;color:#ffffff;background-color:#000000">`
+
+	header, footer := htmlRenderer{page: pageColors{"#ffffff", "#000000"}, fragment: true}.document()
+	if header != pre || footer != "</pre>\n" {
+		t.Errorf("got %q and %q for the fragment", header, footer)
+	}
+
+	header, footer = htmlRenderer{page: pageColors{"#ffffff", "#000000"}}.document()
+	if !strings.HasPrefix(header, "<!DOCTYPE html>\n") || !strings.HasSuffix(header, pre) ||
+		!strings.Contains(header, `<body style="margin:0;color:#ffffff;background-color:#000000">`) {
+		t.Errorf("got wrong header %q", header)
+	}
+	if footer != "</pre>\n</body>\n</html>\n" {
+		t.Errorf("got wrong footer %q", footer)
+	}
+
+	header, _ = htmlRenderer{}.document()
+	if !strings.Contains(header, "<body style=\"margin:0\">\n<pre class=\"logalize\" style=\"margin:0;padding:1em;font-family:ui-monospace,monospace\">") {
+		t.Errorf("got wrong header without page colors %q", header)
+	}
+}
+
+func TestHTMLHighlight(t *testing.T) {
+	cfg := koanf.New(".")
+	err := cfg.Load(file.Provider("./testdata/redact/newFormats/01_good.yaml"), yaml.Parser())
+	if err != nil {
+		t.Fatalf("cfg.Load(...) failed with this error: %s", err)
+	}
+
+	tests := []struct {
+		opts config.Options
+		html string
+	}{
+		{
+			config.Options{Output: "html"},
+			`<span style="color:#ff0000">10.1.2.3 </span>&lt;bob&gt; mail to ` +
+				`<span style="color:#0000ff">bob</span><span style="color:#ffff00">@</span>` +
+				`<span style="color:#ff00ff">example.com</span>`,
+		},
+		{config.Options{Output: "html", DryRun: true}, "10.1.2.3 &lt;bob&gt; mail to bob@example.com"},
+	}
+
+	for _, tt := range tests {
+		tt.opts.Theme = "test"
+		// HTML gets the colors of the theme even if the terminal doesn't support them
+		settings := config.Settings{Config: cfg, Opts: tt.opts, ColorProfile: termenv.Ascii}
+		hl, err := NewHighlighter(settings)
+		if err != nil {
+			t.Fatalf("NewHighlighter() failed with this error: %s", err)
+		}
+
+		t.Run("TestHTMLHighlight", func(t *testing.T) {
+			if html := hl.Colorize("10.1.2.3 <bob> mail to bob@example.com"); html != tt.html {
+				t.Errorf("got %q, want %q", html, tt.html)
+			}
+			if html, _ := hl.ColorizeMatch("10.1.2.3 <bob> mail to bob@example.com", Record{}); html != tt.html {
+				t.Errorf("got %q, want %q", html, tt.html)
+			}
+		})
+	}
+}
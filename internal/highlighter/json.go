@@ -0,0 +1,202 @@
+package highlighter
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/knadh/koanf/v2"
+)
+
+// jsonFormat represents a format for lines that contain one JSON object.
+//
+// Unlike regular formats it doesn't use regular expressions to split
+// the line. The line is tokenized instead and keys, strings, numbers,
+// booleans, null and punctuation are colored separately. Values of
+// the keys from Keys list are colored according to their own settings.
+type jsonFormat struct {
+	// Keys are matched against key names, their alternatives
+	// are matched against raw values (strings are in quotes)
+	Keys keyList `koanf:"keys"`
+
+	Key         tokenStyle `koanf:"-"`
+	Punctuation tokenStyle `koanf:"-"`
+	String      tokenStyle `koanf:"-"`
+	Number      tokenStyle `koanf:"-"`
+	Boolean     tokenStyle `koanf:"-"`
+	Null        tokenStyle `koanf:"-"`
+}
+
+// jsonElements are the names of JSON token kinds in a theme
+// ("null-value" because YAML parses unquoted "null" key as null)
+var jsonElements = []string{"key", "punctuation", "string", "number", "boolean", "null-value"}
+
+// collectJSONFormat reads a JSON format from "formats.<name>.json"
+func collectJSONFormat(config *koanf.Koanf, name string) (*jsonFormat, error) {
+	jf := &jsonFormat{}
+	if err := config.Unmarshal("formats."+name+".json", jf); err != nil {
+		return nil, err
+	}
+
+	return jf, nil
+}
+
+// init sets colors and styles from the theme and compiles regular expressions
+func (jf *jsonFormat) init(config *koanf.Koanf, path string) error {
+	styles := []*tokenStyle{&jf.Key, &jf.Punctuation, &jf.String, &jf.Number, &jf.Boolean, &jf.Null}
+	for i, element := range jsonElements {
+		*styles[i] = newTokenStyle(config, path+"."+element)
+		if err := styles[i].validate("json: " + element); err != nil {
+			return err
+		}
+	}
+
+	return jf.Keys.init(config, path+".keys")
+}
+
+// match reports whether the string is one valid JSON object
+func (jf *jsonFormat) match(str string) bool {
+	trimmed := strings.TrimLeft(str, " \t")
+	if trimmed == "" || trimmed[0] != '{' {
+		return false
+	}
+
+	return json.Valid([]byte(str))
+}
+
+// highlight colorizes every token of the JSON object and keeps
+// the original bytes (whitespace, escapes, key order) as they are.
+// The string must be valid JSON (see match).
+func (jf *jsonFormat) highlight(str string, h Highlighter) []span {
+	var out []span
+
+	// containers we are in ('{' or '[')
+	var stack []byte
+	// next string in an object is a key
+	expectKey := false
+	// the key the next value belongs to (if it's in Keys list)
+	var key *capGroup
+
+	for i := 0; i < len(str); {
+		c := str[i]
+		switch {
+		case isJSONSpace(c):
+			j := i
+			for j < len(str) && isJSONSpace(str[j]) {
+				j++
+			}
+			out = append(out, plain(str[i:j])...)
+			i = j
+
+		case c == '{' || c == '[':
+			stack = append(stack, c)
+			expectKey = c == '{'
+			// per-key settings are applied only to scalar values
+			key = nil
+			out = append(out, jf.Punctuation.highlight(str[i:i+1], "punctuation", h)...)
+			i++
+
+		case c == '}' || c == ']':
+			stack = stack[:len(stack)-1]
+			out = append(out, jf.Punctuation.highlight(str[i:i+1], "punctuation", h)...)
+			i++
+
+		case c == ',':
+			expectKey = len(stack) > 0 && stack[len(stack)-1] == '{'
+			out = append(out, jf.Punctuation.highlight(str[i:i+1], "punctuation", h)...)
+			i++
+
+		case c == ':':
+			out = append(out, jf.Punctuation.highlight(str[i:i+1], "punctuation", h)...)
+			i++
+
+		case c == '"':
+			j := jsonStringEnd(str, i)
+			token := str[i:j]
+			if expectKey {
+				out = append(out, jf.Key.highlight(token, "key", h)...)
+				key = jf.findKey(token)
+				expectKey = false
+			} else {
+				out = append(out, jf.Keys.highlightValue(token, jf.String, "string", key, h)...)
+				key = nil
+			}
+			i = j
+
+		default:
+			// number, true, false or null
+			j := i
+			for j < len(str) && !isJSONSpace(str[j]) && !strings.ContainsRune(",:]}", rune(str[j])) {
+				j++
+			}
+			token := str[i:j]
+			style, element := jf.Number, "number"
+			switch token {
+			case "true", "false":
+				style, element = jf.Boolean, "boolean"
+			case "null":
+				style, element = jf.Null, "null-value"
+			}
+			out = append(out, jf.Keys.highlightValue(token, style, element, key, h)...)
+			key = nil
+			i = j
+		}
+	}
+
+	return out
+}
+
+// value returns the raw value (strings are in quotes) of the first key
+// of the top-level object that matches the entry of Keys list
+func (jf *jsonFormat) value(str string, key *capGroup) (string, bool) {
+	decoder := json.NewDecoder(strings.NewReader(str))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return "", false
+	}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", false
+		}
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return "", false
+		}
+		if name, ok := token.(string); ok && key.RegExp.MatchString(name) {
+			return string(value), true
+		}
+	}
+
+	return "", false
+}
+
+// findKey returns the entry of Keys list that matches the quoted key name
+func (jf *jsonFormat) findKey(token string) *capGroup {
+	name := token[1 : len(token)-1]
+	if strings.Contains(name, `\`) {
+		if err := json.Unmarshal([]byte(token), &name); err != nil {
+			return nil
+		}
+	}
+
+	return jf.Keys.find(name)
+}
+
+// jsonStringEnd returns the index right after the closing quote
+// of the string that starts at index start
+func jsonStringEnd(str string, start int) int {
+	for i := start + 1; i < len(str); i++ {
+		switch str[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+
+	return len(str)
+}
+
+func isJSONSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
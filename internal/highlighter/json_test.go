@@ -0,0 +1,202 @@
+package highlighter
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/deponian/logalize/internal/config"
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/v2"
+	"github.com/muesli/termenv"
+)
+
+func compareJSONFormats(jf1, jf2 jsonFormat) error {
+	if jf1.Key != jf2.Key || jf1.Punctuation != jf2.Punctuation ||
+		jf1.String != jf2.String || jf1.Number != jf2.Number ||
+		jf1.Boolean != jf2.Boolean || jf1.Null != jf2.Null {
+		return fmt.Errorf("styles of JSON elements aren't equal")
+	}
+
+	if len(jf1.Keys) != len(jf2.Keys) {
+		return fmt.Errorf("keys have different length")
+	}
+
+	for i := range jf1.Keys {
+		if err := compareCapGroups(jf1.Keys[i], jf2.Keys[i]); err != nil {
+			return fmt.Errorf("[key1: %s, key2: %s]: %s", jf1.Keys[i].Name, jf2.Keys[i].Name, err)
+		}
+	}
+
+	return nil
+}
+
+func TestJSONFormatNewGood(t *testing.T) {
+	correctJSONFormat := jsonFormat{
+		Keys: []capGroup{
+			{
+				"level", `(level|lvl)`, "", "", "", "",
+				[]capGroup{
+					{"error", `((?i)"error")`, "#ff0000", "", "bold", "", nil, numRange{}, gradient{}, nil, false, regexp.MustCompile(`((?i)"error")`)},
+					{"bunyan-error", `(^50$)`, "#ff0000", "", "", "", nil, numRange{}, gradient{}, nil, false, regexp.MustCompile(`(^50$)`)},
+				},
+				numRange{},
+				gradient{},
+				nil,
+				false,
+				regexp.MustCompile(`^(?:level|lvl)$`),
+			},
+			{"message", `(msg)`, "", "", "patterns-and-words", "", nil, numRange{}, gradient{}, nil, false, regexp.MustCompile(`^(?:msg)$`)},
+		},
+		Key:         tokenStyle{"#0000ff", "", ""},
+		Punctuation: tokenStyle{"#505050", "", ""},
+		String:      tokenStyle{"#00ff00", "", ""},
+		Number:      tokenStyle{"#ff00ff", "", "bold"},
+		Boolean:     tokenStyle{"", "#00ffff", ""},
+		Null:        tokenStyle{"", "", "faint"},
+	}
+
+	cfg := koanf.New(".")
+	err := cfg.Load(file.Provider("./testdata/json/newJSONFormat/01_good.yaml"), yaml.Parser())
+	if err != nil {
+		t.Fatalf("cfg.Load(...) failed with this error: %s", err)
+	}
+
+	t.Run("TestJSONFormatNewGood", func(t *testing.T) {
+		formats, err := newFormats(cfg, "test")
+		if err != nil {
+			t.Fatalf("newFormats() failed with this error: %s", err)
+		}
+
+		if formats[0].CapGroups != nil || formats[0].JSON == nil {
+			t.Fatalf("format %s should have been a JSON format", formats[0].Name)
+		}
+
+		if err := compareJSONFormats(*formats[0].JSON, correctJSONFormat); err != nil {
+			t.Errorf("%s", err)
+		}
+	})
+}
+
+func TestJSONFormatNewBad(t *testing.T) {
+	tests := []string{
+		"./testdata/json/newJSONFormat/02_bad_yaml.yaml",
+		"./testdata/json/newJSONFormat/03_bad_color.yaml",
+		"./testdata/json/newJSONFormat/04_bad_key.yaml",
+		"./testdata/json/newJSONFormat/05_duplicate_key.yaml",
+	}
+
+	for _, tt := range tests {
+		cfg := koanf.New(".")
+		err := cfg.Load(file.Provider(tt), yaml.Parser())
+		if err != nil {
+			t.Fatalf("cfg.Load(...) failed with this error: %s", err)
+		}
+
+		t.Run("TestJSONFormatNewBad"+tt, func(t *testing.T) {
+			if _, err := newFormats(cfg, "test"); err == nil {
+				t.Errorf("newFormats() should have failed")
+			}
+		})
+	}
+}
+
+func TestJSONFormatMatch(t *testing.T) {
+	tests := []struct {
+		str   string
+		match bool
+	}{
+		{`{}`, true},
+		{`  {"a": 1}  `, true},
+		{`{"a": [1, 2, {"b": null}]}`, true},
+		{`[1, 2, 3]`, false},
+		{`"string"`, false},
+		{`{"a": 1`, false},
+		{`{"a": 1} trailing`, false},
+		{`{a: 1}`, false},
+		{``, false},
+		{`   `, false},
+		{`plain text {"a": 1}`, false},
+	}
+
+	jf := jsonFormat{}
+	for _, tt := range tests {
+		t.Run("TestJSONFormatMatch"+tt.str, func(t *testing.T) {
+			if got := jf.match(tt.str); got != tt.match {
+				t.Errorf("match(%q) = %v, want %v", tt.str, got, tt.match)
+			}
+		})
+	}
+}
+
+func TestJSONFormatHighlight(t *testing.T) {
+	tests := []struct {
+		plain   string
+		colored string
+	}{
+		{
+			`{}`,
+			"\x1b[38;2;80;80;80m{\x1b[0m\x1b[38;2;80;80;80m}\x1b[0m",
+		},
+		{
+			`{"level":"ERROR","msg":"got 42 errors","n":-1.5e3,"ok":true,"err":null}`,
+			"\x1b[38;2;80;80;80m{\x1b[0m\x1b[38;2;0;0;255m\"level\"\x1b[0m\x1b[38;2;80;80;80m:\x1b[0m" +
+				"\x1b[38;2;255;0;0;1m\"ERROR\"\x1b[0m\x1b[38;2;80;80;80m,\x1b[0m" +
+				"\x1b[38;2;0;0;255m\"msg\"\x1b[0m\x1b[38;2;80;80;80m:\x1b[0m" +
+				"\"got \x1b[38;2;255;255;0m42\x1b[0m errors\"\x1b[38;2;80;80;80m,\x1b[0m" +
+				"\x1b[38;2;0;0;255m\"n\"\x1b[0m\x1b[38;2;80;80;80m:\x1b[0m\x1b[38;2;255;0;255;1m-1.5e3\x1b[0m\x1b[38;2;80;80;80m,\x1b[0m" +
+				"\x1b[38;2;0;0;255m\"ok\"\x1b[0m\x1b[38;2;80;80;80m:\x1b[0m\x1b[48;2;0;255;255mtrue\x1b[0m\x1b[38;2;80;80;80m,\x1b[0m" +
+				"\x1b[38;2;0;0;255m\"err\"\x1b[0m\x1b[38;2;80;80;80m:\x1b[0m\x1b[2mnull\x1b[0m\x1b[38;2;80;80;80m}\x1b[0m",
+		},
+		// alternatives don't match, the value gets the color of its kind
+		{
+			`{ "lvl": "info", "level": 50 }`,
+			"\x1b[38;2;80;80;80m{\x1b[0m \x1b[38;2;0;0;255m\"lvl\"\x1b[0m\x1b[38;2;80;80;80m:\x1b[0m " +
+				"\x1b[38;2;0;255;0m\"info\"\x1b[0m\x1b[38;2;80;80;80m,\x1b[0m " +
+				"\x1b[38;2;0;0;255m\"level\"\x1b[0m\x1b[38;2;80;80;80m:\x1b[0m \x1b[38;2;255;0;0m50\x1b[0m " +
+				"\x1b[38;2;80;80;80m}\x1b[0m",
+		},
+		// escaped quotes, escaped key names and nested values
+		{
+			`{"a\"b":"c\\\"d","l\u0065vel":"error","level":{"x":["error"]}}`,
+			"\x1b[38;2;80;80;80m{\x1b[0m\x1b[38;2;0;0;255m\"a\\\"b\"\x1b[0m\x1b[38;2;80;80;80m:\x1b[0m" +
+				"\x1b[38;2;0;255;0m\"c\\\\\\\"d\"\x1b[0m\x1b[38;2;80;80;80m,\x1b[0m" +
+				"\x1b[38;2;0;0;255m\"l\\u0065vel\"\x1b[0m\x1b[38;2;80;80;80m:\x1b[0m\x1b[38;2;255;0;0;1m\"error\"\x1b[0m\x1b[38;2;80;80;80m,\x1b[0m" +
+				"\x1b[38;2;0;0;255m\"level\"\x1b[0m\x1b[38;2;80;80;80m:\x1b[0m\x1b[38;2;80;80;80m{\x1b[0m" +
+				"\x1b[38;2;0;0;255m\"x\"\x1b[0m\x1b[38;2;80;80;80m:\x1b[0m\x1b[38;2;80;80;80m[\x1b[0m" +
+				"\x1b[38;2;0;255;0m\"error\"\x1b[0m\x1b[38;2;80;80;80m]\x1b[0m\x1b[38;2;80;80;80m}\x1b[0m\x1b[38;2;80;80;80m}\x1b[0m",
+		},
+	}
+
+	cfg := koanf.New(".")
+	err := cfg.Load(file.Provider("./testdata/json/highlight/01_main.yaml"), yaml.Parser())
+	if err != nil {
+		t.Fatalf("cfg.Load(...) failed with this error: %s", err)
+	}
+
+	settings := config.Settings{
+		Config:       cfg,
+		Opts:         config.Options{Theme: "test"},
+		ColorProfile: termenv.TrueColor,
+	}
+
+	hl, err := NewHighlighter(settings)
+	if err != nil {
+		t.Fatalf("NewHighlighter() failed with this error: %s", err)
+	}
+
+	for _, tt := range tests {
+		t.Run("TestJSONFormatHighlight"+tt.plain, func(t *testing.T) {
+			colored := hl.Colorize(tt.plain)
+			if colored != tt.colored {
+				t.Errorf("got %q, want %q", colored, tt.colored)
+			}
+
+			// the original bytes must be kept as they are
+			if stripped := allANSIEscapeSequencesRegExp.ReplaceAllString(colored, ""); stripped != tt.plain {
+				t.Errorf("got %q after stripping colors, want %q", stripped, tt.plain)
+			}
+		})
+	}
+}
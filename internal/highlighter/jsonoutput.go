@@ -0,0 +1,102 @@
+package highlighter
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// jsonRenderer renders every line as a JSON object with the text
+// of the line and the spans of what was found in it, so other tools
+// can use the results without parsing SGR sequences
+type jsonRenderer struct{}
+
+// jsonLine is one line of JSON output
+type jsonLine struct {
+	Text  string     `json:"text"`
+	Spans []jsonSpan `json:"spans"`
+}
+
+// jsonSpan is a part of the line colored by one format, pattern,
+// word group, etc. Start and End are byte offsets in the text.
+// Colors are hex values like "#ff0000".
+type jsonSpan struct {
+	Start       int    `json:"start"`
+	End         int    `json:"end"`
+	Kind        string `json:"kind"`
+	Name        string `json:"name,omitempty"`
+	Group       string `json:"group,omitempty"`
+	Alternative string `json:"alternative,omitempty"`
+	Foreground  string `json:"fg,omitempty"`
+	Background  string `json:"bg,omitempty"`
+	Style       string `json:"style,omitempty"`
+}
+
+func (jsonRenderer) line(spans []span) string {
+	out := jsonLine{Text: spansText(spans), Spans: []jsonSpan{}}
+
+	offset := 0
+	for _, s := range spans {
+		start := offset
+		offset += len(s.text)
+
+		style := spanTextStyle(s)
+		colored := style != textStyle{}
+		// skip plain text that isn't a capturing group or a word
+		if s.text == "" || !colored && s.group == "" && s.kind != "word" {
+			continue
+		}
+
+		js := jsonSpan{
+			Start:       start,
+			End:         offset,
+			Kind:        s.kind,
+			Name:        s.name,
+			Group:       s.group,
+			Alternative: s.alternative,
+			Foreground:  style.fg,
+			Background:  style.bg,
+			Style:       strings.Join(style.names(), " "),
+		}
+		// the text colored with default color of the theme
+		if js.Kind == "" {
+			js.Kind = "default"
+		}
+		out.Spans = append(out.Spans, js)
+	}
+
+	var line strings.Builder
+	encoder := json.NewEncoder(&line)
+	encoder.SetEscapeHTML(false)
+	// the error is impossible since there are only strings and numbers
+	_ = encoder.Encode(out)
+
+	return strings.TrimSuffix(line.String(), "\n")
+}
+
+func (jsonRenderer) document() (string, string) {
+	return "", ""
+}
+
+// names returns the names of the styles that are set
+// (e.g. "bold" or "underline") without the colors
+func (s textStyle) names() []string {
+	var names []string
+	for _, style := range []struct {
+		name string
+		set  bool
+	}{
+		{"bold", s.bold},
+		{"faint", s.faint},
+		{"italic", s.italic},
+		{"underline", s.underline},
+		{"overline", s.overline},
+		{"crossout", s.crossout},
+		{"reverse", s.reverse},
+	} {
+		if style.set {
+			names = append(names, style.name)
+		}
+	}
+
+	return names
+}
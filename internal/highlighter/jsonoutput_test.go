@@ -0,0 +1,132 @@
+package highlighter
+
+import (
+	"testing"
+
+	"github.com/deponian/logalize/internal/config"
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/v2"
+	"github.com/muesli/termenv"
+)
+
+func TestJSONOutputLine(t *testing.T) {
+	r := jsonRenderer{}
+
+	tests := []struct {
+		name  string
+		spans []span
+		json  string
+	}{
+		{"Empty", nil, `{"text":"","spans":[]}`},
+		{"Plain", plain(`<a & "b">`), `{"text":"<a & \"b\">","spans":[]}`},
+		{
+			"Group",
+			[]span{
+				{text: "500 ", spanStyle: spanStyle{fg: "1", style: "bold"}, origin: origin{"format", "test", "status", "5xx"}},
+				{text: "bob ", origin: origin{kind: "format", name: "test", group: "user"}},
+				{text: "-", origin: origin{kind: "format", name: "test"}},
+			},
+			`{"text":"500 bob -","spans":[` +
+				`{"start":0,"end":4,"kind":"format","name":"test","group":"status","alternative":"5xx","fg":"#800000","style":"bold"},` +
+				`{"start":4,"end":8,"kind":"format","name":"test","group":"user"}]}`,
+		},
+		{
+			"Default",
+			[]span{{text: "text", spanStyle: spanStyle{bg: "#123456"}}},
+			`{"text":"text","spans":[{"start":0,"end":4,"kind":"default","bg":"#123456"}]}`,
+		},
+		{
+			"Search",
+			[]span{{
+				text:      "match",
+				spanStyle: spanStyle{style: "reverse"},
+				under:     []spanStyle{{fg: "#ff0000", style: "bold"}},
+				origin:    origin{kind: "search", name: "m.*"},
+			}},
+			`{"text":"match","spans":[{"start":0,"end":5,"kind":"search","name":"m.*","fg":"#ff0000","style":"bold reverse"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run("TestJSONOutputLine"+tt.name, func(t *testing.T) {
+			if json := r.line(tt.spans); json != tt.json {
+				t.Errorf("got %s, want %s", json, tt.json)
+			}
+		})
+	}
+}
+
+func TestJSONOutputHighlight(t *testing.T) {
+	cfg := koanf.New(".")
+	err := cfg.Load(file.Provider("./testdata/jsonoutput/line/01_main.yaml"), yaml.Parser())
+	if err != nil {
+		t.Fatalf("cfg.Load(...) failed with this error: %s", err)
+	}
+
+	tests := []struct {
+		plain string
+		json  string
+	}{
+		{
+			"503 bob failed at 10.0.0.1",
+			`{"text":"503 bob failed at 10.0.0.1","spans":[` +
+				`{"start":0,"end":4,"kind":"format","name":"test","group":"status","alternative":"5xx","fg":"#ff0000","style":"bold"},` +
+				`{"start":4,"end":8,"kind":"format","name":"test","group":"user"},` +
+				`{"start":8,"end":14,"kind":"word","name":"bad","fg":"#ff0000","style":"underline"},` +
+				`{"start":14,"end":18,"kind":"format","name":"test","group":"message","fg":"#cccccc"},` +
+				`{"start":18,"end":26,"kind":"pattern","name":"ipv4","group":"ipv4","fg":"#0000ff"}]}`,
+		},
+		{
+			"not a success",
+			`{"text":"not a success","spans":[` +
+				`{"start":0,"end":6,"kind":"default","fg":"#cccccc"},` +
+				`{"start":6,"end":13,"kind":"word","name":"good","fg":"#00ff00"}]}`,
+		},
+	}
+
+	settings := config.Settings{
+		Config:       cfg,
+		Opts:         config.Options{Theme: "test", Output: "json"},
+		ColorProfile: termenv.ANSI,
+	}
+	hl, err := NewHighlighter(settings)
+	if err != nil {
+		t.Fatalf("NewHighlighter() failed with this error: %s", err)
+	}
+
+	for _, tt := range tests {
+		t.Run("TestJSONOutputHighlight"+tt.plain, func(t *testing.T) {
+			if json := hl.Colorize(tt.plain); json != tt.json {
+				t.Errorf("got %s, want %s", json, tt.json)
+			}
+		})
+	}
+
+	t.Run("TestJSONOutputLineTerminator", func(t *testing.T) {
+		for _, terminator := range []string{"\r", "\n", "\r\n", ""} {
+			if got := hl.LineTerminator(terminator); got != "\n" {
+				t.Errorf("got %q for %q, want %q", got, terminator, "\n")
+			}
+		}
+	})
+}
+
+func TestJSONOutputDebugStats(t *testing.T) {
+	hl, err := newTestHighlighter(t, "./testdata/detector/detect/01_main.yaml", config.Options{Debug: true, Output: "json"})
+	if err != nil {
+		t.Fatalf("NewHighlighter() failed with this error: %s", err)
+	}
+
+	hl.Colorize("first 1")
+	hl.Colorize("third 2")
+
+	// every line of the statistics is a record of its own
+	stats := `{"text":"[debug] format detection: 2 lines, recently matched format matched 0 of them (0.0%)","spans":[]}` + "\n" +
+		`{"text":"[debug] format first (priority 0): 1 matches (50.0%)","spans":[]}` + "\n" +
+		`{"text":"[debug] format second (priority 0): 0 matches (0.0%)","spans":[]}` + "\n" +
+		`{"text":"[debug] no format: 1 lines (50.0%)","spans":[]}` + "\n"
+	if got := hl.DebugStats(); got != stats {
+		t.Errorf("got %q, want %q", got, stats)
+	}
+}
@@ -0,0 +1,235 @@
+package highlighter
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+
+	"github.com/knadh/koanf/v2"
+)
+
+// lineRule sets a color and a style of the whole line
+// if the line satisfies all conditions of the rule
+type lineRule struct {
+	Name     string
+	Priority int `koanf:"priority"`
+
+	// Format is the name of the format the line has to match
+	Format string `koanf:"format"`
+	// Group and Alternative is the alternative of the capturing group
+	// (or the key of JSON format) that has to be chosen in the line
+	Group       string `koanf:"group"`
+	Alternative string `koanf:"alternative"`
+	// Pattern is the name of the pattern that has to be found in the line
+	Pattern string `koanf:"pattern"`
+	// Words is the name of the word group that has to be found in the line
+	Words string `koanf:"words"`
+	// MinLevel is the lowest severity level of the line
+	MinLevel string `koanf:"min-level"`
+
+	Foreground string `koanf:"-"`
+	Background string `koanf:"-"`
+	Style      string `koanf:"-"`
+
+	format   *format
+	group    *capGroup
+	index    int
+	minLevel Level
+}
+
+type lineRuleList []lineRule
+
+// newLineRules returns list of whole-line styling rules collected
+// from *koanf.Koanf configuration using a theme from the second argument.
+// Conditions of the rules are checked against the formats, patterns
+// and word groups from the other arguments.
+func newLineRules(
+	config *koanf.Koanf, theme string, formats formatList, patterns patternList, words wordGroups,
+) (lineRuleList, error) {
+	if config == nil {
+		return lineRuleList{}, nil
+	}
+
+	var rules lineRuleList
+	for _, name := range config.MapKeys("lines") {
+		rule := lineRule{Name: name}
+		if err := config.Unmarshal("lines."+name, &rule); err != nil {
+			return nil, err
+		}
+
+		path := "themes." + theme + ".lines." + name
+		rule.Foreground = config.String(path + ".fg")
+		rule.Background = config.String(path + ".bg")
+		rule.Style = config.String(path + ".style")
+
+		if err := rule.init(formats, patterns, words); err != nil {
+			return nil, fmt.Errorf("[line: %s] %s", name, err)
+		}
+		rules = append(rules, rule)
+	}
+
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].Priority > rules[j].Priority
+	})
+
+	return rules, nil
+}
+
+// init validates the rule and finds formats, capturing groups
+// and alternatives it refers to
+func (r *lineRule) init(formats formatList, patterns patternList, words wordGroups) error {
+	if err := r.validate(); err != nil {
+		return err
+	}
+
+	if r.MinLevel != "" {
+		level, err := ParseLevel(r.MinLevel)
+		if err != nil {
+			return err
+		}
+		r.minLevel = level
+	}
+
+	if r.Pattern != "" && !slices.ContainsFunc(patterns, func(p pattern) bool { return p.Name == r.Pattern }) {
+		return fmt.Errorf("pattern %q is not defined", r.Pattern)
+	}
+	if r.Words != "" && !words.has(r.Words) {
+		return fmt.Errorf("word group %q is not defined", r.Words)
+	}
+
+	if r.Format == "" {
+		if r.Group != "" || r.Alternative != "" {
+			return fmt.Errorf("\"group\" and \"alternative\" can be used only with \"format\"")
+		}
+
+		return nil
+	}
+
+	i := slices.IndexFunc(formats, func(lf format) bool { return lf.Name == r.Format })
+	if i == -1 {
+		return fmt.Errorf("format %q is not defined", r.Format)
+	}
+	r.format = &formats[i]
+
+	if (r.Group == "") != (r.Alternative == "") {
+		return fmt.Errorf("\"group\" and \"alternative\" must be used together")
+	}
+	if r.Group == "" {
+		return nil
+	}
+
+	var ok bool
+	if r.group, r.index, ok = r.format.findGroup(r.Group); !ok {
+		return fmt.Errorf("format %q has no capturing group %q", r.Format, r.Group)
+	}
+	if !slices.ContainsFunc(r.group.Alternatives, func(alt capGroup) bool { return alt.Name == r.Alternative }) {
+		return fmt.Errorf("%q has no alternative %q", r.Group, r.Alternative)
+	}
+
+	return nil
+}
+
+func (r *lineRule) validate() error {
+	if r.Format == "" && r.Pattern == "" && r.Words == "" && r.MinLevel == "" {
+		return fmt.Errorf("rule must have at least one of \"format\", \"pattern\", \"words\" and \"min-level\" fields")
+	}
+
+	// check foreground
+	if !colorRegExp.MatchString(r.Foreground) {
+		return fmt.Errorf("foreground color %s doesn't match %s pattern", r.Foreground, colorRegExp)
+	}
+
+	// check background
+	if !colorRegExp.MatchString(r.Background) {
+		return fmt.Errorf("background color %s doesn't match %s pattern", r.Background, colorRegExp)
+	}
+
+	// check style
+	if !nonRecursiveStyleRegExp.MatchString(r.Style) {
+		return fmt.Errorf("style %s doesn't match %s pattern", r.Style, nonRecursiveStyleRegExp)
+	}
+
+	return nil
+}
+
+// holds reports whether the line with the match satisfies all conditions of the rule
+func (r *lineRule) holds(line string, m Match) bool {
+	if r.Format != "" && m.Format != r.Format {
+		return false
+	}
+	if r.Pattern != "" && !slices.Contains(m.Patterns, r.Pattern) {
+		return false
+	}
+	if r.Words != "" && !slices.Contains(m.WordGroups, r.Words) {
+		return false
+	}
+	if r.minLevel != LevelUnknown {
+		// lines without any severity are considered informational
+		level := m.Level
+		if level == LevelUnknown {
+			level = LevelInfo
+		}
+		if level < r.minLevel {
+			return false
+		}
+	}
+	if r.group != nil {
+		value, ok := r.format.groupValue(line, r.group, r.index)
+		if !ok {
+			return false
+		}
+		alt := r.group.alternative(value, "")
+
+		return alt != nil && alt.Name == r.Alternative
+	}
+
+	return true
+}
+
+// find returns the first rule that holds for the line or nil
+func (rules lineRuleList) find(line string, m Match) *lineRule {
+	for i := range rules {
+		if rules[i].holds(line, m) {
+			return &rules[i]
+		}
+	}
+
+	return nil
+}
+
+// findGroup returns the capturing group (or the key of JSON format)
+// with the name and its index
+func (lf format) findGroup(name string) (*capGroup, int, bool) {
+	if lf.JSON != nil {
+		i := slices.IndexFunc(lf.JSON.Keys, func(key capGroup) bool { return key.Name == name })
+		if i == -1 {
+			return nil, 0, false
+		}
+
+		return &lf.JSON.Keys[i], i, true
+	}
+
+	i, ok := lf.CapGroups.index[name]
+	if !ok {
+		return nil, 0, false
+	}
+
+	return &lf.CapGroups.groups[i], i, true
+}
+
+// groupValue returns the part of the line matched by the capturing
+// group with the index (or the raw value of the key of JSON format)
+func (lf format) groupValue(str string, group *capGroup, index int) (string, bool) {
+	if lf.JSON != nil {
+		return lf.JSON.value(str, group)
+	}
+
+	cgl := lf.CapGroups
+	matches := cgl.fullRegExp.FindStringSubmatch(str)
+	if matches == nil {
+		return "", false
+	}
+
+	return matches[cgl.fullRegExp.SubexpIndex("capGroup"+strconv.Itoa(index))], true
+}
@@ -0,0 +1,182 @@
+package highlighter
+
+import (
+	"testing"
+
+	"github.com/deponian/logalize/internal/config"
+	"github.com/google/go-cmp/cmp"
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/v2"
+	"github.com/muesli/termenv"
+)
+
+func TestLinesNewGood(t *testing.T) {
+	hl, err := newTestHighlighter(t, "./testdata/lines/newLineRules/01_good.yaml", config.Options{})
+	if err != nil {
+		t.Fatalf("NewHighlighter() failed with this error: %s", err)
+	}
+
+	t.Run("TestLinesNewGoodOrder", func(t *testing.T) {
+		var names []string
+		for _, rule := range hl.lines {
+			names = append(names, rule.Name)
+		}
+		want := []string{"server-errors", "errors", "friends"}
+		if len(names) != len(want) {
+			t.Fatalf("got %v, want %v", names, want)
+		}
+		for i := range want {
+			if names[i] != want[i] {
+				t.Errorf("got %v, want %v", names, want)
+			}
+		}
+	})
+
+	t.Run("TestLinesNewGoodResolved", func(t *testing.T) {
+		rule := hl.lines[0]
+		if rule.format == nil || rule.format.Name != "access" {
+			t.Errorf("format of %q isn't resolved", rule.Name)
+		}
+		if rule.group == nil || rule.group.Name != "status" || rule.index != 1 {
+			t.Errorf("capturing group of %q isn't resolved", rule.Name)
+		}
+		if hl.lines[1].minLevel != LevelError {
+			t.Errorf("got %s, want %s", hl.lines[1].minLevel, LevelError)
+		}
+	})
+}
+
+func TestLinesNewBad(t *testing.T) {
+	tests := []string{
+		"./testdata/lines/newLineRules/02_bad_yaml.yaml",
+		"./testdata/lines/newLineRules/03_empty.yaml",
+		"./testdata/lines/newLineRules/04_unknown_format.yaml",
+		"./testdata/lines/newLineRules/05_unknown_group.yaml",
+		"./testdata/lines/newLineRules/06_unknown_alternative.yaml",
+		"./testdata/lines/newLineRules/07_group_without_alternative.yaml",
+		"./testdata/lines/newLineRules/08_group_without_format.yaml",
+		"./testdata/lines/newLineRules/09_unknown_pattern.yaml",
+		"./testdata/lines/newLineRules/10_unknown_words.yaml",
+		"./testdata/lines/newLineRules/11_bad_level.yaml",
+		"./testdata/lines/newLineRules/12_bad_style.yaml",
+		"./testdata/lines/newLineRules/13_bad_color.yaml",
+	}
+
+	for _, tt := range tests {
+		cfg := koanf.New(".")
+		err := cfg.Load(file.Provider(tt), yaml.Parser())
+		if err != nil {
+			t.Fatalf("cfg.Load(...) failed with this error: %s", err)
+		}
+
+		t.Run("TestLinesNewBad"+tt, func(t *testing.T) {
+			settings := config.Settings{Config: cfg, Opts: config.Options{Theme: "test"}}
+			if _, err := NewHighlighter(settings); err == nil {
+				t.Errorf("NewHighlighter() should have failed")
+			}
+		})
+	}
+}
+
+func TestLinesColorize(t *testing.T) {
+	tests := []struct {
+		plain   string
+		colored string
+	}{
+		// format with the alternative
+		{
+			"GET 503 upstream",
+			"\x1b[38;2;0;0;255;48;2;51;0;0;1mGET \x1b[0m\x1b[38;2;255;0;0;48;2;51;0;0;1m503\x1b[0m\x1b[48;2;51;0;0;1m upstream\x1b[0m",
+		},
+		// format without the alternative
+		{
+			"GET 200 ok",
+			"\x1b[38;2;0;0;255mGET \x1b[0m\x1b[38;2;0;255;0m200\x1b[0m ok",
+		},
+		// level from words
+		{
+			"it fails",
+			"\x1b[48;2;59;31;43mit \x1b[0m\x1b[38;2;240;108;97;48;2;59;31;43mfails\x1b[0m",
+		},
+		// both words and pattern
+		{
+			"toni 42",
+			"\x1b[38;2;248;52;178;4mtoni\x1b[0m\x1b[38;2;170;170;170;4m \x1b[0m\x1b[38;2;255;255;0;4m42\x1b[0m",
+		},
+		// only one of the conditions holds
+		{
+			"toni",
+			"\x1b[38;2;248;52;178mtoni\x1b[0m",
+		},
+	}
+
+	hl, err := newTestHighlighter(t, "./testdata/lines/newLineRules/01_good.yaml", config.Options{})
+	if err != nil {
+		t.Fatalf("NewHighlighter() failed with this error: %s", err)
+	}
+
+	for _, tt := range tests {
+		t.Run("TestLinesColorize"+tt.plain, func(t *testing.T) {
+			if colored := hl.Colorize(tt.plain); colored != tt.colored {
+				t.Errorf("got %q, want %q", colored, tt.colored)
+			}
+		})
+	}
+
+	t.Run("TestLinesColorizeDryRun", func(t *testing.T) {
+		hl, err := newTestHighlighter(t, "./testdata/lines/newLineRules/01_good.yaml", config.Options{DryRun: true})
+		if err != nil {
+			t.Fatalf("NewHighlighter() failed with this error: %s", err)
+		}
+		if colored := hl.Colorize("it fails"); colored != "it fails" {
+			t.Errorf("got %q, want %q", colored, "it fails")
+		}
+	})
+
+	t.Run("TestLinesColorizeDetectOnce", func(t *testing.T) {
+		hl, err := newTestHighlighter(t, "./testdata/lines/newLineRules/01_good.yaml", config.Options{Debug: true})
+		if err != nil {
+			t.Fatalf("NewHighlighter() failed with this error: %s", err)
+		}
+		// the style of "server-errors" rule doesn't colorize the line again
+		hl.Colorize("GET 503 upstream")
+		hl.Colorize("GET 200 ok")
+
+		stats := "[debug] format detection: 2 lines, recently matched format matched 1 of them (50.0%)\n" +
+			"[debug] format access (priority 0): 2 matches (100.0%)\n" +
+			"[debug] no format: 0 lines (0.0%)\n"
+		if got := hl.DebugStats(); got != stats {
+			t.Errorf("got %q, want %q", got, stats)
+		}
+	})
+
+	t.Run("TestLinesColorizeMatch", func(t *testing.T) {
+		_, match := hl.ColorizeMatch("GET 503 upstream", Record{})
+		if match.Level != LevelError || match.Format != "access" {
+			t.Errorf("got %v, want format access with error level", match)
+		}
+	})
+
+	// whole-line styles don't change what is found in the line
+	cfg := koanf.New(".")
+	if err := cfg.Load(file.Provider("./testdata/lines/newLineRules/01_good.yaml"), yaml.Parser()); err != nil {
+		t.Fatalf("cfg.Load(...) failed with this error: %s", err)
+	}
+	cfg.Delete("lines")
+	cfg.Delete("themes.test.lines")
+	settings := config.Settings{Config: cfg, Opts: config.Options{Theme: "test"}, ColorProfile: termenv.TrueColor}
+	withoutLines, err := NewHighlighter(settings)
+	if err != nil {
+		t.Fatalf("NewHighlighter() failed with this error: %s", err)
+	}
+	for _, tt := range tests {
+		t.Run("TestLinesColorizeSameMatch"+tt.plain, func(t *testing.T) {
+			_, match := hl.ColorizeMatch(tt.plain, Record{})
+			_, want := withoutLines.ColorizeMatch(tt.plain, Record{})
+			if !cmp.Equal(match, want) {
+				t.Errorf("got %v, want %v", match, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package highlighter
+
+import (
+	"strings"
+
+	"github.com/knadh/koanf/v2"
+)
+
+// logfmtPattern represents a pattern that finds logfmt pairs
+// (key=value, key="quoted value" and key=) in any part of a line.
+//
+// Unlike regular patterns it doesn't use regular expressions.
+// The pairs are found by a tokenizer that understands escaped quotes,
+// empty values and values with "=" in them. Everything between
+// the pairs is left for other patterns and words.
+type logfmtPattern struct {
+	// Keys are matched against key names, their alternatives
+	// are matched against values (without quotation marks)
+	Keys keyList `koanf:"keys"`
+
+	Key           tokenStyle `koanf:"-"`
+	EqualSign     tokenStyle `koanf:"-"`
+	QuotationMark tokenStyle `koanf:"-"`
+	Value         tokenStyle `koanf:"-"`
+}
+
+// logfmtElements are the names of logfmt token kinds in a theme
+var logfmtElements = []string{"key", "equal-sign", "quotation-mark", "value"}
+
+// logfmtPair is one key=value pair
+type logfmtPair struct {
+	key    string
+	value  string
+	quoted bool
+	end    int // index right after the pair
+}
+
+// collectLogfmtPattern reads a logfmt pattern from "patterns.<name>.logfmt"
+func collectLogfmtPattern(config *koanf.Koanf, name string) (*logfmtPattern, error) {
+	lp := &logfmtPattern{}
+	if err := config.Unmarshal("patterns."+name+".logfmt", lp); err != nil {
+		return nil, err
+	}
+
+	return lp, nil
+}
+
+// init sets colors and styles from the theme and compiles regular expressions
+func (lp *logfmtPattern) init(config *koanf.Koanf, path string) error {
+	styles := []*tokenStyle{&lp.Key, &lp.EqualSign, &lp.QuotationMark, &lp.Value}
+	for i, element := range logfmtElements {
+		*styles[i] = newTokenStyle(config, path+"."+element)
+		if err := styles[i].validate("logfmt: " + element); err != nil {
+			return err
+		}
+	}
+
+	return lp.Keys.init(config, path+".keys")
+}
+
+// find returns the position of the first pair in the string
+// in the same form as regexp.FindStringIndex does
+func (lp *logfmtPattern) find(str string) []int {
+	for i := range len(str) {
+		// a key starts at the beginning of the string or after whitespace
+		if i > 0 && !isLogfmtSpace(str[i-1]) {
+			continue
+		}
+		if pair, ok := parseLogfmtPair(str[i:]); ok {
+			return []int{i, i + pair.end}
+		}
+	}
+
+	return nil
+}
+
+// highlight colorizes the pair found by find
+func (lp *logfmtPattern) highlight(str string, h Highlighter) []span {
+	pair, _ := parseLogfmtPair(str)
+
+	// values are colored with other patterns, but they
+	// shouldn't be split into pairs once again
+	h.patterns = h.patterns.withoutLogfmt()
+
+	spans := append(lp.Key.highlight(pair.key, "key", h), lp.EqualSign.highlight("=", "equal-sign", h)...)
+	var value []span
+	if pair.value != "" {
+		value = lp.Keys.highlightValue(pair.value, lp.Value, "value", lp.Keys.find(pair.key), h)
+	}
+	if pair.quoted {
+		quote := lp.QuotationMark.highlight(`"`, "quotation-mark", h)
+		spans = append(spans, quote...)
+		spans = append(spans, value...)
+
+		return append(spans, quote...)
+	}
+
+	return append(spans, value...)
+}
+
+// parseLogfmtPair parses the pair at the beginning of the string
+func parseLogfmtPair(str string) (logfmtPair, bool) {
+	// key is everything up to "=" without spaces and quotation marks
+	eq := strings.IndexByte(str, '=')
+	if eq <= 0 || strings.ContainsAny(str[:eq], " \t\"") {
+		return logfmtPair{}, false
+	}
+	pair := logfmtPair{key: str[:eq]}
+	rest := str[eq+1:]
+
+	// quoted value with escaped quotation marks in it
+	if strings.HasPrefix(rest, `"`) {
+		for i := 1; i < len(rest); i++ {
+			switch rest[i] {
+			case '\\':
+				i++
+			case '"':
+				pair.value = rest[1:i]
+				pair.quoted = true
+				pair.end = eq + 1 + i + 1
+
+				return pair, true
+			}
+		}
+		// there is no closing quotation mark,
+		// so the value is a bare one
+	}
+
+	// bare value (possibly empty) lasts until whitespace
+	end := strings.IndexAny(rest, " \t")
+	if end == -1 {
+		end = len(rest)
+	}
+	pair.value = rest[:end]
+	pair.end = eq + 1 + end
+
+	return pair, true
+}
+
+func isLogfmtSpace(c byte) bool {
+	return c == ' ' || c == '\t'
+}
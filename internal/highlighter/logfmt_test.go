@@ -0,0 +1,202 @@
+package highlighter
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"testing"
+
+	"github.com/deponian/logalize/internal/config"
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/v2"
+	"github.com/muesli/termenv"
+)
+
+func compareLogfmtPatterns(lp1, lp2 logfmtPattern) error {
+	if lp1.Key != lp2.Key || lp1.EqualSign != lp2.EqualSign ||
+		lp1.QuotationMark != lp2.QuotationMark || lp1.Value != lp2.Value {
+		return fmt.Errorf("styles of logfmt elements aren't equal")
+	}
+
+	if len(lp1.Keys) != len(lp2.Keys) {
+		return fmt.Errorf("keys have different length")
+	}
+
+	for i := range lp1.Keys {
+		if err := compareCapGroups(lp1.Keys[i], lp2.Keys[i]); err != nil {
+			return fmt.Errorf("[key1: %s, key2: %s]: %s", lp1.Keys[i].Name, lp2.Keys[i].Name, err)
+		}
+	}
+
+	return nil
+}
+
+func TestLogfmtPatternNewGood(t *testing.T) {
+	correctPattern := pattern{
+		"test", 10, nil,
+		&logfmtPattern{
+			Keys: []capGroup{
+				{
+					"level", `(level|lvl)`, "", "", "", "",
+					[]capGroup{
+						{"error", `((?i)^error$)`, "#ff0000", "", "bold", "", nil, numRange{}, gradient{}, nil, false, regexp.MustCompile(`((?i)^error$)`)},
+					},
+					numRange{},
+					gradient{},
+					nil,
+					false,
+					regexp.MustCompile(`^(?:level|lvl)$`),
+				},
+				{"duration", `(duration)`, "", "", "patterns", "", nil, numRange{}, gradient{}, nil, false, regexp.MustCompile(`^(?:duration)$`)},
+			},
+			Key:           tokenStyle{"#0000ff", "", ""},
+			EqualSign:     tokenStyle{"#505050", "", ""},
+			QuotationMark: tokenStyle{"#00ff00", "", "bold"},
+			Value:         tokenStyle{"", "", "patterns-and-words"},
+		},
+	}
+
+	cfg := koanf.New(".")
+	err := cfg.Load(file.Provider("./testdata/logfmt/newLogfmtPattern/01_good.yaml"), yaml.Parser())
+	if err != nil {
+		t.Fatalf("cfg.Load(...) failed with this error: %s", err)
+	}
+
+	t.Run("TestLogfmtPatternNewGood", func(t *testing.T) {
+		patterns, err := newPatterns(cfg, "test")
+		if err != nil {
+			t.Fatalf("newPatterns() failed with this error: %s", err)
+		}
+
+		if err := comparePatterns(patterns[0], correctPattern); err != nil {
+			t.Errorf("%s", err)
+		}
+	})
+}
+
+func TestLogfmtPatternNewBad(t *testing.T) {
+	tests := []string{
+		"./testdata/logfmt/newLogfmtPattern/02_bad_yaml.yaml",
+		"./testdata/logfmt/newLogfmtPattern/03_bad_color.yaml",
+		"./testdata/logfmt/newLogfmtPattern/04_bad_key.yaml",
+		"./testdata/logfmt/newLogfmtPattern/05_duplicate_key.yaml",
+	}
+
+	for _, tt := range tests {
+		cfg := koanf.New(".")
+		err := cfg.Load(file.Provider(tt), yaml.Parser())
+		if err != nil {
+			t.Fatalf("cfg.Load(...) failed with this error: %s", err)
+		}
+
+		t.Run("TestLogfmtPatternNewBad"+tt, func(t *testing.T) {
+			if _, err := newPatterns(cfg, "test"); err == nil {
+				t.Errorf("newPatterns() should have failed")
+			}
+		})
+	}
+}
+
+func TestLogfmtPatternFind(t *testing.T) {
+	tests := []struct {
+		str   string
+		found []int
+	}{
+		{`key=value`, []int{0, 9}},
+		{`key=`, []int{0, 4}},
+		{`key= next=1`, []int{0, 4}},
+		{`query=a=b&c=d rest`, []int{0, 13}},
+		{`msg="a \"quoted\" word" rest`, []int{0, 23}},
+		{`msg="a\\" rest"`, []int{0, 9}},
+		{`msg="unterminated value`, []int{0, 17}},
+		{`some words then key=value`, []int{16, 25}},
+		{"tab\tkey=value", []int{4, 13}},
+		{`=value`, nil},
+		{`no pairs here`, nil},
+		{`"quoted"=value`, nil},
+		{`a==b`, []int{0, 4}},
+		{``, nil},
+	}
+
+	lp := logfmtPattern{}
+	for _, tt := range tests {
+		t.Run("TestLogfmtPatternFind"+tt.str, func(t *testing.T) {
+			if got := lp.find(tt.str); !slices.Equal(got, tt.found) {
+				t.Errorf("find(%q) = %v, want %v", tt.str, got, tt.found)
+			}
+		})
+	}
+}
+
+func TestLogfmtPatternHighlight(t *testing.T) {
+	tests := []struct {
+		plain   string
+		colored string
+	}{
+		{
+			`level=error`,
+			"\x1b[38;2;0;0;255mlevel\x1b[0m\x1b[38;2;80;80;80m=\x1b[0m\x1b[38;2;255;0;0;1merror\x1b[0m",
+		},
+		{
+			`level=ERROR lvl="error" level=info`,
+			"\x1b[38;2;0;0;255mlevel\x1b[0m\x1b[38;2;80;80;80m=\x1b[0m\x1b[38;2;255;0;0;1mERROR\x1b[0m " +
+				"\x1b[38;2;0;0;255mlvl\x1b[0m\x1b[38;2;80;80;80m=\x1b[0m\x1b[38;2;0;255;0m\"\x1b[0m\x1b[38;2;255;0;0;1merror\x1b[0m\x1b[38;2;0;255;0m\"\x1b[0m " +
+				"\x1b[38;2;0;0;255mlevel\x1b[0m\x1b[38;2;80;80;80m=\x1b[0minfo",
+		},
+		// escaped quotation marks
+		{
+			`msg="got \"42\" errors" count=7`,
+			"\x1b[38;2;0;0;255mmsg\x1b[0m\x1b[38;2;80;80;80m=\x1b[0m\x1b[38;2;0;255;0m\"\x1b[0mgot \\\"\x1b[38;2;255;255;0m42\x1b[0m\\\" errors\x1b[38;2;0;255;0m\"\x1b[0m " +
+				"\x1b[38;2;0;0;255mcount\x1b[0m\x1b[38;2;80;80;80m=\x1b[0m\x1b[38;2;255;255;0m7\x1b[0m",
+		},
+		// empty values and values with "=" in them
+		{
+			`key= query=a=b duration=15`,
+			"\x1b[38;2;0;0;255mkey\x1b[0m\x1b[38;2;80;80;80m=\x1b[0m \x1b[38;2;0;0;255mquery\x1b[0m\x1b[38;2;80;80;80m=\x1b[0ma=b " +
+				"\x1b[38;2;0;0;255mduration\x1b[0m\x1b[38;2;80;80;80m=\x1b[0m\x1b[38;2;255;255;0m15\x1b[0m",
+		},
+		// bare words are left for word groups
+		{
+			`true words stay words, x=true`,
+			"\x1b[38;2;81;250;138mtrue\x1b[0m words stay words, " +
+				"\x1b[38;2;0;0;255mx\x1b[0m\x1b[38;2;80;80;80m=\x1b[0m\x1b[38;2;81;250;138mtrue\x1b[0m",
+		},
+		// values aren't split into pairs once again
+		{
+			`msg="key=value"`,
+			"\x1b[38;2;0;0;255mmsg\x1b[0m\x1b[38;2;80;80;80m=\x1b[0m\x1b[38;2;0;255;0m\"\x1b[0mkey=value\x1b[38;2;0;255;0m\"\x1b[0m",
+		},
+	}
+
+	cfg := koanf.New(".")
+	err := cfg.Load(file.Provider("./testdata/logfmt/highlight/01_main.yaml"), yaml.Parser())
+	if err != nil {
+		t.Fatalf("cfg.Load(...) failed with this error: %s", err)
+	}
+
+	settings := config.Settings{
+		Config:       cfg,
+		Opts:         config.Options{Theme: "test"},
+		ColorProfile: termenv.TrueColor,
+	}
+
+	hl, err := NewHighlighter(settings)
+	if err != nil {
+		t.Fatalf("NewHighlighter() failed with this error: %s", err)
+	}
+
+	for _, tt := range tests {
+		t.Run("TestLogfmtPatternHighlight"+tt.plain, func(t *testing.T) {
+			colored := hl.Colorize(tt.plain)
+			if colored != tt.colored {
+				t.Errorf("got %q, want %q", colored, tt.colored)
+			}
+
+			// the original bytes must be kept as they are
+			if stripped := allANSIEscapeSequencesRegExp.ReplaceAllString(colored, ""); stripped != tt.plain {
+				t.Errorf("got %q after stripping colors, want %q", stripped, tt.plain)
+			}
+		})
+	}
+}
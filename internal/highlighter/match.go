@@ -0,0 +1,144 @@
+package highlighter
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/deponian/logalize/internal/config"
+)
+
+// Match describes what was found in a line during colorization
+type Match struct {
+	// Format is the name of the format the line matched
+	// (or the format of its multiline record) or empty
+	Format string
+	// Patterns are names of the patterns found in the line
+	Patterns []string
+	// WordGroups are names of the word groups found in the line.
+	// Negated words (e.g. "not successful") belong to the opposite group.
+	WordGroups []string
+	// Level is the severity of the line from its format (or its record)
+	// or from its word groups if the format doesn't have severity
+	Level Level
+	// Time is the time of the line or of the last line with a timestamp
+	// before it. It's set only if user set --since or --until flags.
+	Time time.Time
+}
+
+// ColorizeMatch does the same as ColorizeRecord and also
+// tells what formats, patterns and words were found in the line.
+// It finds them even if user set --dry-run flag.
+func (h Highlighter) ColorizeMatch(line string, record Record) (string, Match) {
+	m := Match{Time: record.time}
+	h.match = &m
+
+	spans := h.colorize(line, record)
+	if h.settings.Opts.DryRun {
+		spans = plain(line)
+	}
+
+	m.resolveLevel()
+
+	return h.renderer.line(spans), m
+}
+
+// resolveLevel takes the level of the line from its word groups
+// if its format (or record) didn't tell the level
+func (m *Match) resolveLevel() {
+	if m.Level == LevelUnknown {
+		m.Level = levelFromWords(m.WordGroups)
+	}
+}
+
+// Filtering reports whether user asked to show only some of the lines
+// (see --only-matching-format, --match-pattern, --match-words,
+// --min-level, --since and --until flags)
+func (h Highlighter) Filtering() bool {
+	opts := h.settings.Opts
+
+	return len(opts.OnlyMatchingFormats) > 0 || len(opts.MatchPatterns) > 0 || len(opts.MatchWords) > 0 ||
+		h.minLevel != LevelUnknown || h.timeRange != nil
+}
+
+// Wanted reports whether a line with the match should be shown.
+// The line must satisfy every filter, but only one of the names
+// given to a filter has to be found in it.
+func (h Highlighter) Wanted(m Match) bool {
+	opts := h.settings.Opts
+
+	if len(opts.OnlyMatchingFormats) > 0 && !slices.Contains(opts.OnlyMatchingFormats, m.Format) {
+		return false
+	}
+	if len(opts.MatchPatterns) > 0 && !containsAny(opts.MatchPatterns, m.Patterns) {
+		return false
+	}
+	if len(opts.MatchWords) > 0 && !containsAny(opts.MatchWords, m.WordGroups) {
+		return false
+	}
+	if h.timeRange != nil && !h.timeRange.contains(m.Time) {
+		return false
+	}
+
+	// lines without any severity are considered informational
+	level := m.Level
+	if level == LevelUnknown {
+		level = LevelInfo
+	}
+
+	return level >= h.minLevel
+}
+
+// addMatch remembers that the format, pattern or word group
+// was found in the line (only if the caller asked for it)
+func (h Highlighter) addMatch(kind any) {
+	if h.match == nil {
+		return
+	}
+
+	switch k := kind.(type) {
+	case format:
+		h.match.Format = k.Name
+	case pattern:
+		if !slices.Contains(h.match.Patterns, k.Name) {
+			h.match.Patterns = append(h.match.Patterns, k.Name)
+		}
+	case wordGroup:
+		// "good" and "bad" groups may be undefined
+		if k.Name != "" && !slices.Contains(h.match.WordGroups, k.Name) {
+			h.match.WordGroups = append(h.match.WordGroups, k.Name)
+		}
+	}
+}
+
+// validateFilters checks that filters refer to existing
+// formats, patterns and word groups
+func validateFilters(opts config.Options, formats formatList, patterns patternList, words wordGroups) error {
+	for _, name := range opts.OnlyMatchingFormats {
+		if !slices.ContainsFunc(formats, func(lf format) bool { return lf.Name == name }) {
+			return fmt.Errorf("format %q is not defined. Use -C/--print-config flag to see the full configuration", name)
+		}
+	}
+	for _, name := range opts.MatchPatterns {
+		if !slices.ContainsFunc(patterns, func(p pattern) bool { return p.Name == name }) {
+			return fmt.Errorf("pattern %q is not defined. Use -C/--print-config flag to see the full configuration", name)
+		}
+	}
+	for _, name := range opts.MatchWords {
+		if !words.has(name) {
+			return fmt.Errorf("word group %q is not defined. Use -C/--print-config flag to see the full configuration", name)
+		}
+	}
+
+	return nil
+}
+
+func containsAny(wanted, found []string) bool {
+	for _, name := range found {
+		if slices.Contains(wanted, name) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,133 @@
+package highlighter
+
+import (
+	"testing"
+
+	"github.com/deponian/logalize/internal/config"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMatchColorizeMatch(t *testing.T) {
+	tests := []struct {
+		plain   string
+		colored string
+		match   Match
+	}{
+		{
+			"GET 200 success",
+			"\x1b[38;2;0;0;255mGET \x1b[0m\x1b[38;2;255;255;0m200\x1b[0m \x1b[38;2;81;250;138msuccess\x1b[0m",
+			Match{Format: "access", Patterns: []string{"number"}, WordGroups: []string{"good"}},
+		},
+		{
+			`toni said "fail" 3 times, not 4, fail`,
+			"\x1b[38;2;248;52;178mtoni\x1b[0m said \x1b[38;2;0;255;0m\"fail\"\x1b[0m \x1b[38;2;255;255;0m3\x1b[0m times, not \x1b[38;2;255;255;0m4\x1b[0m, \x1b[38;2;240;108;97mfail\x1b[0m",
+			Match{Patterns: []string{"string", "number"}, WordGroups: []string{"friends", "bad"}, Level: LevelError},
+		},
+		// negated words belong to the opposite group
+		{
+			"not success",
+			"\x1b[38;2;240;108;97mnot success\x1b[0m",
+			Match{WordGroups: []string{"bad"}, Level: LevelError},
+		},
+		{
+			"nothing here",
+			"nothing here",
+			Match{},
+		},
+	}
+
+	hl, err := newTestHighlighter(t, "./testdata/match/ColorizeMatch/01_main.yaml", config.Options{})
+	if err != nil {
+		t.Fatalf("NewHighlighter() failed with this error: %s", err)
+	}
+
+	for _, tt := range tests {
+		t.Run("TestMatchColorizeMatch"+tt.plain, func(t *testing.T) {
+			colored, match := hl.ColorizeMatch(tt.plain, Record{})
+			if colored != tt.colored {
+				t.Errorf("got %q, want %q", colored, tt.colored)
+			}
+			if !cmp.Equal(match, tt.match) {
+				t.Errorf("got %v, want %v", match, tt.match)
+			}
+		})
+	}
+}
+
+func TestMatchColorizeMatchDryRun(t *testing.T) {
+	hl, err := newTestHighlighter(t, "./testdata/match/ColorizeMatch/01_main.yaml", config.Options{DryRun: true})
+	if err != nil {
+		t.Fatalf("NewHighlighter() failed with this error: %s", err)
+	}
+
+	line := "GET 200 success"
+	colored, match := hl.ColorizeMatch(line, Record{})
+	if colored != line {
+		t.Errorf("got %q, want %q", colored, line)
+	}
+	correctMatch := Match{Format: "access", Patterns: []string{"number"}, WordGroups: []string{"good"}}
+	if !cmp.Equal(match, correctMatch) {
+		t.Errorf("got %v, want %v", match, correctMatch)
+	}
+}
+
+func TestMatchWanted(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   config.Options
+		match  Match
+		wanted bool
+	}{
+		{"no filters", config.Options{}, Match{}, true},
+		{"format", config.Options{OnlyMatchingFormats: []string{"access"}}, Match{Format: "access"}, true},
+		{"no format", config.Options{OnlyMatchingFormats: []string{"access"}}, Match{Patterns: []string{"number"}}, false},
+		{"one of patterns", config.Options{MatchPatterns: []string{"string", "number"}}, Match{Patterns: []string{"number"}}, true},
+		{"no pattern", config.Options{MatchPatterns: []string{"string"}}, Match{Patterns: []string{"number"}}, false},
+		{"words", config.Options{MatchWords: []string{"bad"}}, Match{WordGroups: []string{"friends", "bad"}}, true},
+		{"no words", config.Options{MatchWords: []string{"bad"}}, Match{WordGroups: []string{"good"}}, false},
+		{
+			"all filters",
+			config.Options{OnlyMatchingFormats: []string{"access"}, MatchWords: []string{"good"}},
+			Match{Format: "access", WordGroups: []string{"good"}},
+			true,
+		},
+		{
+			"one of filters",
+			config.Options{OnlyMatchingFormats: []string{"access"}, MatchWords: []string{"good"}},
+			Match{WordGroups: []string{"good"}},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run("TestMatchWanted "+tt.name, func(t *testing.T) {
+			hl, err := newTestHighlighter(t, "./testdata/match/ColorizeMatch/01_main.yaml", tt.opts)
+			if err != nil {
+				t.Fatalf("NewHighlighter() failed with this error: %s", err)
+			}
+
+			filtering := len(tt.opts.OnlyMatchingFormats)+len(tt.opts.MatchPatterns)+len(tt.opts.MatchWords) > 0
+			if hl.Filtering() != filtering {
+				t.Errorf("Filtering() returned %t, want %t", hl.Filtering(), filtering)
+			}
+			if wanted := hl.Wanted(tt.match); wanted != tt.wanted {
+				t.Errorf("Wanted(%v) returned %t, want %t", tt.match, wanted, tt.wanted)
+			}
+		})
+	}
+}
+
+func TestMatchUnknownNames(t *testing.T) {
+	tests := []config.Options{
+		{OnlyMatchingFormats: []string{"error"}},
+		{MatchPatterns: []string{"error"}},
+		{MatchWords: []string{"error"}},
+		{MatchWords: []string{""}},
+	}
+
+	for _, opts := range tests {
+		if _, err := newTestHighlighter(t, "./testdata/match/ColorizeMatch/01_main.yaml", opts); err == nil {
+			t.Errorf("NewHighlighter() should have failed with %v", opts)
+		}
+	}
+}
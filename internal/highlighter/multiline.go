@@ -0,0 +1,213 @@
+package highlighter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/knadh/koanf/v2"
+)
+
+// continuation represents one kind of lines that continue
+// a multiline record (e.g. "at ..." lines of a Java stack trace)
+type continuation struct {
+	Name      string
+	CapGroups *capGroupList
+	// Last ends the record after the line matched this continuation
+	Last bool
+}
+
+// collectMultiline reads continuation rules from "formats.<name>.multiline"
+func collectMultiline(config *koanf.Koanf, name string) ([]continuation, error) {
+	var rules []struct {
+		Name    string     `koanf:"name"`
+		RegExps []capGroup `koanf:"regexps"`
+		Last    bool       `koanf:"last"`
+	}
+	if err := config.Unmarshal("formats."+name+".multiline", &rules); err != nil {
+		return nil, err
+	}
+
+	multiline := make([]continuation, 0, len(rules))
+	for _, rule := range rules {
+		multiline = append(multiline, continuation{
+			Name:      rule.Name,
+			CapGroups: &capGroupList{groups: rule.RegExps},
+			Last:      rule.Last,
+		})
+	}
+
+	return multiline, nil
+}
+
+// initMultiline sets colors and styles of continuation rules
+// from the theme and compiles their regular expressions
+func initMultiline(lf *format, config *koanf.Koanf, theme string) error {
+	// continuation rules share the namespace in the theme
+	// with the capturing groups of the first line
+	seen := make(map[string]bool, len(lf.CapGroups.groups)+len(lf.Multiline))
+	for _, cg := range lf.CapGroups.groups {
+		seen[cg.Name] = true
+	}
+
+	for i, rule := range lf.Multiline {
+		if rule.Name == "" {
+			return fmt.Errorf("continuation rule can't have empty \"name\" field")
+		}
+		if keywordRegExp.MatchString(rule.Name) {
+			return fmt.Errorf(
+				"[continuation: %s] continuation rule cannot be named \"fg\", \"bg\", \"style\", or \"link-to\"",
+				rule.Name)
+		}
+		if seen[rule.Name] {
+			return fmt.Errorf(
+				"[continuation: %s] continuation rule names must differ from each other and from capturing group names",
+				rule.Name)
+		}
+		seen[rule.Name] = true
+
+		if len(rule.CapGroups.groups) == 0 {
+			return fmt.Errorf("[continuation: %s] continuation rule must have at least one regexp", rule.Name)
+		}
+
+		setCapGroupStyles(lf.Multiline[i].CapGroups, config, "themes."+theme+".formats."+lf.Name+"."+rule.Name)
+		if err := lf.Multiline[i].CapGroups.init(true); err != nil {
+			return fmt.Errorf("[continuation: %s] %s", rule.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// Record tells how to colorize a line that belongs to a multiline record.
+// The zero value means that the line isn't a part of any record.
+type Record struct {
+	format *format
+	// rule is nil for the first line of the record
+	rule *continuation
+	// level of the first line is the level of the whole record
+	level Level
+	// time of the line or of the last line with a timestamp before it
+	// (it's set only if user set --since or --until flags)
+	time time.Time
+	// detected is true if the format of the line outside of records was
+	// already detected to find its time, then its index is in detectedFormat
+	detected       bool
+	detectedFormat int
+}
+
+// RecordTracker follows multiline records (stack traces, tracebacks,
+// panics, etc.) in one stream of lines. A record starts with a line
+// that matches a multiline format and lasts while the following lines
+// match one of the continuation rules of the format.
+//
+// The tracker must see every line of the stream in the original order.
+// The records it returns can be colorized in any order (see ColorizeRecord).
+type RecordTracker struct {
+	h Highlighter
+	// formats that have continuation rules
+	multiline []*format
+	// format and level of the current record
+	current *format
+	level   Level
+	// time of the last line with a timestamp
+	time time.Time
+}
+
+// NewRecordTracker creates a tracker of multiline records
+// for one stream of lines
+func (h Highlighter) NewRecordTracker() *RecordTracker {
+	rt := &RecordTracker{h: h}
+	for i := range h.formats {
+		if h.formats[i].Multiline != nil {
+			rt.multiline = append(rt.multiline, &h.formats[i])
+		}
+	}
+
+	return rt
+}
+
+// Track returns the record the next line of the stream belongs to.
+// Lines without a timestamp get the time of the last line that had one,
+// so all lines of a record are shown or hidden together by time filters.
+func (rt *RecordTracker) Track(line string) Record {
+	// records are tracked even with --dry-run flag
+	// because they are used by filters (see ColorizeMatch)
+	if len(rt.multiline) == 0 && rt.h.timeRange == nil {
+		return Record{}
+	}
+
+	if !rt.h.settings.Opts.NoANSIEscapeSequencesStripping && strings.Contains(line, "\x1b") {
+		line = allANSIEscapeSequencesRegExp.ReplaceAllString(line, "")
+	}
+
+	record := rt.track(line)
+	if rt.h.timeRange != nil {
+		// the format of the line is detected only once,
+		// colorizing the line uses it too (see detectFormat)
+		var lf *format
+		switch {
+		case record.format == nil:
+			record.detected = true
+			record.detectedFormat = rt.h.detector.detect(line, rt.h.formats)
+			if record.detectedFormat >= 0 {
+				lf = &rt.h.formats[record.detectedFormat]
+			}
+		case record.rule == nil:
+			// the first line of a record
+			lf = record.format
+		}
+		if t, ok := rt.h.lineTime(line, lf); ok {
+			rt.time = t
+		}
+		record.time = rt.time
+	}
+
+	return record
+}
+
+// track returns the multiline record of the line
+func (rt *RecordTracker) track(line string) Record {
+	// continue the current record
+	if rt.current != nil {
+		for i := range rt.current.Multiline {
+			rule := &rt.current.Multiline[i]
+			if rule.CapGroups.fullRegExp.MatchString(line) {
+				record := Record{format: rt.current, rule: rule, level: rt.level}
+				if rule.Last {
+					rt.current = nil
+				}
+
+				return record
+			}
+		}
+	}
+
+	// or start a new one
+	rt.current = nil
+	for _, lf := range rt.multiline {
+		if lf.CapGroups.fullRegExp.MatchString(line) {
+			rt.current = lf
+			rt.level = lf.level(line)
+
+			return Record{format: lf, level: rt.level}
+		}
+	}
+
+	return Record{}
+}
+
+// highlightRecordLine colorizes a line of a multiline record of the format
+func (lf format) highlightRecordLine(str string, rule *continuation, h Highlighter) []span {
+	if rule == nil {
+		return lf.highlight(str, h)
+	}
+
+	spans := claim(rule.CapGroups.highlight(str, h), "format", lf.Name)
+	if h.settings.Opts.Debug {
+		spans = h.addDebugInfo(spans, lf)
+	}
+	h.addMatch(lf)
+
+	return spans
+}
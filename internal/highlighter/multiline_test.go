@@ -0,0 +1,276 @@
+package highlighter
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/deponian/logalize/internal/config"
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/v2"
+	"github.com/muesli/termenv"
+)
+
+func TestMultilineNewGood(t *testing.T) {
+	correctFormat := format{
+		"test", 0, &capGroupList{
+			[]capGroup{
+				{"prefix", `(Error:\s)`, "#ff0000", "", "bold", "", nil, numRange{}, gradient{}, nil, false, nil},
+				{"message", `(.*)`, "", "", "patterns-and-words", "", nil, numRange{}, gradient{}, nil, false, nil},
+			},
+			regexp.MustCompile(`^(?P<capGroup0>(?:Error:\s))(?P<capGroup1>(?:.*))$`),
+			map[string]int{"prefix": 0, "message": 1},
+		},
+		nil,
+		[]continuation{
+			{
+				"frame", &capGroupList{
+					[]capGroup{
+						{"at", `(  at )`, "#505050", "", "", "", nil, numRange{}, gradient{}, nil, false, nil},
+						{"function", `(.+)`, "#0000ff", "", "", "", nil, numRange{}, gradient{}, nil, false, nil},
+					},
+					regexp.MustCompile(`^(?P<capGroup0>(?:  at ))(?P<capGroup1>(?:.+))$`),
+					map[string]int{"at": 0, "function": 1},
+				},
+				false,
+			},
+			{
+				"end", &capGroupList{
+					[]capGroup{
+						{"text", `(\(end\))`, "", "", "faint", "", nil, numRange{}, gradient{}, nil, false, nil},
+					},
+					regexp.MustCompile(`^(?P<capGroup0>(?:\(end\)))$`),
+					map[string]int{"text": 0},
+				},
+				true,
+			},
+		},
+		nil,
+	}
+
+	cfg := koanf.New(".")
+	err := cfg.Load(file.Provider("./testdata/multiline/newMultiline/01_good.yaml"), yaml.Parser())
+	if err != nil {
+		t.Fatalf("cfg.Load(...) failed with this error: %s", err)
+	}
+
+	t.Run("TestMultilineNewGood", func(t *testing.T) {
+		formats, err := newFormats(cfg, "test")
+		if err != nil {
+			t.Fatalf("newFormats() failed with this error: %s", err)
+		}
+
+		if err := compareFormats(formats[0], correctFormat); err != nil {
+			t.Errorf("%s", err)
+		}
+	})
+}
+
+func TestMultilineNewBad(t *testing.T) {
+	tests := []string{
+		"./testdata/multiline/newMultiline/02_bad_yaml.yaml",
+		"./testdata/multiline/newMultiline/03_bad_regexp.yaml",
+		"./testdata/multiline/newMultiline/04_empty_name.yaml",
+		"./testdata/multiline/newMultiline/05_duplicate_name.yaml",
+		"./testdata/multiline/newMultiline/06_keyword_name.yaml",
+		"./testdata/multiline/newMultiline/07_no_regexps.yaml",
+		"./testdata/multiline/newMultiline/08_bad_color.yaml",
+	}
+
+	for _, tt := range tests {
+		cfg := koanf.New(".")
+		err := cfg.Load(file.Provider(tt), yaml.Parser())
+		if err != nil {
+			t.Fatalf("cfg.Load(...) failed with this error: %s", err)
+		}
+
+		t.Run("TestMultilineNewBad"+tt, func(t *testing.T) {
+			if _, err := newFormats(cfg, "test"); err == nil {
+				t.Errorf("newFormats() should have failed")
+			}
+		})
+	}
+}
+
+func TestRecordTracker(t *testing.T) {
+	tests := []struct {
+		plain   string
+		rule    string // "" means the line isn't a part of a record
+		colored string
+	}{
+		{
+			"  at main()",
+			"",
+			"  at main()",
+		},
+		{
+			"Error: got 5 errors",
+			"start",
+			"\x1b[38;2;255;0;0;1mError: \x1b[0mgot \x1b[38;2;255;255;0m5\x1b[0m errors",
+		},
+		{
+			"  at main()",
+			"frame",
+			"\x1b[38;2;80;80;80m  at \x1b[0m\x1b[38;2;0;0;255mmain()\x1b[0m",
+		},
+		{
+			"  at \x1b[31mrun(7)\x1b[0m",
+			"frame",
+			"\x1b[38;2;80;80;80m  at \x1b[0m\x1b[38;2;0;0;255mrun(7)\x1b[0m",
+		},
+		{
+			"(end)",
+			"end",
+			"\x1b[2m(end)\x1b[0m",
+		},
+		// the record ended with the "last" rule
+		{
+			"  at main()",
+			"",
+			"  at main()",
+		},
+		{
+			"Error: 1",
+			"start",
+			"\x1b[38;2;255;0;0;1mError: \x1b[0m\x1b[38;2;255;255;0m1\x1b[0m",
+		},
+		// a new record starts right after the previous one
+		{
+			"Error: 2",
+			"start",
+			"\x1b[38;2;255;0;0;1mError: \x1b[0m\x1b[38;2;255;255;0m2\x1b[0m",
+		},
+		// a line that doesn't match any rule ends the record
+		{
+			"plain line 3",
+			"",
+			"plain line \x1b[38;2;255;255;0m3\x1b[0m",
+		},
+		{
+			"  at main()",
+			"",
+			"  at main()",
+		},
+	}
+
+	cfg := koanf.New(".")
+	err := cfg.Load(file.Provider("./testdata/multiline/RecordTracker/01_main.yaml"), yaml.Parser())
+	if err != nil {
+		t.Fatalf("cfg.Load(...) failed with this error: %s", err)
+	}
+
+	settings := config.Settings{
+		Config:       cfg,
+		Opts:         config.Options{Theme: "test"},
+		ColorProfile: termenv.TrueColor,
+	}
+
+	hl, err := NewHighlighter(settings)
+	if err != nil {
+		t.Fatalf("NewHighlighter() failed with this error: %s", err)
+	}
+
+	tracker := hl.NewRecordTracker()
+	for _, tt := range tests {
+		record := tracker.Track(tt.plain)
+
+		var rule string
+		switch {
+		case record.format == nil:
+		case record.rule == nil:
+			rule = "start"
+		default:
+			rule = record.rule.Name
+		}
+		if rule != tt.rule {
+			t.Errorf("Track(%q) returned rule %q, want %q", tt.plain, rule, tt.rule)
+		}
+
+		if colored := hl.ColorizeRecord(tt.plain, record); colored != tt.colored {
+			t.Errorf("got %q, want %q", colored, tt.colored)
+		}
+	}
+}
+
+func TestRecordTrackerDryRun(t *testing.T) {
+	cfg := koanf.New(".")
+	err := cfg.Load(file.Provider("./testdata/multiline/RecordTracker/01_main.yaml"), yaml.Parser())
+	if err != nil {
+		t.Fatalf("cfg.Load(...) failed with this error: %s", err)
+	}
+
+	settings := config.Settings{
+		Config:       cfg,
+		Opts:         config.Options{Theme: "test", DryRun: true},
+		ColorProfile: termenv.TrueColor,
+	}
+
+	hl, err := NewHighlighter(settings)
+	if err != nil {
+		t.Fatalf("NewHighlighter() failed with this error: %s", err)
+	}
+
+	tracker := hl.NewRecordTracker()
+	for _, line := range []string{"Error: 1", "  at main()"} {
+		if colored := hl.ColorizeRecord(line, tracker.Track(line)); colored != line {
+			t.Errorf("got %q, want %q", colored, line)
+		}
+	}
+}
+
+func TestMultilineBuiltins(t *testing.T) {
+	tests := []struct {
+		plain   string
+		colored string
+	}{
+		// java-stack-trace
+		{`Exception in thread "main" java.lang.IllegalStateException: boom`, "\x1b[38;2;99;109;166mException in thread \"main\" \x1b[0m\x1b[38;2;255;117;127;1mjava.lang.IllegalStateException\x1b[0m: boom"},
+		{"\tat com.example.App.run(App.java:42)", "\x1b[38;2;99;109;166m\tat \x1b[0m\x1b[38;2;154;173;236mcom.example.App.run\x1b[0m\x1b[38;2;192;153;255m(App.java:42)\x1b[0m"},
+		{"\tat java.base/java.lang.Thread.run(Thread.java:833)", "\x1b[38;2;99;109;166m\tat \x1b[0m\x1b[38;2;154;173;236mjava.base/java.lang.Thread.run\x1b[0m\x1b[38;2;192;153;255m(Thread.java:833)\x1b[0m"},
+		{`Caused by: java.io.IOException: disk full`, "\x1b[38;2;255;199;119;1mCaused by: \x1b[0m\x1b[38;2;255;117;127;1mjava.io.IOException\x1b[0m: disk full"},
+		{"\t... 5 more", "\x1b[38;2;99;109;166m\t... 5 more\x1b[0m"},
+		{"\tSuppressed: java.lang.RuntimeException", "\x1b[38;2;255;199;119;1m\tSuppressed: \x1b[0m\x1b[38;2;255;117;127;1mjava.lang.RuntimeException\x1b[0m"},
+		{"\t\tat com.example.Store.close(Store.java:7)", "\x1b[38;2;99;109;166m\t\tat \x1b[0m\x1b[38;2;154;173;236mcom.example.Store.close\x1b[0m\x1b[38;2;192;153;255m(Store.java:7)\x1b[0m"},
+
+		// python-traceback
+		{`Traceback (most recent call last):`, "\x1b[38;2;255;117;127;1mTraceback (most recent call last):\x1b[0m"},
+		{`  File "/app/main.py", line 10, in <module>`, "\x1b[38;2;99;109;166m  File \x1b[0m\x1b[38;2;195;232;141m\"/app/main.py\"\x1b[0m\x1b[38;2;99;109;166m, line \x1b[0m\x1b[38;2;255;150;108m10\x1b[0m\x1b[38;2;154;173;236m, in <module>\x1b[0m"},
+		{`    main()`, "    main()"},
+		{`  File "<string>", line 1`, "\x1b[38;2;99;109;166m  File \x1b[0m\x1b[38;2;195;232;141m\"<string>\"\x1b[0m\x1b[38;2;99;109;166m, line \x1b[0m\x1b[38;2;255;150;108m1\x1b[0m\x1b[38;2;154;173;236m\x1b[0m"},
+		{`ValueError: invalid literal`, "\x1b[38;2;255;117;127;1mValueError\x1b[0m: invalid literal"},
+
+		// go-panic
+		{`goroutine 1 [running]:`, "\x1b[38;2;99;109;166mgoroutine \x1b[0m\x1b[38;2;255;150;108m1\x1b[0m\x1b[38;2;192;153;255m [running]:\x1b[0m"},
+		{`main.(*Server).handle(0xc000010000, {0x1, 0x2})`, "\x1b[38;2;154;173;236mmain.(*Server).handle\x1b[0m\x1b[38;2;99;109;166m(0xc000010000, {0x1, 0x2})\x1b[0m"},
+		{"\t/app/server.go:42 +0x1d", "\x1b[38;2;195;232;141m\t/app/server.go\x1b[0m\x1b[38;2;255;150;108m:42\x1b[0m\x1b[38;2;99;109;166m +0x1d\x1b[0m"},
+		{`created by main.main in goroutine 1`, "\x1b[38;2;99;109;166mcreated by \x1b[0m\x1b[38;2;154;173;236mmain.main\x1b[0m\x1b[38;2;99;109;166m in goroutine 1\x1b[0m"},
+		{"\t/app/main.go:12", "\x1b[38;2;195;232;141m\t/app/main.go\x1b[0m\x1b[38;2;255;150;108m:12\x1b[0m\x1b[38;2;99;109;166m\x1b[0m"},
+	}
+
+	cfg := koanf.New(".")
+	err := cfg.Load(file.Provider("./testdata/formats/builtins/theme.yaml"), yaml.Parser())
+	if err != nil {
+		t.Fatalf("cfg.Load(...) failed with this error: %s", err)
+	}
+
+	settings, err := config.NewSettings(builtins, cfg, nil, true)
+	if err != nil {
+		t.Fatalf("config.NewSettings(...) failed with this error: %s", err)
+	}
+	settings.ColorProfile = termenv.TrueColor
+
+	hl, err := NewHighlighter(settings)
+	if err != nil {
+		t.Fatalf("NewHighlighter() failed with this error: %s", err)
+	}
+
+	tracker := hl.NewRecordTracker()
+	for _, tt := range tests {
+		t.Run("TestMultilineBuiltins"+tt.plain, func(t *testing.T) {
+			colored := hl.ColorizeRecord(tt.plain, tracker.Track(tt.plain))
+			if colored != tt.colored {
+				t.Errorf("got %q, want %q", colored, tt.colored)
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package highlighter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// dimension is a kind of quantity a number with a unit represents
+type dimension int
+
+const (
+	dimensionless dimension = iota
+	duration
+	size
+)
+
+// unit is a multiplier that converts a value to seconds or bytes
+type unit struct {
+	dimension dimension
+	factor    float64
+}
+
+// units are the suffixes of durations and byte sizes.
+// Decimal sizes (KB, MB, ...) are powers of 1000,
+// binary ones (KiB, MiB, ...) are powers of 1024.
+var units = map[string]unit{
+	"ns":  {duration, 1e-9},
+	"us":  {duration, 1e-6},
+	"µs":  {duration, 1e-6},
+	"ms":  {duration, 1e-3},
+	"s":   {duration, 1},
+	"m":   {duration, 60},
+	"min": {duration, 60},
+	"h":   {duration, 3600},
+	"d":   {duration, 86400},
+
+	"B":   {size, 1},
+	"K":   {size, 1e3},
+	"KB":  {size, 1e3},
+	"kB":  {size, 1e3},
+	"M":   {size, 1e6},
+	"MB":  {size, 1e6},
+	"G":   {size, 1e9},
+	"GB":  {size, 1e9},
+	"T":   {size, 1e12},
+	"TB":  {size, 1e12},
+	"KiB": {size, 1 << 10},
+	"MiB": {size, 1 << 20},
+	"GiB": {size, 1 << 30},
+	"TiB": {size, 1 << 40},
+}
+
+// quantity is a number with an optional unit
+type quantity struct {
+	value     float64
+	dimension dimension
+}
+
+// numRange is a numeric condition of an alternative. The alternative is
+// chosen if the first number in the string is greater than Gt, less than Lt,
+// or lies between both values of Between (inclusive).
+//
+// Values can have units of durations (ns, us, ms, s, m, h, d) or byte sizes
+// (B, KB, MB, GB, TB, KiB, MiB, GiB, TiB). A number without a unit in
+// the string is taken in seconds or bytes if the condition has a unit.
+type numRange struct {
+	Gt      string   `koanf:"gt"`
+	Lt      string   `koanf:"lt"`
+	Between []string `koanf:"between"`
+
+	min, max       quantity
+	hasMin, hasMax bool
+	inclusive      bool
+}
+
+// isSet reports whether the range has any condition
+func (nr numRange) isSet() bool {
+	return nr.Gt != "" || nr.Lt != "" || nr.Between != nil
+}
+
+// init parses the bounds of the range
+func (nr *numRange) init() error {
+	if !nr.isSet() {
+		return nil
+	}
+
+	lower, upper := nr.Gt, nr.Lt
+	if nr.Between != nil {
+		if nr.Gt != "" || nr.Lt != "" {
+			return fmt.Errorf("\"between\" can't be used with \"gt\" or \"lt\"")
+		}
+		if len(nr.Between) != 2 {
+			return fmt.Errorf("\"between\" must have exactly two values")
+		}
+		lower, upper = nr.Between[0], nr.Between[1]
+		nr.inclusive = true
+	}
+
+	var err error
+	if lower != "" {
+		if nr.min, err = parseQuantity(lower); err != nil {
+			return err
+		}
+		nr.hasMin = true
+	}
+	if upper != "" {
+		if nr.max, err = parseQuantity(upper); err != nil {
+			return err
+		}
+		nr.hasMax = true
+	}
+
+	if nr.hasMin && nr.hasMax {
+		if nr.min.dimension != nr.max.dimension {
+			return fmt.Errorf("bounds %q and %q have units of different kinds", lower, upper)
+		}
+		if nr.min.value > nr.max.value {
+			return fmt.Errorf("lower bound %q is greater than upper bound %q", lower, upper)
+		}
+	}
+
+	return nil
+}
+
+// match reports whether the first number in the string is in the range.
+// rest is the text right after the string (e.g. the next capturing groups),
+// the unit of the number may be there.
+func (nr numRange) match(str, rest string) bool {
+	q, ok := firstQuantity(str, rest, nr.dimension())
+	if !ok {
+		return false
+	}
+
+	if nr.hasMin && (q.value < nr.min.value || !nr.inclusive && q.value == nr.min.value) {
+		return false
+	}
+	if nr.hasMax && (q.value > nr.max.value || !nr.inclusive && q.value == nr.max.value) {
+		return false
+	}
+
+	return true
+}
+
+func (nr numRange) dimension() dimension {
+	if nr.hasMin {
+		return nr.min.dimension
+	}
+
+	return nr.max.dimension
+}
+
+var (
+	// a number with an optional unit right after it (e.g. "1.5s" or "200 ms")
+	quantityRegExp = regexp.MustCompile(`([-+]?\d+(?:\.\d+)?)\s?([A-Za-zµ]*)`)
+	// the same, but the whole string is the quantity
+	boundRegExp = regexp.MustCompile(`^\s*([-+]?\d+(?:\.\d+)?)\s?([A-Za-zµ]*)\s*$`)
+	// the next part of a compound duration (e.g. "59s" of "1m59s")
+	durationPartRegExp = regexp.MustCompile(`^(\d+(?:\.\d+)?)([A-Za-zµ]+)`)
+	// a unit at the beginning of the text after the number
+	unitRegExp = regexp.MustCompile(`^\s?([A-Za-zµ]+)`)
+)
+
+// firstQuantity returns the first number in the string with its unit.
+// If the string ends with the number, its unit is looked for at
+// the beginning of rest. Numbers without units are taken in seconds
+// or bytes (the dimension from the third argument). Parts of a compound
+// duration like "1m59s" are added up. It returns false if there is
+// no number or its unit is of another kind.
+func firstQuantity(str, rest string, dim dimension) (quantity, bool) {
+	m := quantityRegExp.FindStringSubmatchIndex(str)
+	if m == nil {
+		return quantity{}, false
+	}
+	suffix, after := str[m[4]:m[5]], str[m[1]:]
+	if after == "" {
+		after = rest
+		if u := unitRegExp.FindStringSubmatchIndex(after); suffix == "" && u != nil {
+			suffix, after = after[u[2]:u[3]], after[u[1]:]
+		}
+	}
+	// words after the number aren't units (e.g. "5 items")
+	if _, ok := units[suffix]; !ok {
+		suffix = ""
+	}
+	q, err := newQuantity(str[m[2]:m[3]], suffix)
+	if err != nil {
+		return quantity{}, false
+	}
+	if suffix != "" {
+		q, _ = addDurationParts(q, after)
+	}
+
+	if q.dimension == dimensionless {
+		q.dimension = dim
+	}
+
+	return q, q.dimension == dim
+}
+
+// parseQuantity parses a bound of a range like "200ms", "1m30s" or "1MiB"
+func parseQuantity(str string) (quantity, error) {
+	m := boundRegExp.FindStringSubmatch(str)
+	if m != nil {
+		return newQuantity(m[1], m[2])
+	}
+
+	// compound durations
+	trimmed := strings.TrimSpace(str)
+	if first := durationPartRegExp.FindStringSubmatch(trimmed); first != nil {
+		q, err := newQuantity(first[1], first[2])
+		if err == nil && q.dimension == duration {
+			if q, rest := addDurationParts(q, trimmed[len(first[0]):]); rest == "" {
+				return q, nil
+			}
+		}
+	}
+
+	return quantity{}, fmt.Errorf("%q is not a number", str)
+}
+
+// addDurationParts adds the parts of a compound duration that follow
+// the duration right away to it and returns the rest of the string
+func addDurationParts(q quantity, rest string) (quantity, string) {
+	if q.dimension != duration {
+		return q, rest
+	}
+
+	for {
+		m := durationPartRegExp.FindStringSubmatch(rest)
+		if m == nil {
+			return q, rest
+		}
+		part, err := newQuantity(m[1], m[2])
+		if err != nil || part.dimension != duration {
+			return q, rest
+		}
+		q.value += part.value
+		rest = rest[len(m[0]):]
+	}
+}
+
+func newQuantity(number, suffix string) (quantity, error) {
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return quantity{}, err
+	}
+	if suffix == "" {
+		return quantity{value, dimensionless}, nil
+	}
+
+	u, ok := units[suffix]
+	if !ok {
+		return quantity{}, fmt.Errorf("unknown unit %q", suffix)
+	}
+
+	return quantity{value * u.factor, u.dimension}, nil
+}
@@ -0,0 +1,182 @@
+package highlighter
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/deponian/logalize/internal/config"
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/v2"
+	"github.com/muesli/termenv"
+)
+
+func TestNumericParseQuantity(t *testing.T) {
+	tests := []struct {
+		str string
+		q   quantity
+		err string
+	}{
+		{"42", quantity{42, dimensionless}, "%!s(<nil>)"},
+		{"-1.5", quantity{-1.5, dimensionless}, "%!s(<nil>)"},
+		{"200ms", quantity{0.2, duration}, "%!s(<nil>)"},
+		{"1.5 s", quantity{1.5, duration}, "%!s(<nil>)"},
+		{"2m", quantity{120, duration}, "%!s(<nil>)"},
+		{"1d", quantity{86400, duration}, "%!s(<nil>)"},
+		{"10KB", quantity{10000, size}, "%!s(<nil>)"},
+		{"1MiB", quantity{1 << 20, size}, "%!s(<nil>)"},
+		{" 3 GB ", quantity{3e9, size}, "%!s(<nil>)"},
+		{"1parsec", quantity{}, `unknown unit "parsec"`},
+		{"fast", quantity{}, `"fast" is not a number`},
+		{"1m30s", quantity{90, duration}, "%!s(<nil>)"},
+		{" 1h2m3.5s ", quantity{3723.5, duration}, "%!s(<nil>)"},
+		{"1s2", quantity{}, `"1s2" is not a number`},
+		{"1s2KB", quantity{}, `"1s2KB" is not a number`},
+	}
+
+	for _, tt := range tests {
+		t.Run("TestNumericParseQuantity"+tt.str, func(t *testing.T) {
+			q, err := parseQuantity(tt.str)
+			if e := fmt.Sprintf("%s", err); e != tt.err {
+				t.Errorf("got %s, want %s", e, tt.err)
+			}
+			if q != tt.q {
+				t.Errorf("got %v, want %v", q, tt.q)
+			}
+		})
+	}
+}
+
+func TestNumericRangeInit(t *testing.T) {
+	tests := []struct {
+		name string
+		nr   numRange
+		err  string
+	}{
+		{"empty", numRange{}, "%!s(<nil>)"},
+		{"gt", numRange{Gt: "1s"}, "%!s(<nil>)"},
+		{"gt-lt", numRange{Gt: "1KB", Lt: "1MB"}, "%!s(<nil>)"},
+		{"between", numRange{Between: []string{"100", "200"}}, "%!s(<nil>)"},
+		{"between-gt", numRange{Gt: "1", Between: []string{"100", "200"}}, `"between" can't be used with "gt" or "lt"`},
+		{"between-one", numRange{Between: []string{"100"}}, `"between" must have exactly two values`},
+		{"bad-gt", numRange{Gt: "1x"}, `unknown unit "x"`},
+		{"bad-lt", numRange{Lt: "x"}, `"x" is not a number`},
+		{"dimensions", numRange{Gt: "1s", Lt: "1MB"}, `bounds "1s" and "1MB" have units of different kinds`},
+		{"order", numRange{Between: []string{"2s", "1s"}}, `lower bound "2s" is greater than upper bound "1s"`},
+	}
+
+	for _, tt := range tests {
+		t.Run("TestNumericRangeInit"+tt.name, func(t *testing.T) {
+			if err := fmt.Sprintf("%s", tt.nr.init()); err != tt.err {
+				t.Errorf("got %s, want %s", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestNumericRangeMatch(t *testing.T) {
+	tests := []struct {
+		nr    numRange
+		str   string
+		rest  string
+		match bool
+	}{
+		{numRange{Gt: "1s"}, "1.5s", "", true},
+		{numRange{Gt: "1s"}, "1s", "", false},
+		{numRange{Gt: "1s"}, "999ms", "", false},
+		{numRange{Gt: "1s"}, "2 m", "", true},
+		{numRange{Gt: "1s"}, "0.003 ", "", false},
+		{numRange{Gt: "1s"}, "1.003 ", "", true},
+		{numRange{Gt: "1s"}, "5MB", "", false},
+		{numRange{Gt: "1s"}, "-", "", false},
+		{numRange{Lt: "1KiB"}, "1023", "", true},
+		{numRange{Lt: "1KiB"}, "1KB", "", true},
+		{numRange{Lt: "1KiB"}, "1024B", "", false},
+		{numRange{Lt: "100"}, "took 5 items", "", true},
+		{numRange{Lt: "100"}, "5ms", "", false},
+		{numRange{Between: []string{"200", "299"}}, "200 ", "", true},
+		{numRange{Between: []string{"200", "299"}}, "299", "", true},
+		{numRange{Between: []string{"200", "299"}}, "300", "", false},
+		{numRange{Gt: "100ms", Lt: "1s"}, "[0.5]", "", true},
+		{numRange{Gt: "100ms", Lt: "1s"}, "100ms", "", false},
+		// parts of compound durations are added up
+		{numRange{Gt: "2m"}, "1m59s", "", false},
+		{numRange{Gt: "1m59s"}, "2m", "", true},
+		{numRange{Lt: "2m"}, "1m59s", "", true},
+		{numRange{Between: []string{"2m", "2m"}}, "1m60s", "", true},
+		{numRange{Gt: "1m"}, "1m 59s", "", false},
+		// units and parts of compound durations can be in the next groups
+		{numRange{Gt: "1s"}, "500", "ms", false},
+		{numRange{Gt: "1s"}, "5", "m", true},
+		{numRange{Gt: "1m"}, "1", "m59s", true},
+		{numRange{Gt: "2m"}, "1", "m59s", false},
+		{numRange{Gt: "1m"}, "1m", "1s", true},
+		{numRange{Lt: "100"}, "5", " items", true},
+		{numRange{Gt: "1s"}, "5 ", "m", true},
+		{numRange{Gt: "1s"}, "1s", "2", false},
+	}
+
+	for _, tt := range tests {
+		if err := tt.nr.init(); err != nil {
+			t.Fatalf("init() failed with this error: %s", err)
+		}
+		t.Run(fmt.Sprintf("TestNumericRangeMatch%v%s%s", tt.nr, tt.str, tt.rest), func(t *testing.T) {
+			if match := tt.nr.match(tt.str, tt.rest); match != tt.match {
+				t.Errorf("got %v, want %v", match, tt.match)
+			}
+		})
+	}
+}
+
+func TestNumericHighlight(t *testing.T) {
+	tests := []struct {
+		plain   string
+		colored string
+	}{
+		{"1.5s 512 503", "\x1b[38;2;255;0;0m1.5s \x1b[0m\x1b[2m512 \x1b[0m\x1b[38;2;255;0;255m503\x1b[0m"},
+		{"300ms 2KiB 404", "\x1b[38;2;255;255;0m300ms \x1b[0m2KiB \x1b[38;2;255;0;0m404\x1b[0m"},
+		{"1s 1KiB 200", "\x1b[38;2;255;255;0m1s \x1b[0m1KiB 200"},
+		{"50ms 1000 200", "\x1b[38;2;0;255;0m50ms \x1b[0m\x1b[2m1000 \x1b[0m200"},
+	}
+
+	cfg := koanf.New(".")
+	err := cfg.Load(file.Provider("./testdata/numeric/newFormats/01_good.yaml"), yaml.Parser())
+	if err != nil {
+		t.Fatalf("cfg.Load(...) failed with this error: %s", err)
+	}
+
+	settings := config.Settings{Config: cfg, Opts: config.Options{Theme: "test"}, ColorProfile: termenv.TrueColor}
+	hl, err := NewHighlighter(settings)
+	if err != nil {
+		t.Fatalf("NewHighlighter() failed with this error: %s", err)
+	}
+
+	for _, tt := range tests {
+		t.Run("TestNumericHighlight"+tt.plain, func(t *testing.T) {
+			if colored := hl.Colorize(tt.plain); colored != tt.colored {
+				t.Errorf("got %q, want %q", colored, tt.colored)
+			}
+		})
+	}
+}
+
+func TestNumericNewFormatsBad(t *testing.T) {
+	for _, path := range []string{
+		"./testdata/numeric/newFormats/02_top_level_range.yaml",
+		"./testdata/numeric/newFormats/03_unknown_unit.yaml",
+		"./testdata/numeric/newFormats/04_different_units.yaml",
+		"./testdata/numeric/newFormats/05_no_regexp.yaml",
+	} {
+		cfg := koanf.New(".")
+		err := cfg.Load(file.Provider(path), yaml.Parser())
+		if err != nil {
+			t.Fatalf("cfg.Load(...) failed with this error: %s", err)
+		}
+
+		t.Run("TestNumericNewFormatsBad"+path, func(t *testing.T) {
+			if _, err := newFormats(cfg, "test"); err == nil {
+				t.Errorf("newFormats() should have failed")
+			}
+		})
+	}
+}
@@ -12,6 +12,7 @@ type pattern struct {
 	Name      string
 	Priority  int
 	CapGroups *capGroupList
+	Logfmt    *logfmtPattern
 }
 
 // patternList represents a list of pattern
@@ -50,6 +51,16 @@ func collectPatterns(config *koanf.Koanf) (patternList, error) {
 		var pattern pattern
 		pattern.Name = patternName
 		pattern.Priority = config.Int("patterns." + patternName + ".priority")
+		if config.Exists("patterns." + patternName + ".logfmt") {
+			logfmt, err := collectLogfmtPattern(config, patternName)
+			if err != nil {
+				return nil, err
+			}
+			pattern.Logfmt = logfmt
+			patterns = append(patterns, pattern)
+
+			continue
+		}
 		pattern.CapGroups = &capGroupList{}
 		if config.Exists("patterns." + patternName + ".regexps") {
 			if err := config.Unmarshal("patterns."+patternName+".regexps", &pattern.CapGroups.groups); err != nil {
@@ -60,6 +71,12 @@ func collectPatterns(config *koanf.Koanf) (patternList, error) {
 				return nil, err
 			}
 		}
+		// "redact" of the pattern hides all of its capturing groups
+		if config.Bool("patterns." + patternName + ".redact") {
+			for i := range pattern.CapGroups.groups {
+				pattern.CapGroups.groups[i].Redact = true
+			}
+		}
 		patterns = append(patterns, pattern)
 	}
 
@@ -67,6 +84,15 @@ func collectPatterns(config *koanf.Koanf) (patternList, error) {
 }
 
 func initPattern(p *pattern, config *koanf.Koanf, theme string) error {
+	// logfmt patterns have their own set of elements in the theme
+	if p.Logfmt != nil {
+		if err := p.Logfmt.init(config, "themes."+theme+".patterns."+p.Name); err != nil {
+			return fmt.Errorf("[pattern: %s] %s", p.Name, err)
+		}
+
+		return nil
+	}
+
 	// set colors and style from the theme
 	for i, cg := range p.CapGroups.groups {
 		cgReal := &p.CapGroups.groups[i]
@@ -86,6 +112,7 @@ func initPattern(p *pattern, config *koanf.Koanf, theme string) error {
 		cgReal.Background = config.String(path + ".bg")
 		cgReal.Style = config.String(path + ".style")
 		cgReal.LinkTo = config.String(path + ".link-to")
+		cgReal.Gradient.Colors = config.Strings(path + ".gradient")
 
 		if len(cg.Alternatives) > 0 {
 			for j, alt := range cg.Alternatives {
@@ -107,25 +134,62 @@ func initPattern(p *pattern, config *koanf.Koanf, theme string) error {
 
 // highlight colorizes various patterns like IP address, date, HTTP response code, etc.
 // It doesn't touch already colored parts of the input.
-func (patterns patternList) highlight(str string, h Highlighter) string {
-	return walkNonSGR(str, func(part string) string {
-		if part == "" {
-			return part
-		}
-		for _, pattern := range patterns {
-			matches := pattern.CapGroups.fullRegExp.FindStringSubmatchIndex(part)
-			if matches != nil {
-				leftPart := patterns.highlight(part[0:matches[0]], h)
-				match := pattern.CapGroups.highlight(part[matches[0]:matches[1]], h)
-				rightPart := patterns.highlight(part[matches[1]:], h)
-				if h.settings.Opts.Debug {
-					match = h.addDebugInfo(match, pattern)
-				}
-
-				return leftPart + match + rightPart
+func (patterns patternList) highlight(spans []span, h Highlighter) []span {
+	return walkUncolored(spans, func(part string) []span {
+		return patterns.highlightText(part, h)
+	})
+}
+
+// highlightText colorizes patterns in the text that isn't colored yet
+func (patterns patternList) highlightText(part string, h Highlighter) []span {
+	if part == "" {
+		return nil
+	}
+	for _, pattern := range patterns {
+		matches := pattern.find(part)
+		if matches != nil {
+			leftPart := patterns.highlightText(part[0:matches[0]], h)
+			match := claim(pattern.highlight(part[matches[0]:matches[1]], h), "pattern", pattern.Name)
+			rightPart := patterns.highlightText(part[matches[1]:], h)
+			if h.settings.Opts.Debug {
+				match = h.addDebugInfo(match, pattern)
 			}
+			h.addMatch(pattern)
+
+			return append(append(leftPart, match...), rightPart...)
 		}
+	}
 
-		return part
-	})
+	return plain(part)
+}
+
+// withoutLogfmt returns a copy of the list without logfmt patterns
+func (patterns patternList) withoutLogfmt() patternList {
+	var filtered patternList
+	for _, pattern := range patterns {
+		if pattern.Logfmt == nil {
+			filtered = append(filtered, pattern)
+		}
+	}
+
+	return filtered
+}
+
+// find returns the position of the first match of the pattern
+// in the same form as regexp.FindStringIndex does
+func (p pattern) find(str string) []int {
+	if p.Logfmt != nil {
+		return p.Logfmt.find(str)
+	}
+
+	return p.CapGroups.fullRegExp.FindStringIndex(str)
+}
+
+// highlight colorizes the string matched by find
+func (p pattern) highlight(str string, h Highlighter) []span {
+	if p.Logfmt != nil {
+		return p.Logfmt.highlight(str, h)
+	}
+
+	return p.CapGroups.highlight(str, h)
 }
@@ -21,6 +21,17 @@ func comparePatterns(pattern1, pattern2 pattern) error {
 		return fmt.Errorf("[pattern1: %s, pattern2: %s] names or priorities aren't equal", pattern1.Name, pattern2.Name)
 	}
 
+	if pattern1.Logfmt != nil || pattern2.Logfmt != nil {
+		if pattern1.Logfmt == nil || pattern2.Logfmt == nil {
+			return fmt.Errorf("[pattern1: %s, pattern2: %s] only one of them is a logfmt pattern", pattern1.Name, pattern2.Name)
+		}
+		if err := compareLogfmtPatterns(*pattern1.Logfmt, *pattern2.Logfmt); err != nil {
+			return fmt.Errorf("[pattern1: %s, pattern2: %s] %s", pattern1.Name, pattern2.Name, err)
+		}
+
+		return nil
+	}
+
 	if err := compareCapGroupLists(*pattern1.CapGroups, *pattern2.CapGroups); err != nil {
 		return fmt.Errorf("[pattern1: %s, pattern2: %s] %s", pattern1.Name, pattern2.Name, err)
 	}
@@ -47,33 +58,33 @@ func TestPatternsNewGood(t *testing.T) {
 		{"string", 500, &capGroupList{
 			[]capGroup{
 				{
-					"string", `("[^"]+"|'[^']+')`, "#00ff00", "", "", "", nil, nil,
+					"string", `("[^"]+"|'[^']+')`, "#00ff00", "", "", "", nil, numRange{}, gradient{}, nil, false, nil,
 				},
 			},
 			regexp.MustCompile(`(?P<capGroup0>(?:"[^"]+"|'[^']+'))`),
 			map[string]int{"string": 0},
-		}},
+		}, nil},
 		{"ipv4-address", 0, &capGroupList{
 			[]capGroup{
 				{
-					"one", `(\d\d\d(\.\d\d\d){3})`, "#ffc777", "", "", "", nil, nil,
+					"one", `(\d\d\d(\.\d\d\d){3})`, "#ffc777", "", "", "", nil, numRange{}, gradient{}, nil, false, nil,
 				},
 				{
-					"two", `((:\d{1,5})?)`, "#ff966c", "", "", "", nil, nil,
+					"two", `((:\d{1,5})?)`, "#ff966c", "", "", "", nil, numRange{}, gradient{}, nil, false, nil,
 				},
 			},
 			regexp.MustCompile(`(?P<capGroup0>(?:\d\d\d(\.\d\d\d){3}))(?P<capGroup1>(?:(:\d{1,5})?))`),
 			map[string]int{"one": 0, "two": 1},
-		}},
+		}, nil},
 		{"number", 0, &capGroupList{
 			[]capGroup{
 				{
-					"number", `(\d+)`, "", "#00ffff", "bold", "", nil, nil,
+					"number", `(\d+)`, "", "#00ffff", "bold", "", nil, numRange{}, gradient{}, nil, false, nil,
 				},
 			},
 			regexp.MustCompile(`(?P<capGroup0>(?:\d+))`),
 			map[string]int{"number": 0},
-		}},
+		}, nil},
 	}
 
 	cfg := koanf.New(".")
@@ -192,7 +203,7 @@ func TestPatternsHighlight(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run("TestPatternsHighlight"+tt.plain, func(t *testing.T) {
-			colored := patterns.highlight(tt.plain, hl)
+			colored := hl.renderer.line(patterns.highlight(plain(tt.plain), hl))
 			if colored != tt.colored {
 				t.Errorf("got %v, want %v", colored, tt.colored)
 			}
@@ -268,19 +279,27 @@ func TestPatternsBuiltins(t *testing.T) {
 		{`Sunday 3`, "\x1b[38;2;192;153;255mSunday 3\x1b[0m"},
 
 		// duration
-		{`75.984854ms`, "\x1b[38;2;79;214;190m75.984854\x1b[0m\x1b[38;2;65;166;181mms\x1b[0m"},
-		{`5s`, "\x1b[38;2;79;214;190m5\x1b[0m\x1b[38;2;65;166;181ms\x1b[0m"},
-		{`784m`, "\x1b[38;2;79;214;190m784\x1b[0m\x1b[38;2;65;166;181mm\x1b[0m"},
-		{`7.5h`, "\x1b[38;2;79;214;190m7.5\x1b[0m\x1b[38;2;65;166;181mh\x1b[0m"},
-		{`25d`, "\x1b[38;2;79;214;190m25\x1b[0m\x1b[38;2;65;166;181md\x1b[0m"},
-
-		// logfmt-general
+		{`75.984854ms`, "\x1b[38;2;79;214;190m75.984854\x1b[0m\x1b[38;2;65;166;181mms\x1b[0m\x1b[38;2;79;214;190m\x1b[0m"},
+		{`5s`, "\x1b[38;2;255;199;119m5\x1b[0m\x1b[38;2;65;166;181ms\x1b[0m\x1b[38;2;255;199;119m\x1b[0m"},
+		{`784m`, "\x1b[38;2;255;117;127m784\x1b[0m\x1b[38;2;65;166;181mm\x1b[0m\x1b[38;2;255;117;127m\x1b[0m"},
+		{`7.5h`, "\x1b[38;2;255;117;127m7.5\x1b[0m\x1b[38;2;65;166;181mh\x1b[0m\x1b[38;2;255;117;127m\x1b[0m"},
+		{`25d`, "\x1b[38;2;255;117;127m25\x1b[0m\x1b[38;2;65;166;181md\x1b[0m\x1b[38;2;255;117;127m\x1b[0m"},
+		{`1s`, "\x1b[38;2;79;214;190m1\x1b[0m\x1b[38;2;65;166;181ms\x1b[0m\x1b[38;2;79;214;190m\x1b[0m"},
+		{`1.5s`, "\x1b[38;2;255;199;119m1.5\x1b[0m\x1b[38;2;65;166;181ms\x1b[0m\x1b[38;2;255;199;119m\x1b[0m"},
+		{`1m`, "\x1b[38;2;255;199;119m1\x1b[0m\x1b[38;2;65;166;181mm\x1b[0m\x1b[38;2;255;199;119m\x1b[0m"},
+		{`1m59s`, "\x1b[38;2;255;117;127m1\x1b[0m\x1b[38;2;65;166;181mm\x1b[0m\x1b[38;2;255;117;127m59s\x1b[0m"},
+
+		// logfmt
 		{`key=value`, "\x1b[38;2;154;173;236mkey\x1b[0m\x1b[38;2;99;109;166m=\x1b[0mvalue"},
-		{`key=5s`, "\x1b[38;2;154;173;236mkey\x1b[0m\x1b[38;2;99;109;166m=\x1b[0m\x1b[38;2;79;214;190m5\x1b[0m\x1b[38;2;65;166;181ms\x1b[0m"},
-
-		// logfmt-string
+		{`key=5s`, "\x1b[38;2;154;173;236mkey\x1b[0m\x1b[38;2;99;109;166m=\x1b[0m\x1b[38;2;255;199;119m5\x1b[0m\x1b[38;2;65;166;181ms\x1b[0m\x1b[38;2;255;199;119m\x1b[0m"},
 		{`key="value"`, "\x1b[38;2;154;173;236mkey\x1b[0m\x1b[38;2;99;109;166m=\x1b[0m\x1b[38;2;154;173;236m\"\x1b[0mvalue\x1b[38;2;154;173;236m\"\x1b[0m"},
-		{`key="5s"`, "\x1b[38;2;154;173;236mkey\x1b[0m\x1b[38;2;99;109;166m=\x1b[0m\x1b[38;2;154;173;236m\"\x1b[0m\x1b[38;2;79;214;190m5\x1b[0m\x1b[38;2;65;166;181ms\x1b[0m\x1b[38;2;154;173;236m\"\x1b[0m"},
+		{`key="5s"`, "\x1b[38;2;154;173;236mkey\x1b[0m\x1b[38;2;99;109;166m=\x1b[0m\x1b[38;2;154;173;236m\"\x1b[0m\x1b[38;2;255;199;119m5\x1b[0m\x1b[38;2;65;166;181ms\x1b[0m\x1b[38;2;255;199;119m\x1b[0m\x1b[38;2;154;173;236m\"\x1b[0m"},
+		{`key=`, "\x1b[38;2;154;173;236mkey\x1b[0m\x1b[38;2;99;109;166m=\x1b[0m"},
+		{`query=a=b`, "\x1b[38;2;154;173;236mquery\x1b[0m\x1b[38;2;99;109;166m=\x1b[0ma=b"},
+		{`msg="a \"b\" c"`, "\x1b[38;2;154;173;236mmsg\x1b[0m\x1b[38;2;99;109;166m=\x1b[0m\x1b[38;2;154;173;236m\"\x1b[0ma \\\"b\\\" c\x1b[38;2;154;173;236m\"\x1b[0m"},
+		{`level=error`, "\x1b[38;2;154;173;236mlevel\x1b[0m\x1b[38;2;99;109;166m=\x1b[0m\x1b[38;2;255;117;127;1merror\x1b[0m"},
+		{`level="WARN"`, "\x1b[38;2;154;173;236mlevel\x1b[0m\x1b[38;2;99;109;166m=\x1b[0m\x1b[38;2;154;173;236m\"\x1b[0m\x1b[38;2;255;199;119;1mWARN\x1b[0m\x1b[38;2;154;173;236m\"\x1b[0m"},
+		{`duration=5s`, "\x1b[38;2;154;173;236mduration\x1b[0m\x1b[38;2;99;109;166m=\x1b[0m\x1b[38;2;255;199;119m5\x1b[0m\x1b[38;2;65;166;181ms\x1b[0m\x1b[38;2;255;199;119m\x1b[0m"},
 
 		// ipv4-address
 		{`127.0.0.1`, "\x1b[38;2;118;211;255m127.0.0.1\x1b[0m\x1b[38;2;13;185;215m\x1b[0m"},
@@ -489,7 +508,7 @@ func TestPatternsBuiltins(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run("TestPatternsHighlight"+tt.plain, func(t *testing.T) {
-			colored := patterns.highlight(tt.plain, hl)
+			colored := hl.renderer.line(patterns.highlight(plain(tt.plain), hl))
 			if colored != tt.colored {
 				t.Errorf("got %v, want %v", colored, tt.colored)
 			}
@@ -0,0 +1,79 @@
+package highlighter
+
+import (
+	"fmt"
+
+	"github.com/knadh/koanf/v2"
+)
+
+// prefix is a color and a style of a source prefix
+// (the name of the file a line came from)
+type prefix struct {
+	Foreground string `koanf:"fg"`
+	Background string `koanf:"bg"`
+	Style      string `koanf:"style"`
+}
+
+type prefixList []prefix
+
+// newPrefixes returns list of source prefix colors
+// from the theme in *koanf.Koanf configuration
+func newPrefixes(config *koanf.Koanf, theme string) (prefixList, error) {
+	if config == nil {
+		return prefixList{}, nil
+	}
+
+	var prefixes prefixList
+	if err := config.Unmarshal("themes."+theme+".prefixes", &prefixes); err != nil {
+		return nil, fmt.Errorf("[theme: %s] %s", theme, err)
+	}
+
+	for _, p := range prefixes {
+		if err := p.validate(); err != nil {
+			return nil, fmt.Errorf("[theme: %s] %s", theme, err)
+		}
+	}
+
+	return prefixes, nil
+}
+
+// ColorizePrefix colors the prefix of the source with the given index.
+// Every source gets its own color from the theme's "prefixes" list,
+// the colors are reused if there are more sources than colors.
+func (h Highlighter) ColorizePrefix(str string, index int) string {
+	if h.settings.Opts.DryRun || len(h.prefixes) == 0 {
+		return h.renderer.line(plain(str))
+	}
+
+	p := h.prefixes[index%len(h.prefixes)]
+
+	return h.renderer.line(h.highlight(str, p.Foreground, p.Background, p.Style))
+}
+
+func (p prefix) validate() error {
+	// check foreground
+	if !colorRegExp.MatchString(p.Foreground) {
+		return fmt.Errorf(
+			"[prefix] foreground color %s doesn't match %s pattern",
+			p.Foreground, colorRegExp,
+		)
+	}
+
+	// check background
+	if !colorRegExp.MatchString(p.Background) {
+		return fmt.Errorf(
+			"[prefix] background color %s doesn't match %s pattern",
+			p.Background, colorRegExp,
+		)
+	}
+
+	// check style
+	if !nonRecursiveStyleRegExp.MatchString(p.Style) {
+		return fmt.Errorf(
+			"[prefix] style %s doesn't match %s pattern",
+			p.Style, nonRecursiveStyleRegExp,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package highlighter
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/deponian/logalize/internal/config"
+	"github.com/google/go-cmp/cmp"
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/v2"
+	"github.com/muesli/termenv"
+)
+
+func TestPrefixesNewGood(t *testing.T) {
+	correctPrefixes := prefixList{
+		{"#ff0000", "", ""},
+		{"", "#00ff00", "bold"},
+		{"#0000ff", "#ffffff", "underline"},
+	}
+
+	cfg := koanf.New(".")
+	err := cfg.Load(file.Provider("./testdata/prefixes/newPrefixes/01_good.yaml"), yaml.Parser())
+	if err != nil {
+		t.Fatalf("cfg.Load(...) failed with this error: %s", err)
+	}
+
+	t.Run("TestPrefixesNewGood", func(t *testing.T) {
+		prefixes, err := newPrefixes(cfg, "test")
+		if err != nil {
+			t.Errorf("newPrefixes() failed with this error: %s", err)
+		}
+		if !cmp.Equal(prefixes, correctPrefixes) {
+			t.Errorf("got: %v, want: %v", prefixes, correctPrefixes)
+		}
+	})
+
+	t.Run("TestPrefixesNewNoPrefixes", func(t *testing.T) {
+		prefixes, err := newPrefixes(cfg, "unknown")
+		if err != nil {
+			t.Errorf("newPrefixes() failed with this error: %s", err)
+		}
+		if len(prefixes) != 0 {
+			t.Errorf("prefixes have to be empty, got: %v", prefixes)
+		}
+	})
+}
+
+func TestPrefixesNewBad(t *testing.T) {
+	for _, path := range []string{
+		"./testdata/prefixes/newPrefixes/02_bad_yaml.yaml",
+		"./testdata/prefixes/newPrefixes/03_bad_style.yaml",
+	} {
+		cfg := koanf.New(".")
+		err := cfg.Load(file.Provider(path), yaml.Parser())
+		if err != nil {
+			t.Fatalf("cfg.Load(...) failed with this error: %s", err)
+		}
+
+		t.Run("TestPrefixesNewBad"+path, func(t *testing.T) {
+			if _, err := newPrefixes(cfg, "test"); err == nil {
+				t.Errorf("newPrefixes() should have failed")
+			}
+		})
+
+		t.Run("TestPrefixesNewBadHighlighter"+path, func(t *testing.T) {
+			settings := config.Settings{Config: cfg, Opts: config.Options{Theme: "test"}}
+			if _, err := NewHighlighter(settings); err == nil {
+				t.Errorf("NewHighlighter() should have failed")
+			}
+		})
+	}
+}
+
+func TestPrefixesCheck(t *testing.T) {
+	tests := []struct {
+		err    string
+		prefix prefix
+	}{
+		{
+			"%!s(<nil>)",
+			prefix{"#ff0000", "#00ff00", "bold"},
+		},
+		{
+			fmt.Sprintf(`[prefix] foreground color #ff00xd doesn't match %s pattern`, colorRegExp),
+			prefix{"#ff00xd", "", ""},
+		},
+		{
+			fmt.Sprintf(`[prefix] background color hello doesn't match %s pattern`, colorRegExp),
+			prefix{"", "hello", ""},
+		},
+		{
+			fmt.Sprintf(`[prefix] style words doesn't match %s pattern`, nonRecursiveStyleRegExp),
+			prefix{"", "", "words"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run("TestPrefixesCheck"+tt.err, func(t *testing.T) {
+			if err := fmt.Sprintf("%s", tt.prefix.validate()); err != tt.err {
+				t.Errorf("got %s, want %s", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestPrefixesColorizePrefix(t *testing.T) {
+	tests := []struct {
+		index   int
+		colored string
+	}{
+		{0, "\x1b[38;2;255;0;0mapi.log |\x1b[0m"},
+		{1, "\x1b[48;2;0;255;0;1mapi.log |\x1b[0m"},
+		{2, "\x1b[38;2;0;0;255;48;2;255;255;255;4mapi.log |\x1b[0m"},
+		{3, "\x1b[38;2;255;0;0mapi.log |\x1b[0m"},
+	}
+
+	cfg := koanf.New(".")
+	err := cfg.Load(file.Provider("./testdata/prefixes/newPrefixes/01_good.yaml"), yaml.Parser())
+	if err != nil {
+		t.Fatalf("cfg.Load(...) failed with this error: %s", err)
+	}
+
+	settings := config.Settings{Config: cfg, Opts: config.Options{Theme: "test"}, ColorProfile: termenv.TrueColor}
+	hl, err := NewHighlighter(settings)
+	if err != nil {
+		t.Fatalf("NewHighlighter() failed with this error: %s", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("TestPrefixesColorizePrefix%d", tt.index), func(t *testing.T) {
+			if colored := hl.ColorizePrefix("api.log |", tt.index); colored != tt.colored {
+				t.Errorf("got %q, want %q", colored, tt.colored)
+			}
+		})
+	}
+
+	t.Run("TestPrefixesColorizePrefixDryRun", func(t *testing.T) {
+		settings := settings
+		settings.Opts.DryRun = true
+		hl, err := NewHighlighter(settings)
+		if err != nil {
+			t.Fatalf("NewHighlighter() failed with this error: %s", err)
+		}
+		if colored := hl.ColorizePrefix("api.log |", 0); colored != "api.log |" {
+			t.Errorf("got %q, want %q", colored, "api.log |")
+		}
+	})
+}
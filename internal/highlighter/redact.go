@@ -0,0 +1,186 @@
+package highlighter
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+	"unicode"
+)
+
+// redactCutset is trimmed from the text of a group before redaction,
+// so spaces, quotes and brackets around the value are preserved
+const redactCutset = " \t[]()\"'"
+
+// redactMask replaces values in "mask" mode
+const redactMask = "***"
+
+// redactor replaces the text of capturing groups with "redact" field
+// (see --redact flag). The color of the group doesn't change.
+type redactor struct {
+	// mask hides values completely, otherwise they are replaced with pseudonyms
+	mask bool
+	// key makes pseudonyms different in every run of the program
+	key []byte
+}
+
+// newRedactor returns a redactor for the mode ("mask" or "pseudonym")
+// or nil if the mode is empty
+func newRedactor(mode string) (*redactor, error) {
+	switch mode {
+	case "":
+		return nil, nil
+	case "mask":
+		return &redactor{mask: true}, nil
+	case "pseudonym":
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+
+		return &redactor{key: key}, nil
+	}
+
+	return nil, fmt.Errorf("[redact] mode %q must be \"mask\" or \"pseudonym\"", mode)
+}
+
+// redact hides the text of the group if the group must be redacted
+func (h Highlighter) redact(str string, cg *capGroup) string {
+	if h.redactor == nil || !cg.Redact {
+		return str
+	}
+
+	return h.redactor.redact(str)
+}
+
+// highlightRedacted colorizes the shown (redacted) text of the value.
+// The color of "hash" style is taken from the original value,
+// so equal values still get equal colors after redaction.
+func (h Highlighter) highlightRedacted(value, shown, fg, bg, style string) []span {
+	if style == "hash" && shown != value {
+		if color := h.palette.color(value); color != "" {
+			fg = color
+		}
+		style = ""
+	}
+
+	return h.highlight(shown, fg, bg, style)
+}
+
+// redact replaces the value in the string. Strings without
+// letters and digits (like "-" for empty values) are left as is.
+func (r *redactor) redact(str string) string {
+	value := strings.TrimLeft(str, redactCutset)
+	prefix := str[:len(str)-len(value)]
+	value = strings.TrimRight(value, redactCutset)
+	suffix := str[len(prefix)+len(value):]
+	// the bracket belongs to IPv6 address with a port like [::1]:80
+	if strings.HasSuffix(prefix, "[") && strings.Contains(value, "]:") {
+		prefix, value = prefix[:len(prefix)-1], "["+value
+	}
+
+	if !strings.ContainsFunc(value, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) {
+		return str
+	}
+	if r.mask {
+		return prefix + redactMask + suffix
+	}
+
+	return prefix + r.pseudonym(value) + suffix
+}
+
+// pseudonym returns a fake value that looks like the original one.
+// The same value always gets the same pseudonym during one run.
+// IP addresses are replaced with private ones (ports are kept),
+// MAC addresses with locally administered ones. Other values keep
+// their punctuation, digits are replaced with digits and letters
+// with letters (or hex digits if the value is hexadecimal).
+func (r *redactor) pseudonym(value string) string {
+	if addr, err := netip.ParseAddr(value); err == nil {
+		return r.fakeAddr(addr).String()
+	}
+	if addrPort, err := netip.ParseAddrPort(value); err == nil {
+		return netip.AddrPortFrom(r.fakeAddr(addrPort.Addr()), addrPort.Port()).String()
+	}
+	if mac, err := net.ParseMAC(value); err == nil && len(mac) == 6 && len(value) == 17 {
+		return r.fakeMAC(value)
+	}
+
+	return r.fakeText(value)
+}
+
+// sum returns the keyed hash of the value
+func (r *redactor) sum(value string) []byte {
+	mac := hmac.New(sha256.New, r.key)
+	mac.Write([]byte(value))
+
+	return mac.Sum(nil)
+}
+
+// fakeAddr returns an address from 10.0.0.0/8 or fd00::/8
+func (r *redactor) fakeAddr(addr netip.Addr) netip.Addr {
+	sum := r.sum(addr.String())
+	if addr.Is4() {
+		return netip.AddrFrom4([4]byte{10, sum[0], sum[1], sum[2]})
+	}
+
+	var fake [16]byte
+	fake[0] = 0xfd
+	copy(fake[1:], sum)
+
+	return netip.AddrFrom16(fake)
+}
+
+// fakeMAC returns a locally administered unicast MAC address
+// with the same separator and letter case as the original one
+func (r *redactor) fakeMAC(value string) string {
+	sum := r.sum(strings.ToLower(value))
+	sum[0] = sum[0]&0xfc | 0x02
+
+	parts := make([]string, 6)
+	for i := range parts {
+		parts[i] = fmt.Sprintf("%02x", sum[i])
+	}
+	fake := strings.Join(parts, value[2:3])
+	if strings.ContainsAny(value, "ABCDEF") {
+		fake = strings.ToUpper(fake)
+	}
+
+	return fake
+}
+
+// fakeText replaces every digit and letter of the value keeping its shape
+func (r *redactor) fakeText(value string) string {
+	// hexadecimal values like UUIDs and hashes stay hexadecimal
+	hex := strings.ContainsFunc(value, unicode.IsDigit) && !strings.ContainsFunc(value, func(r rune) bool {
+		return unicode.IsLetter(r) && !strings.ContainsRune("abcdefABCDEF", r)
+	})
+
+	sum := r.sum(value)
+	var fake strings.Builder
+	for i, char := range []rune(value) {
+		// the hash is reused for long values but shifted every round
+		b := int(sum[i%len(sum)]) + i/len(sum)
+		switch {
+		case unicode.IsDigit(char):
+			fake.WriteByte(byte('0' + b%10))
+		case hex && unicode.IsLetter(char):
+			fake.WriteByte("0123456789abcdef"[b%16])
+		case unicode.IsLower(char):
+			fake.WriteByte(byte('a' + b%26))
+		case unicode.IsUpper(char):
+			fake.WriteByte(byte('A' + b%26))
+		default:
+			fake.WriteRune(char)
+		}
+	}
+
+	if hex && strings.ContainsAny(value, "ABCDEF") {
+		return strings.ToUpper(fake.String())
+	}
+
+	return fake.String()
+}
@@ -0,0 +1,200 @@
+package highlighter
+
+import (
+	"fmt"
+	"net/netip"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/deponian/logalize/internal/config"
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/v2"
+)
+
+func TestRedactNewRedactor(t *testing.T) {
+	tests := []struct {
+		mode  string
+		isNil bool
+		mask  bool
+		err   string
+	}{
+		{"", true, false, "%!s(<nil>)"},
+		{"mask", false, true, "%!s(<nil>)"},
+		{"pseudonym", false, false, "%!s(<nil>)"},
+		{"hide", true, false, `[redact] mode "hide" must be "mask" or "pseudonym"`},
+	}
+
+	for _, tt := range tests {
+		t.Run("TestRedactNewRedactor"+tt.mode, func(t *testing.T) {
+			r, err := newRedactor(tt.mode)
+			if e := fmt.Sprintf("%s", err); e != tt.err {
+				t.Errorf("got %s, want %s", e, tt.err)
+			}
+			if (r == nil) != tt.isNil {
+				t.Fatalf("got %v, want nil: %v", r, tt.isNil)
+			}
+			if r != nil && r.mask != tt.mask {
+				t.Errorf("got mask %v, want %v", r.mask, tt.mask)
+			}
+			if r != nil && !r.mask && len(r.key) != 32 {
+				t.Errorf("got key of length %d, want 32", len(r.key))
+			}
+		})
+	}
+}
+
+func TestRedactMask(t *testing.T) {
+	tests := []struct {
+		str      string
+		redacted string
+	}{
+		{"192.168.1.10", "***"},
+		{"192.168.1.10 ", "*** "},
+		{"[fe80::1]:8080 ", "*** "},
+		{"\"bob\"", "\"***\""},
+		{"(550e8400-e29b-41d4-a716-446655440000)", "(***)"},
+		// empty values are left as is
+		{"- ", "- "},
+		{"\"\"", "\"\""},
+	}
+
+	r := &redactor{mask: true}
+	for _, tt := range tests {
+		t.Run("TestRedactMask"+tt.str, func(t *testing.T) {
+			if redacted := r.redact(tt.str); redacted != tt.redacted {
+				t.Errorf("got %q, want %q", redacted, tt.redacted)
+			}
+		})
+	}
+}
+
+func TestRedactPseudonym(t *testing.T) {
+	r := &redactor{key: []byte("test")}
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+	tests := []struct {
+		str   string
+		check func(string) bool
+	}{
+		{"192.168.1.10", func(s string) bool {
+			addr, err := netip.ParseAddr(s)
+			return err == nil && netip.MustParsePrefix("10.0.0.0/8").Contains(addr)
+		}},
+		{"192.168.1.10:443 ", func(s string) bool {
+			addrPort, err := netip.ParseAddrPort(strings.TrimSpace(s))
+			return err == nil && strings.HasSuffix(s, " ") && addrPort.Addr().Is4() && addrPort.Port() == 443
+		}},
+		{"2001:db8::1", func(s string) bool {
+			addr, err := netip.ParseAddr(s)
+			return err == nil && netip.MustParsePrefix("fd00::/8").Contains(addr)
+		}},
+		{"[2001:db8::1]:8080", func(s string) bool {
+			addrPort, err := netip.ParseAddrPort(s)
+			return err == nil && addrPort.Addr().Is6() && addrPort.Port() == 8080
+		}},
+		{"00:1A:2B:3C:4D:5E", func(s string) bool {
+			return regexp.MustCompile(`^[0-9A-F]{2}(:[0-9A-F]{2}){5}$`).MatchString(s) &&
+				strings.ContainsAny(s[1:2], "2367ABEF")
+		}},
+		{"00-1a-2b-3c-4d-5e", func(s string) bool {
+			return regexp.MustCompile(`^[0-9a-f]{2}(-[0-9a-f]{2}){5}$`).MatchString(s)
+		}},
+		{"550e8400-e29b-41d4-a716-446655440000", uuid.MatchString},
+		{"\"John.Smith\"", func(s string) bool {
+			return regexp.MustCompile(`^"[A-Z][a-z]{3}\.[A-Z][a-z]{4}"$`).MatchString(s)
+		}},
+		{"- ", func(s string) bool { return s == "- " }},
+	}
+
+	for _, tt := range tests {
+		t.Run("TestRedactPseudonym"+tt.str, func(t *testing.T) {
+			pseudonym := r.redact(tt.str)
+			if pseudonym == tt.str && tt.str != "- " {
+				t.Errorf("%q wasn't redacted", tt.str)
+			}
+			if !tt.check(pseudonym) {
+				t.Errorf("pseudonym %q of %q has wrong shape", pseudonym, tt.str)
+			}
+			if again := r.redact(tt.str); again != pseudonym {
+				t.Errorf("got %q and %q for the same value", pseudonym, again)
+			}
+		})
+	}
+
+	t.Run("TestRedactPseudonymKey", func(t *testing.T) {
+		other := &redactor{key: []byte("other")}
+		if r.redact("192.168.1.10") == other.redact("192.168.1.10") {
+			t.Errorf("pseudonyms don't depend on the key")
+		}
+	})
+}
+
+func TestRedactHighlight(t *testing.T) {
+	tests := []struct {
+		mode    string
+		plain   string
+		colored string
+	}{
+		{
+			"",
+			"10.1.2.3 bob mail to bob@example.com",
+			"\x1b[38;2;255;0;0m10.1.2.3 \x1b[0mbob mail to " +
+				"\x1b[38;2;0;0;255mbob\x1b[0m\x1b[38;2;255;255;0m@\x1b[0m\x1b[38;2;255;0;255mexample.com\x1b[0m",
+		},
+		{
+			"mask",
+			"10.1.2.3 bob mail to bob@example.com",
+			"\x1b[38;2;255;0;0m*** \x1b[0m*** mail to " +
+				"\x1b[38;2;0;0;255m***\x1b[0m\x1b[38;2;255;255;0m@\x1b[0m\x1b[38;2;255;0;255m***\x1b[0m",
+		},
+		// the style is chosen by the original value
+		{"mask", "127.0.0.1 - mail to nobody", "\x1b[38;2;0;255;0m*** \x1b[0m- mail to nobody"},
+		// only values colored by groups with "redact" field are redacted
+		{"mask", `{"token":"abc","user":"bob@example.com"}`, `{"token":"***","user":"bob@example.com"}`},
+	}
+
+	for _, tt := range tests {
+		hl, err := newTestHighlighter(t, "./testdata/redact/newFormats/01_good.yaml", config.Options{Redact: tt.mode})
+		if err != nil {
+			t.Fatalf("NewHighlighter() failed with this error: %s", err)
+		}
+		t.Run("TestRedactHighlight"+tt.mode+tt.plain, func(t *testing.T) {
+			if colored := hl.Colorize(tt.plain); colored != tt.colored {
+				t.Errorf("got %q, want %q", colored, tt.colored)
+			}
+		})
+	}
+}
+
+func TestRedactHighlightPseudonym(t *testing.T) {
+	hl, err := newTestHighlighter(t, "./testdata/redact/newFormats/01_good.yaml", config.Options{Redact: "pseudonym"})
+	if err != nil {
+		t.Fatalf("NewHighlighter() failed with this error: %s", err)
+	}
+
+	colored := hl.Colorize("10.1.2.3 10.1.2.3 mail to bob@example.com")
+	parts := strings.Fields(allANSIEscapeSequencesRegExp.ReplaceAllString(colored, ""))
+	if len(parts) != 5 {
+		t.Fatalf("got %q, want 5 fields", colored)
+	}
+	if parts[0] == "10.1.2.3" || parts[0] != parts[1] {
+		t.Errorf("got %q and %q, want the same pseudonym for 10.1.2.3", parts[0], parts[1])
+	}
+	if again := hl.Colorize("10.1.2.3 10.1.2.3 mail to bob@example.com"); again != colored {
+		t.Errorf("got %q and %q for the same line", colored, again)
+	}
+}
+
+func TestRedactNewFormatsBad(t *testing.T) {
+	cfg := koanf.New(".")
+	err := cfg.Load(file.Provider("./testdata/redact/newFormats/02_alternative.yaml"), yaml.Parser())
+	if err != nil {
+		t.Fatalf("cfg.Load(...) failed with this error: %s", err)
+	}
+
+	if _, err := newFormats(cfg, "test"); err == nil {
+		t.Errorf("newFormats() should have failed")
+	}
+}
@@ -8,7 +8,8 @@ var (
 	// values from configuration files will be checked using these regular expressions
 	capGroupRegExp          = regexp.MustCompile(`^\(.+\)$`)
 	colorRegExp             = regexp.MustCompile(`^(#[[:xdigit:]]{6}|[[:digit:]]{1,3})?$`)
-	styleRegExp             = regexp.MustCompile(`^(bold|faint|italic|underline|overline|crossout|reverse|words|patterns|patterns-and-words)?$`)
+	hexColorRegExp          = regexp.MustCompile(`^#[[:xdigit:]]{6}$`)
+	styleRegExp             = regexp.MustCompile(`^(bold|faint|italic|underline|overline|crossout|reverse|hash|gradient|words|patterns|patterns-and-words)?$`)
 	nonRecursiveStyleRegExp = regexp.MustCompile(`^(bold|faint|italic|underline|overline|crossout|reverse)?$`)
 	keywordRegExp           = regexp.MustCompile(`^(fg|bg|style|link-to)$`)
 
@@ -43,15 +44,12 @@ var (
 		`(?:(?:\d{1,4}(?:[;:]\d{0,4})*)?[\dA-PR-TZcf-nq-uy=><~]))`,
 	)
 
-	// match only Select Graphic Rendition sequences
-	sgrANSIEscapeSequenceRegExp = regexp.MustCompile(`` +
-		// CSI (Control Sequence Introducer)
-		`(?:\x1B\[|\x9B)` +
-		// opening sequence (attributes that set color or text style)
-		`\d{1,4}(?:[;:]\d{0,4})*m` +
-		// text that will be displayed in according to attributes from the opening sequence above
-		`.*?` +
-		// closing sequence
+	// match text colored with Select Graphic Rendition sequences,
+	// opening sequences (there can be several of them in a row)
+	// and the text are in separate groups
+	sgrSegmentRegExp = regexp.MustCompile(`` +
+		`((?:(?:\x1B\[|\x9B)\d{1,4}(?:[;:]\d{0,4})*m)+)` +
+		`(.*?)` +
 		`(?:\x1B\[|\x9B)0?m`,
 	)
 )
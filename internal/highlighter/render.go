@@ -0,0 +1,344 @@
+package highlighter
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/deponian/logalize/internal/config"
+	"github.com/knadh/koanf/v2"
+	"github.com/muesli/termenv"
+)
+
+// renderer turns colored lines into the output format of --output flag.
+// The highlighter only finds what is colored and how (see span),
+// so every output format is a renderer of the same spans.
+type renderer interface {
+	// line renders the spans of one colored line
+	line(spans []span) string
+	// document returns what goes before and after all the lines
+	document() (header, footer string)
+}
+
+// wholeRenderer is a renderer that lays out all the lines at once
+// (e.g. to know the size of a picture), so it keeps the spans
+// of the lines and the output is collected first
+type wholeRenderer interface {
+	renderer
+	// renderAll renders the whole output made of rendered lines
+	// and line terminators
+	renderAll(output string) string
+}
+
+// newRenderer returns the renderer of the output format
+func newRenderer(opts config.Options, page pageColors, profile termenv.Profile) (renderer, error) {
+	switch opts.Output {
+	case "", "ansi":
+		return ansiRenderer{profile: profile}, nil
+	case "plain":
+		return plainRenderer{}, nil
+	case "html":
+		return htmlRenderer{page: page}, nil
+	case "html-fragment":
+		return htmlRenderer{page: page, fragment: true}, nil
+	case "svg":
+		if opts.Follow {
+			return nil, fmt.Errorf("[output] format \"svg\" needs the whole input and can't be used with --follow")
+		}
+
+		return newSVGRenderer(page), nil
+	case "json":
+		return jsonRenderer{}, nil
+	}
+
+	return nil, fmt.Errorf("[output] format %q must be \"ansi\", \"plain\", \"html\", \"html-fragment\", \"svg\" or \"json\"", opts.Output)
+}
+
+// Document returns what must be written before and after
+// all the lines (e.g. the beginning and the end of HTML document)
+func (h Highlighter) Document() (header, footer string) {
+	return h.renderer.document()
+}
+
+// Buffered reports whether the output format needs all the lines at once
+// (see --output svg). In that case the whole output must be collected
+// and passed to RenderAll instead of being written line by line.
+func (h Highlighter) Buffered() bool {
+	_, ok := h.renderer.(wholeRenderer)
+
+	return ok
+}
+
+// RenderAll renders the whole output collected from the lines
+// if the output format needs it (see Buffered)
+func (h Highlighter) RenderAll(output string) string {
+	if r, ok := h.renderer.(wholeRenderer); ok {
+		return r.renderAll(output)
+	}
+
+	return output
+}
+
+// LineTerminator returns the terminator of the rendered line.
+// Every line of JSON output ends with "\n" (see --output json).
+func (h Highlighter) LineTerminator(terminator string) string {
+	if _, ok := h.renderer.(jsonRenderer); ok {
+		return "\n"
+	}
+
+	return terminator
+}
+
+// plainRenderer writes only the text of lines without any colors
+// (e.g. to share a log processed with --redact or --tz flags)
+type plainRenderer struct{}
+
+func (plainRenderer) line(spans []span) string {
+	var out strings.Builder
+	for _, s := range spans {
+		// the text may have escape sequences of the input
+		if strings.ContainsAny(s.text, "\x1b\x9b") {
+			out.WriteString(allANSIEscapeSequencesRegExp.ReplaceAllString(s.text, ""))
+
+			continue
+		}
+		out.WriteString(s.text)
+	}
+
+	return out.String()
+}
+
+func (plainRenderer) document() (string, string) {
+	return "", ""
+}
+
+// pageColors are the colors of the page behind the text for the outputs
+// that aren't shown in the terminal (see "page" in themes)
+type pageColors struct {
+	Foreground string
+	Background string
+}
+
+// newPageColors returns page colors of the theme. The default color
+// of the theme is used if the theme doesn't have page colors.
+func newPageColors(config *koanf.Koanf, theme string) (pageColors, error) {
+	if config == nil {
+		return pageColors{}, nil
+	}
+
+	fg := cmp.Or(config.String("themes."+theme+".page.fg"), config.String("themes."+theme+".default.fg"))
+	bg := cmp.Or(config.String("themes."+theme+".page.bg"), config.String("themes."+theme+".default.bg"))
+	if !colorRegExp.MatchString(fg) {
+		return pageColors{}, fmt.Errorf("[page] foreground color %s doesn't match %s pattern", fg, colorRegExp)
+	}
+	if !colorRegExp.MatchString(bg) {
+		return pageColors{}, fmt.Errorf("[page] background color %s doesn't match %s pattern", bg, colorRegExp)
+	}
+
+	return pageColors{Foreground: hexColor(fg), Background: hexColor(bg)}, nil
+}
+
+// hexColor returns the hex value of the color from configuration
+// (it's either a hex value already or a number of one of 256 ANSI colors)
+func hexColor(color string) string {
+	n, err := strconv.Atoi(color)
+	if err != nil {
+		return color
+	}
+	if n > 255 {
+		return ""
+	}
+
+	return ansiHex(n)
+}
+
+// textStyle is the state of the text after a series of SGR sequences.
+// Colors are hex values like "#ff0000" or empty for the default color.
+type textStyle struct {
+	fg, bg    string
+	bold      bool
+	faint     bool
+	italic    bool
+	underline bool
+	overline  bool
+	crossout  bool
+	reverse   bool
+}
+
+// styledText is a part of a line with the same style
+type styledText struct {
+	text  string
+	style textStyle
+}
+
+// splitStyles splits the line colored with SGR sequences into parts
+// with the same style starting with the style. Other escape sequences are dropped.
+func splitStyles(colored string, style textStyle) []styledText {
+	var parts []styledText
+	for colored != "" {
+		loc := allANSIEscapeSequencesRegExp.FindStringIndex(colored)
+		if loc == nil {
+			parts = append(parts, styledText{colored, style})
+
+			break
+		}
+		if loc[0] > 0 {
+			parts = append(parts, styledText{colored[:loc[0]], style})
+		}
+		style.applySequence(colored[loc[0]:loc[1]])
+		colored = colored[loc[1]:]
+	}
+
+	return parts
+}
+
+// spanTextStyle returns the style of the span with the styles
+// of the spans it's painted on top of
+func spanTextStyle(s span) textStyle {
+	var style textStyle
+	for _, st := range slices.Concat(s.under, []spanStyle{s.spanStyle}) {
+		for _, seq := range allANSIEscapeSequencesRegExp.FindAllString(st.sgr, -1) {
+			style.applySequence(seq)
+		}
+		if st.fg != "" {
+			style.fg = hexColor(st.fg)
+		}
+		if st.bg != "" {
+			style.bg = hexColor(st.bg)
+		}
+		switch st.style {
+		case "bold":
+			style.bold = true
+		case "faint":
+			style.faint = true
+		case "italic":
+			style.italic = true
+		case "underline":
+			style.underline = true
+		case "overline":
+			style.overline = true
+		case "crossout":
+			style.crossout = true
+		case "reverse":
+			style.reverse = true
+		}
+	}
+
+	return style
+}
+
+// applySequence changes the style if the escape sequence is SGR one
+func (s *textStyle) applySequence(seq string) {
+	if strings.HasSuffix(seq, "m") {
+		seq = strings.TrimPrefix(strings.TrimPrefix(seq, "\x1b["), "\x9b")
+		s.apply(seq[:len(seq)-1])
+	}
+}
+
+// apply changes the style according to the parameters of SGR sequence
+func (s *textStyle) apply(params string) {
+	codes := strings.FieldsFunc(params, func(r rune) bool { return r == ';' || r == ':' })
+	if len(codes) == 0 {
+		*s = textStyle{}
+
+		return
+	}
+
+	for i := 0; i < len(codes); i++ {
+		code, err := strconv.Atoi(codes[i])
+		if err != nil {
+			continue
+		}
+
+		switch {
+		case code == 0:
+			*s = textStyle{}
+		case code == 1:
+			s.bold = true
+		case code == 2:
+			s.faint = true
+		case code == 3:
+			s.italic = true
+		case code == 4:
+			s.underline = true
+		case code == 7:
+			s.reverse = true
+		case code == 9:
+			s.crossout = true
+		case code == 22:
+			s.bold, s.faint = false, false
+		case code == 23:
+			s.italic = false
+		case code == 24:
+			s.underline = false
+		case code == 27:
+			s.reverse = false
+		case code == 29:
+			s.crossout = false
+		case code == 53:
+			s.overline = true
+		case code == 55:
+			s.overline = false
+		case code >= 30 && code <= 37:
+			s.fg = ansiHex(code - 30)
+		case code >= 90 && code <= 97:
+			s.fg = ansiHex(code - 90 + 8)
+		case code == 39:
+			s.fg = ""
+		case code >= 40 && code <= 47:
+			s.bg = ansiHex(code - 40)
+		case code >= 100 && code <= 107:
+			s.bg = ansiHex(code - 100 + 8)
+		case code == 49:
+			s.bg = ""
+		case code == 38 || code == 48:
+			var color string
+			color, i = extendedColor(codes, i+1)
+			if code == 38 {
+				s.fg = color
+			} else {
+				s.bg = color
+			}
+		}
+	}
+}
+
+// extendedColor parses 256 colors ("5;n") and true colors ("2;r;g;b")
+// that start at codes[i] and returns the color and the index of its last code.
+// The rest of the codes is skipped if the color is malformed.
+func extendedColor(codes []string, i int) (string, int) {
+	var size int
+	switch {
+	case i < len(codes) && codes[i] == "5":
+		size = 1
+	case i < len(codes) && codes[i] == "2":
+		size = 3
+	default:
+		return "", len(codes)
+	}
+	if i+size >= len(codes) {
+		return "", len(codes)
+	}
+
+	n := make([]int, size)
+	for j := range n {
+		v, err := strconv.Atoi(codes[i+1+j])
+		if err != nil || v < 0 || v > 255 {
+			return "", len(codes)
+		}
+		n[j] = v
+	}
+
+	if size == 1 {
+		return ansiHex(n[0]), i + size
+	}
+
+	return fmt.Sprintf("#%02x%02x%02x", n[0], n[1], n[2]), i + size
+}
+
+// ansiHex returns the hex value of one of 256 ANSI colors
+func ansiHex(n int) string {
+	return termenv.ConvertToRGB(termenv.ANSI256Color(n)).Hex()
+}
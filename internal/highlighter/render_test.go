@@ -0,0 +1,142 @@
+package highlighter
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/deponian/logalize/internal/config"
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/v2"
+	"github.com/muesli/termenv"
+)
+
+func TestRenderNewRenderer(t *testing.T) {
+	tests := []struct {
+		output   string
+		renderer renderer
+		err      string
+	}{
+		{"", ansiRenderer{termenv.TrueColor}, "%!s(<nil>)"},
+		{"ansi", ansiRenderer{termenv.TrueColor}, "%!s(<nil>)"},
+		{"plain", plainRenderer{}, "%!s(<nil>)"},
+		{"html", htmlRenderer{}, "%!s(<nil>)"},
+		{"html-fragment", htmlRenderer{fragment: true}, "%!s(<nil>)"},
+		{"svg", newSVGRenderer(pageColors{}), "%!s(<nil>)"},
+		{"json", jsonRenderer{}, "%!s(<nil>)"},
+		{"xml", nil, `[output] format "xml" must be "ansi", "plain", "html", "html-fragment", "svg" or "json"`},
+	}
+
+	for _, tt := range tests {
+		t.Run("TestRenderNewRenderer"+tt.output, func(t *testing.T) {
+			r, err := newRenderer(config.Options{Output: tt.output}, pageColors{}, termenv.TrueColor)
+			if e := fmt.Sprintf("%s", err); e != tt.err {
+				t.Errorf("got %s, want %s", e, tt.err)
+			}
+			if !reflect.DeepEqual(r, tt.renderer) {
+				t.Errorf("got %#v, want %#v", r, tt.renderer)
+			}
+		})
+	}
+
+	t.Run("TestRenderNewRendererSVGFollow", func(t *testing.T) {
+		if _, err := newRenderer(config.Options{Output: "svg", Follow: true}, pageColors{}, termenv.TrueColor); err == nil {
+			t.Errorf("newRenderer() should have failed")
+		}
+	})
+}
+
+func TestRenderPlainLine(t *testing.T) {
+	spans := []span{
+		{text: "red", spanStyle: spanStyle{fg: "#ff0000", style: "bold"}},
+		{text: " input", spanStyle: spanStyle{sgr: "\x1b[31m"}, origin: origin{kind: "input"}},
+		{text: " \x1b[1mbold\x1b[0m \x1b[2Kcleared"},
+	}
+	if text := (plainRenderer{}).line(spans); text != "red input bold cleared" {
+		t.Errorf("got %q, want %q", text, "red input bold cleared")
+	}
+}
+
+func TestRenderNewPageColors(t *testing.T) {
+	tests := []struct {
+		path string
+		page pageColors
+		err  bool
+	}{
+		{"./testdata/render/newPageColors/01_page.yaml", pageColors{"#ffffff", "#000000"}, false},
+		{"./testdata/render/newPageColors/02_default.yaml", pageColors{"#ff0000", "#000000"}, false},
+		{"./testdata/render/newPageColors/03_bad.yaml", pageColors{}, true},
+	}
+
+	for _, tt := range tests {
+		cfg := koanf.New(".")
+		err := cfg.Load(file.Provider(tt.path), yaml.Parser())
+		if err != nil {
+			t.Fatalf("cfg.Load(...) failed with this error: %s", err)
+		}
+
+		t.Run("TestRenderNewPageColors"+tt.path, func(t *testing.T) {
+			page, err := newPageColors(cfg, "test")
+			if (err != nil) != tt.err {
+				t.Errorf("got error %v, want error: %v", err, tt.err)
+			}
+			if page != tt.page {
+				t.Errorf("got %v, want %v", page, tt.page)
+			}
+		})
+	}
+
+	t.Run("TestRenderNewPageColorsNil", func(t *testing.T) {
+		if page, err := newPageColors(nil, "test"); err != nil || page != (pageColors{}) {
+			t.Errorf("got %v and %v, want empty colors", page, err)
+		}
+	})
+}
+
+func TestRenderSplitStyles(t *testing.T) {
+	tests := []struct {
+		colored string
+		parts   []styledText
+	}{
+		{"plain", []styledText{{"plain", textStyle{}}}},
+		{
+			"\x1b[38;2;255;0;0mred\x1b[0m plain",
+			[]styledText{{"red", textStyle{fg: "#ff0000"}}, {" plain", textStyle{}}},
+		},
+		{
+			"\x1b[48;5;196;1;4mbold\x1b[0m",
+			[]styledText{{"bold", textStyle{bg: "#ff0000", bold: true, underline: true}}},
+		},
+		{
+			// several sequences in a row and partial resets
+			"\x1b[31m\x1b[7mone\x1b[27;39;42mtwo\x1b[m",
+			[]styledText{{"one", textStyle{fg: "#800000", reverse: true}}, {"two", textStyle{bg: "#008000"}}},
+		},
+		{
+			"\x1b[92;2;3;9;53mall\x1b[22;23;29;55;104mnone\x1b[49m",
+			[]styledText{
+				{"all", textStyle{fg: "#00ff00", faint: true, italic: true, crossout: true, overline: true}},
+				{"none", textStyle{fg: "#00ff00", bg: "#0000ff"}},
+			},
+		},
+		{
+			// colons and malformed colors
+			"\x1b[38:2::1:2:3mcolon\x1b[0m\x1b[38;2;1mbad\x1b[0m",
+			[]styledText{{"colon", textStyle{fg: "#010203"}}, {"bad", textStyle{}}},
+		},
+		{
+			// other escape sequences are dropped
+			"\x1b[2Kclear\x1b]0;title\x07",
+			[]styledText{{"clear", textStyle{}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("TestRenderSplitStyles%q", tt.colored), func(t *testing.T) {
+			if parts := splitStyles(tt.colored, textStyle{}); !reflect.DeepEqual(parts, tt.parts) {
+				t.Errorf("got %#v, want %#v", parts, tt.parts)
+			}
+		})
+	}
+}
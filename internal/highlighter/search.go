@@ -0,0 +1,139 @@
+package highlighter
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/knadh/koanf/v2"
+)
+
+// search is a regexp from -H/--highlight flag.
+// Its matches are painted on top of everything else.
+type search struct {
+	RegExp     *regexp.Regexp
+	Foreground string
+	Background string
+	Style      string
+}
+
+type searchList []search
+
+// newSearches returns list of searches from regexps and COLOR:REGEX expressions.
+// Matches of the regexps are painted with the "highlight" color and style
+// of the theme (or reversed if the theme doesn't have them). Matches
+// of the expressions are painted with the background of their color.
+func newSearches(exprs, coloredExprs []string, config *koanf.Koanf, theme string) (searchList, error) {
+	defaultStyle := tokenStyle{Style: "reverse"}
+	if config != nil && config.Exists("themes."+theme+".highlight") {
+		defaultStyle = newTokenStyle(config, "themes."+theme+".highlight")
+		if !colorRegExp.MatchString(defaultStyle.Foreground) ||
+			!colorRegExp.MatchString(defaultStyle.Background) ||
+			!nonRecursiveStyleRegExp.MatchString(defaultStyle.Style) {
+			return nil, fmt.Errorf(
+				"[theme: %s] [highlight] colors must match %s and style must match %s",
+				theme, colorRegExp, nonRecursiveStyleRegExp)
+		}
+	}
+
+	searches := make(searchList, 0, len(exprs)+len(coloredExprs))
+	for _, expr := range exprs {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("[highlight: %s] %s", expr, err)
+		}
+		searches = append(searches, search{
+			RegExp:     re,
+			Foreground: defaultStyle.Foreground,
+			Background: defaultStyle.Background,
+			Style:      defaultStyle.Style,
+		})
+	}
+
+	for _, coloredExpr := range coloredExprs {
+		// colors never contain ":", so the rest is the regexp whatever it is
+		color, expr, found := strings.Cut(coloredExpr, ":")
+		if !found || color == "" || !colorRegExp.MatchString(color) || hexColor(color) == "" {
+			return nil, fmt.Errorf(
+				"[highlight-color: %s] must be COLOR:REGEX where COLOR is a hex value like #ff0000 or a number between 0 and 255",
+				coloredExpr)
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("[highlight-color: %s] %s", coloredExpr, err)
+		}
+		searches = append(searches, search{RegExp: re, Background: color})
+	}
+
+	return searches, nil
+}
+
+// highlight paints matches of all searches in the colored line.
+// Colors of the matched parts are kept and the color and the style
+// of the search are applied on top of them.
+func (searches searchList) highlight(spans []span, h Highlighter) []span {
+	for _, s := range searches {
+		spans = s.highlight(spans, h)
+	}
+
+	return spans
+}
+
+func (s search) highlight(spans []span, h Highlighter) []span {
+	var matches [][]int
+	for _, m := range s.RegExp.FindAllStringIndex(spansText(spans), -1) {
+		if m[0] < m[1] {
+			matches = append(matches, m)
+		}
+	}
+	if len(matches) == 0 {
+		return spans
+	}
+
+	out := make([]span, 0, len(spans))
+	offset := 0
+	for _, sp := range spans {
+		start, end := offset, offset+len(sp.text)
+		offset = end
+
+		pos := start
+		for _, m := range matches {
+			if m[1] <= pos || m[0] >= end {
+				continue
+			}
+			// part before the match
+			if m[0] > pos {
+				out = append(out, sp.slice(pos-start, m[0]-start))
+			}
+			// the match itself on top of the span's colors
+			from, to := max(m[0], pos), min(m[1], end)
+			out = append(out, s.paint(sp, sp.text[from-start:to-start], h))
+			pos = to
+		}
+		if pos < end {
+			out = append(out, sp.slice(pos-start, end-start))
+		}
+	}
+
+	return out
+}
+
+// paint returns the part of the span painted by the search
+func (s search) paint(sp span, text string, h Highlighter) span {
+	painted := h.highlight(text, s.Foreground, s.Background, s.Style)[0]
+	painted.under = sp.under
+	if sp.spanStyle != (spanStyle{}) {
+		painted.under = slices.Concat(sp.under, []spanStyle{sp.spanStyle})
+	}
+	painted.origin = origin{kind: "search", name: s.RegExp.String()}
+
+	return painted
+}
+
+// slice returns the span with the part of its text between from and to
+func (sp span) slice(from, to int) span {
+	sp.text = sp.text[from:to]
+
+	return sp
+}
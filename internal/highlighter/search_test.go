@@ -0,0 +1,148 @@
+package highlighter
+
+import (
+	"testing"
+
+	"github.com/deponian/logalize/internal/config"
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/v2"
+)
+
+func TestSearchNew(t *testing.T) {
+	tests := []struct {
+		expr    string
+		colored bool
+		regexp  string
+		search  search
+	}{
+		{"req-[0-9]+", false, "req-[0-9]+", search{Style: "reverse"}},
+		// regexps are taken as they are whatever they end with
+		{"status=200", false, "status=200", search{Style: "reverse"}},
+		{"user=#ff0000", false, "user=#ff0000", search{Style: "reverse"}},
+		{`id\=42`, false, `id\=42`, search{Style: "reverse"}},
+		{"fe80::1", false, "fe80::1", search{Style: "reverse"}},
+		{"#ff0000:user", true, "user", search{Background: "#ff0000"}},
+		{"42:status=200", true, "status=200", search{Background: "42"}},
+		{"255:fe80::1", true, "fe80::1", search{Background: "255"}},
+	}
+
+	for _, tt := range tests {
+		t.Run("TestSearchNew"+tt.expr, func(t *testing.T) {
+			exprs, coloredExprs := []string{tt.expr}, []string(nil)
+			if tt.colored {
+				exprs, coloredExprs = nil, exprs
+			}
+			searches, err := newSearches(exprs, coloredExprs, nil, "")
+			if err != nil {
+				t.Fatalf("newSearches() failed with this error: %s", err)
+			}
+			s := searches[0]
+			if s.RegExp.String() != tt.regexp {
+				t.Errorf("got regexp %q, want %q", s.RegExp, tt.regexp)
+			}
+			s.RegExp = nil
+			if s != tt.search {
+				t.Errorf("got %v, want %v", s, tt.search)
+			}
+		})
+	}
+
+	t.Run("TestSearchNewBadRegExp", func(t *testing.T) {
+		if _, err := newSearches([]string{"req-[0-9"}, nil, nil, ""); err == nil {
+			t.Errorf("newSearches() should have failed")
+		}
+	})
+
+	for _, coloredExpr := range []string{"256:user", "999:user", "red:user", "user", ":user", "#ff0000:req-[0-9"} {
+		t.Run("TestSearchNewBadColored"+coloredExpr, func(t *testing.T) {
+			if _, err := newSearches(nil, []string{coloredExpr}, nil, ""); err == nil {
+				t.Errorf("newSearches() should have failed")
+			}
+		})
+	}
+
+	t.Run("TestSearchNewBadTheme", func(t *testing.T) {
+		cfg := koanf.New(".")
+		err := cfg.Load(file.Provider("./testdata/search/newSearches/02_bad_theme.yaml"), yaml.Parser())
+		if err != nil {
+			t.Fatalf("cfg.Load(...) failed with this error: %s", err)
+		}
+		settings := config.Settings{Config: cfg, Opts: config.Options{Theme: "test", Highlights: []string{"a"}}}
+		if _, err := NewHighlighter(settings); err == nil {
+			t.Errorf("NewHighlighter() should have failed")
+		}
+	})
+}
+
+func TestSearchHighlight(t *testing.T) {
+	tests := []struct {
+		name       string
+		theme      string
+		highlights []string
+		colors     []string
+		plain      string
+		colored    string
+	}{
+		{
+			"InsideFormat",
+			"test",
+			[]string{"api"},
+			nil,
+			"GET /api/v1 200",
+			"\x1b[38;2;0;0;255mGET \x1b[0m\x1b[38;2;0;255;0m/\x1b[0m\x1b[38;2;0;255;0m\x1b[7mapi\x1b[0m\x1b[38;2;0;255;0m/v1 \x1b[0m\x1b[38;2;255;255;0m200\x1b[0m",
+		},
+		{
+			"AcrossSpans",
+			"test",
+			[]string{"T /a"},
+			nil,
+			"GET /api/v1 200",
+			"\x1b[38;2;0;0;255mGE\x1b[0m\x1b[38;2;0;0;255m\x1b[7mT \x1b[0m\x1b[38;2;0;255;0m\x1b[7m/a\x1b[0m\x1b[38;2;0;255;0mpi/v1 \x1b[0m\x1b[38;2;255;255;0m200\x1b[0m",
+		},
+		{
+			"PlainText",
+			"test",
+			nil,
+			[]string{"#ff0000:o+"},
+			"foo 7 boo",
+			"f\x1b[48;2;255;0;0moo\x1b[0m \x1b[38;2;255;255;0m7\x1b[0m b\x1b[48;2;255;0;0moo\x1b[0m",
+		},
+		{
+			"SeveralRegExps",
+			"test",
+			[]string{"foo", "7"},
+			nil,
+			"foo 7",
+			"\x1b[7mfoo\x1b[0m \x1b[38;2;255;255;0m\x1b[7m7\x1b[0m",
+		},
+		{
+			"ThemeStyle",
+			"underlined",
+			[]string{"foo"},
+			nil,
+			"foo bar",
+			"\x1b[38;2;255;0;0;4mfoo\x1b[0m bar",
+		},
+		{
+			"NoMatches",
+			"test",
+			[]string{"baz", "x*"},
+			nil,
+			"foo 7",
+			"foo \x1b[38;2;255;255;0m7\x1b[0m",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run("TestSearchHighlight"+tt.name, func(t *testing.T) {
+			hl, err := newTestHighlighter(t, "./testdata/search/newSearches/01_good.yaml", config.Options{Theme: tt.theme, Highlights: tt.highlights, HighlightColors: tt.colors})
+			if err != nil {
+				t.Fatalf("NewHighlighter() failed with this error: %s", err)
+			}
+			if colored := hl.Colorize(tt.plain); colored != tt.colored {
+				t.Errorf("got %q, want %q", colored, tt.colored)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package highlighter
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/knadh/koanf/v2"
+)
+
+// Level is a normalized severity level of a line
+type Level int
+
+// Levels from the least to the most severe one.
+// LevelUnknown means that the line has no severity.
+const (
+	LevelUnknown Level = iota
+	LevelTrace
+	LevelDebug
+	LevelInfo
+	LevelWarn
+	LevelError
+	LevelFatal
+)
+
+var levelNames = []string{"", "trace", "debug", "info", "warn", "error", "fatal"}
+
+// levelAliases are other common names of the levels
+var levelAliases = map[string]Level{
+	"warning":  LevelWarn,
+	"err":      LevelError,
+	"critical": LevelFatal,
+	"crit":     LevelFatal,
+}
+
+// syslogLevels maps syslog severities (priority % 8) to levels
+var syslogLevels = [8]Level{
+	LevelFatal, // emergency
+	LevelFatal, // alert
+	LevelFatal, // critical
+	LevelError, // error
+	LevelWarn,  // warning
+	LevelInfo,  // notice
+	LevelInfo,  // informational
+	LevelDebug, // debug
+}
+
+// ParseLevel returns the level with the name
+func ParseLevel(name string) (Level, error) {
+	name = strings.ToLower(name)
+	for i, levelName := range levelNames {
+		if levelName != "" && levelName == name {
+			return Level(i), nil
+		}
+	}
+	if level, ok := levelAliases[name]; ok {
+		return level, nil
+	}
+
+	return LevelUnknown, fmt.Errorf(
+		"unknown level %q. Use one of: %s", name, strings.Join(levelNames[1:], ", "))
+}
+
+func (l Level) String() string {
+	if l < LevelUnknown || int(l) >= len(levelNames) {
+		return ""
+	}
+
+	return levelNames[l]
+}
+
+// severity describes where a format keeps the severity of its lines
+type severity struct {
+	// Group is the name of the capturing group (or the key of JSON
+	// format) with the severity. Levels of its alternatives are taken
+	// from Levels or from the names of the alternatives themselves.
+	Group  string            `koanf:"group"`
+	Levels map[string]string `koanf:"levels"`
+	// SyslogPriority means that the group holds syslog priority
+	// like "<13>" instead of alternatives
+	SyslogPriority bool `koanf:"syslog-priority"`
+	// Level is the level of every line of the format
+	Level string `koanf:"level"`
+
+	fixed Level
+	// the capturing group or the key with the severity
+	group *capGroup
+	index int
+	// levels of the group's alternatives
+	levels map[string]Level
+}
+
+// collectSeverity reads the severity of a format from "formats.<name>.severity"
+func collectSeverity(config *koanf.Koanf, name string) (*severity, error) {
+	s := &severity{}
+	if err := config.Unmarshal("formats."+name+".severity", s); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// init finds the capturing group with the severity
+// and the levels of its alternatives
+func (s *severity) init(lf *format) error {
+	if (s.Group == "") == (s.Level == "") {
+		return fmt.Errorf("severity must have exactly one of \"group\" and \"level\" fields")
+	}
+
+	if s.Level != "" {
+		level, err := ParseLevel(s.Level)
+		if err != nil {
+			return fmt.Errorf("[severity] %s", err)
+		}
+		s.fixed = level
+
+		return nil
+	}
+
+	var ok bool
+	if s.group, s.index, ok = lf.findGroup(s.Group); !ok {
+		if lf.JSON != nil {
+			return fmt.Errorf("[severity] key %q doesn't exist", s.Group)
+		}
+
+		return fmt.Errorf("[severity] capturing group %q doesn't exist", s.Group)
+	}
+
+	if s.SyslogPriority {
+		if len(s.Levels) > 0 {
+			return fmt.Errorf("[severity] \"levels\" can't be used with \"syslog-priority\"")
+		}
+
+		return nil
+	}
+
+	alternatives := s.group.Alternatives
+	for name := range s.Levels {
+		if !slices.ContainsFunc(alternatives, func(alt capGroup) bool { return alt.Name == name }) {
+			return fmt.Errorf("[severity] %q has no alternative %q", s.Group, name)
+		}
+	}
+
+	s.levels = make(map[string]Level, len(alternatives))
+	for _, alt := range alternatives {
+		name, ok := s.Levels[alt.Name]
+		if !ok {
+			// alternatives named after levels don't need to be listed
+			if level, err := ParseLevel(alt.Name); err == nil {
+				s.levels[alt.Name] = level
+			}
+
+			continue
+		}
+		level, err := ParseLevel(name)
+		if err != nil {
+			return fmt.Errorf("[severity] [alternative: %s] %s", alt.Name, err)
+		}
+		s.levels[alt.Name] = level
+	}
+
+	return nil
+}
+
+// level returns the level of the line of the format
+func (s *severity) level(str string, lf format) Level {
+	if s == nil {
+		return LevelUnknown
+	}
+	if s.fixed != LevelUnknown {
+		return s.fixed
+	}
+
+	value, ok := lf.groupValue(str, s.group, s.index)
+	if !ok {
+		return LevelUnknown
+	}
+
+	if s.SyslogPriority {
+		priority, err := strconv.Atoi(strings.Trim(value, "<>\""))
+		if err != nil || priority < 0 {
+			return LevelUnknown
+		}
+
+		return syslogLevels[priority%8]
+	}
+
+	if alt := s.group.alternative(value, ""); alt != nil {
+		return s.levels[alt.Name]
+	}
+
+	return LevelUnknown
+}
+
+// levelFromWords returns the level of a line
+// without explicit severity by its word groups
+func levelFromWords(groups []string) Level {
+	level := LevelUnknown
+	for _, group := range groups {
+		switch group {
+		case "bad":
+			level = max(level, LevelError)
+		case "warning":
+			level = max(level, LevelWarn)
+		}
+	}
+
+	return level
+}
@@ -0,0 +1,278 @@
+package highlighter
+
+import (
+	"testing"
+
+	"github.com/deponian/logalize/internal/config"
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/v2"
+	"github.com/muesli/termenv"
+)
+
+func TestSeverityParseLevel(t *testing.T) {
+	tests := []struct {
+		name  string
+		level Level
+	}{
+		{"trace", LevelTrace},
+		{"debug", LevelDebug},
+		{"info", LevelInfo},
+		{"warn", LevelWarn},
+		{"warning", LevelWarn},
+		{"WARNING", LevelWarn},
+		{"error", LevelError},
+		{"err", LevelError},
+		{"fatal", LevelFatal},
+		{"critical", LevelFatal},
+		{"crit", LevelFatal},
+	}
+
+	for _, tt := range tests {
+		t.Run("TestSeverityParseLevel"+tt.name, func(t *testing.T) {
+			level, err := ParseLevel(tt.name)
+			if err != nil {
+				t.Fatalf("ParseLevel(%q) failed with this error: %s", tt.name, err)
+			}
+			if level != tt.level {
+				t.Errorf("ParseLevel(%q) = %s, want %s", tt.name, level, tt.level)
+			}
+		})
+	}
+
+	for _, name := range []string{"", "loud", "warnings"} {
+		if _, err := ParseLevel(name); err == nil {
+			t.Errorf("ParseLevel(%q) should have failed", name)
+		}
+	}
+
+	if name := LevelWarn.String(); name != "warn" {
+		t.Errorf("LevelWarn.String() = %q, want %q", name, "warn")
+	}
+}
+
+func TestSeverityNewBad(t *testing.T) {
+	tests := []string{
+		"./testdata/severity/newSeverity/02_group_and_level.yaml",
+		"./testdata/severity/newSeverity/03_empty.yaml",
+		"./testdata/severity/newSeverity/04_unknown_group.yaml",
+		"./testdata/severity/newSeverity/05_unknown_alternative.yaml",
+		"./testdata/severity/newSeverity/06_bad_level.yaml",
+		"./testdata/severity/newSeverity/07_syslog_with_levels.yaml",
+		"./testdata/severity/newSeverity/08_bad_fixed_level.yaml",
+		"./testdata/severity/newSeverity/09_json_unknown_key.yaml",
+		"./testdata/severity/newSeverity/10_bad_yaml.yaml",
+	}
+
+	for _, tt := range tests {
+		cfg := koanf.New(".")
+		err := cfg.Load(file.Provider(tt), yaml.Parser())
+		if err != nil {
+			t.Fatalf("cfg.Load(...) failed with this error: %s", err)
+		}
+
+		t.Run("TestSeverityNewBad"+tt, func(t *testing.T) {
+			if _, err := newFormats(cfg, "test"); err == nil {
+				t.Errorf("newFormats() should have failed")
+			}
+		})
+	}
+}
+
+func TestSeverityLevel(t *testing.T) {
+	tests := []struct {
+		plain string
+		level Level
+	}{
+		// alternatives
+		{"D message", LevelDebug},
+		{"W message", LevelWarn},
+		{"E message", LevelError},
+		{"N message", LevelInfo},
+		{"X message", LevelUnknown},
+		{"Z message", LevelUnknown},
+
+		// fixed level
+		{"panic: everything is fine", LevelFatal},
+
+		// syslog priority
+		{"<0>kernel: panic", LevelFatal},
+		{"<11>sshd: error", LevelError},
+		{"<28>sshd: warning", LevelWarn},
+		{"<13>sshd: notice", LevelInfo},
+		{"<191>sshd: debug", LevelDebug},
+		{"sshd: no priority", LevelUnknown},
+
+		// JSON keys
+		{`{"level":"ERROR","msg":"fail"}`, LevelError},
+		{`{"msg":"slow","lvl":30}`, LevelInfo},
+		{`{"msg":{"level":"error"}}`, LevelUnknown},
+		{`{"level":"trace"}`, LevelUnknown},
+
+		// lines without format fall back to words
+		{"request fail", LevelError},
+		{"slow request", LevelWarn},
+		{"slow request fail", LevelError},
+		{"just a line", LevelUnknown},
+	}
+
+	cfg := koanf.New(".")
+	err := cfg.Load(file.Provider("./testdata/severity/newSeverity/01_good.yaml"), yaml.Parser())
+	if err != nil {
+		t.Fatalf("cfg.Load(...) failed with this error: %s", err)
+	}
+
+	settings := config.Settings{
+		Config:       cfg,
+		Opts:         config.Options{Theme: "test"},
+		ColorProfile: termenv.TrueColor,
+	}
+
+	hl, err := NewHighlighter(settings)
+	if err != nil {
+		t.Fatalf("NewHighlighter() failed with this error: %s", err)
+	}
+
+	for _, tt := range tests {
+		t.Run("TestSeverityLevel"+tt.plain, func(t *testing.T) {
+			if _, match := hl.ColorizeMatch(tt.plain, Record{}); match.Level != tt.level {
+				t.Errorf("got level %q for %q, want %q", match.Level, tt.plain, tt.level)
+			}
+		})
+	}
+}
+
+func TestSeverityRecords(t *testing.T) {
+	tests := []struct {
+		plain string
+		level Level
+	}{
+		{"Error: failed", LevelError},
+		{"  at main()", LevelError},
+		{"(end)", LevelError},
+		{"plain line", LevelUnknown},
+	}
+
+	cfg := koanf.New(".")
+	err := cfg.Load(file.Provider("./testdata/multiline/RecordTracker/01_main.yaml"), yaml.Parser())
+	if err != nil {
+		t.Fatalf("cfg.Load(...) failed with this error: %s", err)
+	}
+	err = cfg.Set("formats.test.severity.level", "error")
+	if err != nil {
+		t.Fatalf("cfg.Set(...) failed with this error: %s", err)
+	}
+
+	settings := config.Settings{
+		Config:       cfg,
+		Opts:         config.Options{Theme: "test", DryRun: true},
+		ColorProfile: termenv.TrueColor,
+	}
+
+	hl, err := NewHighlighter(settings)
+	if err != nil {
+		t.Fatalf("NewHighlighter() failed with this error: %s", err)
+	}
+
+	tracker := hl.NewRecordTracker()
+	for _, tt := range tests {
+		if _, match := hl.ColorizeMatch(tt.plain, tracker.Track(tt.plain)); match.Level != tt.level {
+			t.Errorf("got level %q for %q, want %q", match.Level, tt.plain, tt.level)
+		}
+	}
+}
+
+func TestSeverityMinLevel(t *testing.T) {
+	tests := []struct {
+		minLevel string
+		level    Level
+		wanted   bool
+	}{
+		{"warn", LevelWarn, true},
+		{"warn", LevelError, true},
+		{"warn", LevelInfo, false},
+		// lines without severity are informational
+		{"warn", LevelUnknown, false},
+		{"info", LevelUnknown, true},
+		{"debug", LevelDebug, true},
+		{"info", LevelDebug, false},
+	}
+
+	for _, tt := range tests {
+		settings := config.Settings{Opts: config.Options{MinLevel: tt.minLevel}}
+		hl, err := NewHighlighter(settings)
+		if err != nil {
+			t.Fatalf("NewHighlighter() failed with this error: %s", err)
+		}
+
+		if !hl.Filtering() {
+			t.Errorf("Filtering() should be true with --min-level")
+		}
+		if wanted := hl.Wanted(Match{Level: tt.level}); wanted != tt.wanted {
+			t.Errorf("Wanted() with level %q and --min-level %s returned %t, want %t",
+				tt.level, tt.minLevel, wanted, tt.wanted)
+		}
+	}
+
+	settings := config.Settings{Opts: config.Options{MinLevel: "loud"}}
+	if _, err := NewHighlighter(settings); err == nil {
+		t.Errorf("NewHighlighter() should have failed")
+	}
+}
+
+func TestSeverityBuiltins(t *testing.T) {
+	tests := []struct {
+		plain string
+		level Level
+	}{
+		// redis
+		{"1:C 01 Feb 2024 19:41:07.224 # Configuration loaded", LevelWarn},
+		{"1:M 01 Feb 2024 19:41:07.225 * Running mode=standalone, port=6379.", LevelInfo},
+		{"1:M 01 Feb 2024 19:41:07.225 . Ready", LevelDebug},
+
+		// klog
+		{"I0410 13:18:43.650517       1 controller.go:129] started", LevelInfo},
+		{"W0410 13:18:43.650517       1 controller.go:129] starting metrics server", LevelWarn},
+		{"E0410 13:18:43.650671       1 controller.go:182] starting leader election", LevelError},
+		{"F0410 13:18:43.655728       1 leaderelection.go:250] attempting to acquire leader lease", LevelFatal},
+
+		// syslog
+		{"<25>Jul 13 10:20:04 menetekel systemd[1]: certbot.service: Deactivated successfully.", LevelFatal},
+		{"<30>Jul 13 10:20:04 menetekel systemd[1]: Finished Certbot.", LevelInfo},
+
+		// json
+		{`{"level":"warn","msg":"slow query"}`, LevelWarn},
+		{`{"level":50,"msg":"out of memory"}`, LevelError},
+
+		// multiline records
+		{`Exception in thread "main" java.lang.IllegalStateException: boom`, LevelError},
+		{"\tat com.example.App.run(App.java:42)", LevelError},
+		{`goroutine 1 [running]:`, LevelFatal},
+		{"\t/app/server.go:42 +0x1d", LevelFatal},
+	}
+
+	cfg := koanf.New(".")
+	err := cfg.Load(file.Provider("./testdata/formats/builtins/theme.yaml"), yaml.Parser())
+	if err != nil {
+		t.Fatalf("cfg.Load(...) failed with this error: %s", err)
+	}
+
+	settings, err := config.NewSettings(builtins, cfg, nil, true)
+	if err != nil {
+		t.Fatalf("config.NewSettings(...) failed with this error: %s", err)
+	}
+
+	hl, err := NewHighlighter(settings)
+	if err != nil {
+		t.Fatalf("NewHighlighter() failed with this error: %s", err)
+	}
+
+	tracker := hl.NewRecordTracker()
+	for _, tt := range tests {
+		t.Run("TestSeverityBuiltins"+tt.plain, func(t *testing.T) {
+			if _, match := hl.ColorizeMatch(tt.plain, tracker.Track(tt.plain)); match.Level != tt.level {
+				t.Errorf("got level %q for %q, want %q", match.Level, tt.plain, tt.level)
+			}
+		})
+	}
+}
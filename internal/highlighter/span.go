@@ -0,0 +1,166 @@
+package highlighter
+
+import (
+	"strings"
+)
+
+// span is a part of a colored line with the same look.
+// Colorized line is a list of spans, and every span knows
+// what colored it (see origin), so the line can be rendered
+// in any output format (see renderer).
+type span struct {
+	text string
+	spanStyle
+	// under are the styles of the spans this one
+	// is painted on top of (see --highlight flag)
+	under []spanStyle
+	// byDefault is true for the text colored
+	// with the default color (see applyDefaultColor)
+	byDefault bool
+	origin
+}
+
+// spanStyle is how the text of a span looks. Colors and the style
+// come from the theme (the style is one of non-recursive styles),
+// sgr is the SGR sequences of the text colored in the input
+// (see --no-ansi-escape-sequences-stripping flag).
+type spanStyle struct {
+	fg, bg, style string
+	sgr           string
+}
+
+// origin tells what colored the span
+type origin struct {
+	// kind is "format", "pattern", "word", "search", "debug" or "input"
+	kind string
+	// name of the format, pattern, word group or search
+	name string
+	// capturing group of the format or pattern (or element of JSON and logfmt)
+	group string
+	// alternative of the capturing group
+	alternative string
+}
+
+// plain returns the text as a span that isn't colored
+func plain(text string) []span {
+	if text == "" {
+		return nil
+	}
+
+	return []span{{text: text}}
+}
+
+// spansText returns the text of the spans without any colors
+func spansText(spans []span) string {
+	var text strings.Builder
+	for _, s := range spans {
+		text.WriteString(s.text)
+	}
+
+	return text.String()
+}
+
+// claim sets the kind and the name of the spans that don't have
+// an origin yet, i.e. colored by the format, pattern or word group
+// itself and not by the patterns and words found inside of it
+func claim(spans []span, kind, name string) []span {
+	for i := range spans {
+		if spans[i].kind == "" {
+			spans[i].kind, spans[i].name = kind, name
+		}
+	}
+
+	return spans
+}
+
+// claimGroup sets the capturing group and its alternative
+// of the spans that don't have an origin yet
+func claimGroup(spans []span, group, alternative string) []span {
+	for i := range spans {
+		if spans[i].kind == "" && spans[i].group == "" {
+			spans[i].group, spans[i].alternative = group, alternative
+		}
+	}
+
+	return spans
+}
+
+// colored reports whether the span has its own colors or style
+func (s span) colored() bool {
+	return len(s.under) > 0 || s.spanStyle != spanStyle{}
+}
+
+// walkUncolored replaces every run of spans that aren't colored
+// with the spans returned by f for their text. The spans stay
+// as they are if f doesn't color anything. Parts colored
+// in the input are left untouched as well.
+func walkUncolored(spans []span, f func(string) []span) []span {
+	out := make([]span, 0, len(spans))
+	for i := 0; i < len(spans); {
+		if spans[i].colored() {
+			out = append(out, spans[i])
+			i++
+
+			continue
+		}
+
+		j := i
+		for j < len(spans) && !spans[j].colored() {
+			j++
+		}
+		run := spans[i:j]
+		i = j
+
+		text := spansText(run)
+		if text == "" {
+			out = append(out, run...)
+
+			continue
+		}
+		if !strings.ContainsAny(text, "\x1b\x9b") {
+			if walked := f(text); changed(walked) {
+				out = append(out, walked...)
+			} else {
+				out = append(out, run...)
+			}
+
+			continue
+		}
+		out = append(out, walkInput(text, f)...)
+	}
+
+	return out
+}
+
+// changed reports whether any of the spans was colored or found
+func changed(spans []span) bool {
+	for _, s := range spans {
+		if s.kind != "" || s.colored() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// walkInput applies f to the parts of the text that aren't colored in the input
+func walkInput(text string, f func(string) []span) []span {
+	var out []span
+	for text != "" {
+		loc := sgrSegmentRegExp.FindStringSubmatchIndex(text)
+		if loc == nil {
+			return append(out, f(text)...)
+		}
+		if loc[0] > 0 {
+			out = append(out, f(text[:loc[0]])...)
+		}
+		out = append(out, span{
+			text:      text[loc[4]:loc[5]],
+			spanStyle: spanStyle{sgr: text[loc[2]:loc[3]]},
+			origin:    origin{kind: "input"},
+		})
+		text = text[loc[1]:]
+	}
+
+	return out
+}
@@ -0,0 +1,146 @@
+package highlighter
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/knadh/koanf/v2"
+)
+
+// tokenStyle is a color and a style of one kind of tokens
+// in structured logs (e.g. JSON strings or logfmt keys)
+type tokenStyle struct {
+	Foreground string
+	Background string
+	Style      string
+}
+
+// newTokenStyle reads a color and a style from the path in a theme
+func newTokenStyle(config *koanf.Koanf, path string) tokenStyle {
+	return tokenStyle{
+		Foreground: config.String(path + ".fg"),
+		Background: config.String(path + ".bg"),
+		Style:      config.String(path + ".style"),
+	}
+}
+
+// highlight colorizes the token of the element (e.g. "key" or "punctuation")
+func (ts tokenStyle) highlight(str, element string, h Highlighter) []span {
+	return claimGroup(h.highlight(str, ts.Foreground, ts.Background, ts.Style), element, "")
+}
+
+func (ts tokenStyle) validate(element string) error {
+	// check foreground
+	if !colorRegExp.MatchString(ts.Foreground) {
+		return fmt.Errorf(
+			"[%s] foreground color %s doesn't match %s regexp",
+			element, ts.Foreground, colorRegExp)
+	}
+
+	// check background
+	if !colorRegExp.MatchString(ts.Background) {
+		return fmt.Errorf(
+			"[%s] background color %s doesn't match %s regexp",
+			element, ts.Background, colorRegExp)
+	}
+
+	// check style
+	if !styleRegExp.MatchString(ts.Style) {
+		return fmt.Errorf(
+			"[%s] style %s doesn't match %s regexp",
+			element, ts.Style, styleRegExp)
+	}
+
+	return nil
+}
+
+// keyList is a list of keys in structured logs (JSON, logfmt)
+// whose values are colored according to their own settings.
+// The regexp of a key is matched against the whole key name,
+// its alternatives are matched against the values.
+type keyList []capGroup
+
+// init sets colors and styles of the keys from the path
+// in a theme and compiles regular expressions
+func (kl keyList) init(config *koanf.Koanf, path string) error {
+	for i, key := range kl {
+		keyPath := path + "." + key.Name
+		keyReal := &kl[i]
+
+		keyReal.Foreground = config.String(keyPath + ".fg")
+		keyReal.Background = config.String(keyPath + ".bg")
+		keyReal.Style = config.String(keyPath + ".style")
+		keyReal.Gradient.Colors = config.Strings(keyPath + ".gradient")
+
+		for j, alt := range key.Alternatives {
+			altReal := &kl[i].Alternatives[j]
+			altReal.Foreground = config.String(keyPath + "." + alt.Name + ".fg")
+			altReal.Background = config.String(keyPath + "." + alt.Name + ".bg")
+			altReal.Style = config.String(keyPath + "." + alt.Name + ".style")
+		}
+	}
+
+	if err := kl.validate(); err != nil {
+		return err
+	}
+
+	for i, key := range kl {
+		// key names have to match as a whole
+		kl[i].RegExp = regexp.MustCompile("^(?:" + key.RegExpStr[1:len(key.RegExpStr)-1] + ")$")
+		kl[i].initValues()
+	}
+
+	return nil
+}
+
+func (kl keyList) validate() error {
+	seen := make(map[string]bool, len(kl))
+	for _, key := range kl {
+		if err := key.validate(); err != nil {
+			return err
+		}
+		if seen[key.Name] {
+			return fmt.Errorf("[capturing group: %s] capturing group names must be unique", key.Name)
+		}
+		seen[key.Name] = true
+	}
+
+	return nil
+}
+
+// find returns the first key that matches the name or nil
+func (kl keyList) find(name string) *capGroup {
+	for i := range kl {
+		if kl[i].RegExp.MatchString(name) {
+			return &kl[i]
+		}
+	}
+
+	return nil
+}
+
+// highlightValue colorizes the value using settings of its key
+// and falls back to the style of the value's kind (element).
+// Timestamps of keys with "timestamp" field are converted
+// like the ones of capturing groups (see timeConverter).
+func (kl keyList) highlightValue(value string, fallback tokenStyle, element string, key *capGroup, h Highlighter) []span {
+	if key != nil {
+		h.gradient = &key.Gradient
+		shown := h.redact(value, key)
+		if h.times != nil && len(key.Timestamp) > 0 {
+			if converted, ok := h.times.convert(shown, key.Timestamp); ok {
+				shown = converted
+			}
+		}
+		if alt := key.alternative(value, ""); alt != nil {
+			return claimGroup(h.highlightRedacted(value, shown, alt.Foreground, alt.Background, alt.Style), key.Name, alt.Name)
+		}
+		if key.Foreground != "" || key.Background != "" || key.Style != "" {
+			return claimGroup(h.highlightRedacted(value, shown, key.Foreground, key.Background, key.Style), key.Name, "")
+		}
+
+		return claimGroup(h.highlightRedacted(value, shown, fallback.Foreground, fallback.Background, fallback.Style), key.Name, "")
+	}
+
+	return fallback.highlight(value, element, h)
+}
@@ -0,0 +1,265 @@
+package highlighter
+
+import (
+	"cmp"
+	"fmt"
+	"html"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/rivo/uniseg"
+)
+
+// sizes of the terminal grid of SVG picture in pixels
+const (
+	svgFontSize   = 14
+	svgCellWidth  = 8.4 // 0.6em is the width of most monospace fonts
+	svgLineHeight = 18
+	svgPadding    = 16
+	svgTabWidth   = 8
+)
+
+// svgRenderer draws all the lines as a picture of a terminal.
+// It needs all the lines at once to know the size of the picture,
+// so it keeps the spans of every line until the whole output
+// is ready (see renderAll).
+type svgRenderer struct {
+	page  pageColors
+	lines *svgLines
+}
+
+// svgLines are the spans of the rendered lines. Lines are rendered
+// by several goroutines (see --jobs flag) and put in order later,
+// so a rendered line is only a reference to its spans (see svgRef).
+type svgLines struct {
+	mu    sync.Mutex
+	spans [][]span
+}
+
+func newSVGRenderer(page pageColors) svgRenderer {
+	return svgRenderer{page: page, lines: &svgLines{}}
+}
+
+// line returns the reference to the spans. The output is made
+// only of references and line terminators, and NUL bytes that delimit
+// the references are removed from the text of the input (terminals
+// don't show them either), so nothing can be mistaken for a reference.
+func (r svgRenderer) line(spans []span) string {
+	spans = slices.Clone(spans)
+	for i := range spans {
+		spans[i].text = strings.ReplaceAll(spans[i].text, "\x00", "")
+	}
+
+	r.lines.mu.Lock()
+	defer r.lines.mu.Unlock()
+	r.lines.spans = append(r.lines.spans, spans)
+
+	return svgRef(len(r.lines.spans) - 1)
+}
+
+func (svgRenderer) document() (string, string) {
+	return "", ""
+}
+
+// svgCell is a part of a line with the same style placed on the grid
+type svgCell struct {
+	col, width int
+	text       string
+	style      textStyle
+}
+
+// svgRef returns the reference to the spans of the line number n
+func svgRef(n int) string {
+	return "\x00" + strconv.Itoa(n) + "\x00"
+}
+
+// spanLines returns the spans of every line of the output
+// made of references to the rendered lines and line terminators
+func (r svgRenderer) spanLines(output string) [][]span {
+	var lines [][]span
+	var line []span
+	for output != "" {
+		switch output[0] {
+		case '\x00':
+			ref, rest, _ := strings.Cut(output[1:], "\x00")
+			if n, err := strconv.Atoi(ref); err == nil && r.lines != nil && n >= 0 && n < len(r.lines.spans) {
+				line = append(line, r.lines.spans[n]...)
+			}
+			output = rest
+		case '\r', '\n':
+			lines = append(lines, line)
+			line = nil
+			if strings.HasPrefix(output, "\r\n") {
+				output = output[1:]
+			}
+			output = output[1:]
+		default:
+			// there is nothing else between the lines,
+			// but keep it as plain text anyway
+			end := strings.IndexAny(output, "\x00\r\n")
+			if end < 0 {
+				end = len(output)
+			}
+			line = append(line, plain(output[:end])...)
+			output = output[end:]
+		}
+	}
+	if line != nil || len(lines) == 0 {
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+func (r svgRenderer) renderAll(output string) string {
+	if r.lines != nil {
+		r.lines.mu.Lock()
+		defer r.lines.mu.Unlock()
+	}
+	lines := r.spanLines(output)
+
+	rows := make([][]svgCell, len(lines))
+	cols := 1
+	for i, spans := range lines {
+		col := 0
+		for _, s := range spans {
+			// only colors of the input are SGR sequences here
+			for _, part := range splitStyles(s.text, spanTextStyle(s)) {
+				text := expandTabs(part.text, col)
+				width := uniseg.StringWidth(text)
+				col += width
+				// neighbouring spans often look the same
+				if n := len(rows[i]); n > 0 && rows[i][n-1].style == part.style {
+					rows[i][n-1].text += text
+					rows[i][n-1].width += width
+
+					continue
+				}
+				rows[i] = append(rows[i], svgCell{col - width, width, text, part.style})
+			}
+		}
+		cols = max(cols, col)
+	}
+
+	fg := cmp.Or(r.page.Foreground, "#000000")
+	bg := cmp.Or(r.page.Background, "#ffffff")
+	width := svgNumber(2*svgPadding + float64(cols)*svgCellWidth)
+	height := svgNumber(float64(2*svgPadding + len(rows)*svgLineHeight))
+
+	var out strings.Builder
+	fmt.Fprintf(&out, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="0 0 %s %s">`+"\n",
+		width, height, width, height)
+	fmt.Fprintf(&out, `<rect width="100%%" height="100%%" rx="6" fill="%s"/>`+"\n", bg)
+	fmt.Fprintf(&out, `<g font-family="ui-monospace,Menlo,Consolas,monospace" font-size="%d" fill="%s" xml:space="preserve">`+"\n",
+		svgFontSize, fg)
+
+	for i, cells := range rows {
+		top := svgPadding + i*svgLineHeight
+		var text strings.Builder
+		for _, cell := range cells {
+			cellFg, cellBg := cell.style.fg, cell.style.bg
+			if cell.style.reverse {
+				cellFg, cellBg = cmp.Or(cellBg, bg), cmp.Or(cellFg, fg)
+			}
+			x := svgNumber(svgPadding + float64(cell.col)*svgCellWidth)
+
+			if cellBg != "" && cell.width > 0 {
+				fmt.Fprintf(&out, `<rect x="%s" y="%d" width="%s" height="%d" fill="%s"/>`+"\n",
+					x, top, svgNumber(float64(cell.width)*svgCellWidth), svgLineHeight, cellBg)
+			}
+			if strings.TrimSpace(cell.text) == "" {
+				continue
+			}
+			fmt.Fprintf(&text, `<tspan x="%s"%s>%s</tspan>`, x, svgAttributes(cellFg, cell.style), svgText(cell.text))
+		}
+
+		if text.Len() > 0 {
+			// the baseline is a bit lower than the middle of the line
+			baseline := float64(top) + (svgLineHeight+svgFontSize*0.7)/2
+			fmt.Fprintf(&out, "<text y=\"%s\">%s</text>\n", svgNumber(baseline), text.String())
+		}
+	}
+
+	out.WriteString("</g>\n</svg>\n")
+
+	return out.String()
+}
+
+// svgNumber formats the coordinate without floating point noise
+func svgNumber(f float64) string {
+	return strconv.FormatFloat(math.Round(f*100)/100, 'f', -1, 64)
+}
+
+// svgAttributes returns presentation attributes of the text with the style
+func svgAttributes(fg string, s textStyle) string {
+	var attrs strings.Builder
+	if fg != "" {
+		fmt.Fprintf(&attrs, ` fill="%s"`, fg)
+	}
+	if s.bold {
+		attrs.WriteString(` font-weight="bold"`)
+	}
+	if s.faint {
+		attrs.WriteString(` fill-opacity="0.6"`)
+	}
+	if s.italic {
+		attrs.WriteString(` font-style="italic"`)
+	}
+
+	var lines []string
+	if s.underline {
+		lines = append(lines, "underline")
+	}
+	if s.overline {
+		lines = append(lines, "overline")
+	}
+	if s.crossout {
+		lines = append(lines, "line-through")
+	}
+	if len(lines) > 0 {
+		fmt.Fprintf(&attrs, ` text-decoration="%s"`, strings.Join(lines, " "))
+	}
+
+	return attrs.String()
+}
+
+// svgText escapes the text and removes control characters
+// that aren't allowed in XML
+func svgText(text string) string {
+	text = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+
+		return r
+	}, text)
+
+	return html.EscapeString(text)
+}
+
+// expandTabs replaces tabs with spaces up to the next tab stop.
+// col is the column the text starts at.
+func expandTabs(text string, col int) string {
+	if !strings.Contains(text, "\t") {
+		return text
+	}
+
+	var out strings.Builder
+	for _, r := range text {
+		if r == '\t' {
+			spaces := svgTabWidth - col%svgTabWidth
+			out.WriteString(strings.Repeat(" ", spaces))
+			col += spaces
+
+			continue
+		}
+		out.WriteRune(r)
+		col += uniseg.StringWidth(string(r))
+	}
+
+	return out.String()
+}
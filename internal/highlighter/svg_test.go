@@ -0,0 +1,126 @@
+package highlighter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/deponian/logalize/internal/config"
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/v2"
+	"github.com/muesli/termenv"
+)
+
+func TestSVGRenderAll(t *testing.T) {
+	r := newSVGRenderer(pageColors{"#ffffff", "#000000"})
+
+	output := r.line([]span{{text: "red", spanStyle: spanStyle{fg: "#ff0000", style: "bold"}}, {text: " <plain>"}}) + "\r\n" +
+		r.line(nil) + "\n" +
+		r.line([]span{
+			{text: "rev", spanStyle: spanStyle{style: "reverse"}, under: []spanStyle{{style: "italic"}}},
+			{text: "\t"},
+			{text: "blue", spanStyle: spanStyle{bg: "21", style: "underline"}},
+		}) + "\n"
+	svg := `<svg xmlns="http://www.w3.org/2000/svg" width="132.8" height="86" viewBox="0 0 132.8 86">` + "\n" +
+		`<rect width="100%" height="100%" rx="6" fill="#000000"/>` + "\n" +
+		`<g font-family="ui-monospace,Menlo,Consolas,monospace" font-size="14" fill="#ffffff" xml:space="preserve">` + "\n" +
+		`<text y="29.9"><tspan x="16" fill="#ff0000" font-weight="bold">red</tspan><tspan x="41.2"> &lt;plain&gt;</tspan></text>` + "\n" +
+		`<rect x="16" y="52" width="25.2" height="18" fill="#ffffff"/>` + "\n" +
+		`<rect x="83.2" y="52" width="33.6" height="18" fill="#0000ff"/>` + "\n" +
+		`<text y="65.9"><tspan x="16" fill="#000000" font-style="italic">rev</tspan>` +
+		`<tspan x="83.2" text-decoration="underline">blue</tspan></text>` + "\n" +
+		"</g>\n</svg>\n"
+
+	if got := r.renderAll(output); got != svg {
+		t.Errorf("got %q, want %q", got, svg)
+	}
+
+	// colors of the input are the only SGR sequences left
+	t.Run("TestSVGRenderAllInput", func(t *testing.T) {
+		output := r.line([]span{
+			{text: "in", spanStyle: spanStyle{sgr: "\x1b[31m"}, origin: origin{kind: "input"}},
+			{text: "\x1b[1mdry\x1b[0m run"},
+		})
+		text := `<text y="29.9"><tspan x="16" fill="#800000">in</tspan>` +
+			`<tspan x="32.8" font-weight="bold">dry</tspan><tspan x="58"> run</tspan></text>`
+		if got := r.renderAll(output); !strings.Contains(got, text) {
+			t.Errorf("got %q, want it to contain %q", got, text)
+		}
+	})
+
+	// NUL bytes of the input are dropped like in the terminal
+	t.Run("TestSVGRenderAllNUL", func(t *testing.T) {
+		output := r.line(plain("a\x000\x00b")) + "\n" + r.line(plain("\x00c"))
+		text := `<text y="29.9"><tspan x="16">a0b</tspan></text>` + "\n" +
+			`<text y="47.9"><tspan x="16">c</tspan></text>`
+		if got := r.renderAll(output); !strings.Contains(got, text) {
+			t.Errorf("got %q, want it to contain %q", got, text)
+		}
+	})
+
+	t.Run("TestSVGRenderAllEmpty", func(t *testing.T) {
+		empty := `<svg xmlns="http://www.w3.org/2000/svg" width="40.4" height="50" viewBox="0 0 40.4 50">` + "\n" +
+			`<rect width="100%" height="100%" rx="6" fill="#ffffff"/>` + "\n" +
+			`<g font-family="ui-monospace,Menlo,Consolas,monospace" font-size="14" fill="#000000" xml:space="preserve">` + "\n" +
+			"</g>\n</svg>\n"
+		if got := (svgRenderer{}).renderAll(""); got != empty {
+			t.Errorf("got %q, want %q", got, empty)
+		}
+	})
+}
+
+func TestSVGExpandTabs(t *testing.T) {
+	tests := []struct {
+		text     string
+		col      int
+		expanded string
+	}{
+		{"no tabs", 0, "no tabs"},
+		{"\tone", 0, "        one"},
+		{"\tone", 3, "     one"},
+		{"ab\tc\td", 0, "ab      c       d"},
+		{"日\tx", 0, "日      x"},
+	}
+
+	for _, tt := range tests {
+		t.Run("TestSVGExpandTabs"+tt.text, func(t *testing.T) {
+			if expanded := expandTabs(tt.text, tt.col); expanded != tt.expanded {
+				t.Errorf("got %q, want %q", expanded, tt.expanded)
+			}
+		})
+	}
+}
+
+func TestSVGHighlighter(t *testing.T) {
+	cfg := koanf.New(".")
+	err := cfg.Load(file.Provider("./testdata/redact/newFormats/01_good.yaml"), yaml.Parser())
+	if err != nil {
+		t.Fatalf("cfg.Load(...) failed with this error: %s", err)
+	}
+
+	settings := config.Settings{Config: cfg, Opts: config.Options{Theme: "test", Output: "svg"}, ColorProfile: termenv.Ascii}
+	hl, err := NewHighlighter(settings)
+	if err != nil {
+		t.Fatalf("NewHighlighter() failed with this error: %s", err)
+	}
+	if !hl.Buffered() {
+		t.Fatalf("Buffered() returned false")
+	}
+
+	// lines are drawn from their spans after the whole output is collected
+	colored := hl.Colorize("10.1.2.3 bob hello") + "\n" + hl.Colorize("bob") + "\n"
+	text := `<text y="29.9"><tspan x="16" fill="#ff0000">10.1.2.3 </tspan><tspan x="91.6">bob hello</tspan></text>` + "\n" +
+		`<text y="47.9"><tspan x="16">bob</tspan></text>`
+	if svg := hl.RenderAll(colored); !strings.Contains(svg, text) {
+		t.Errorf("got %q, want it to contain %q", svg, text)
+	}
+
+	settings.Opts.Output = "html"
+	hl, err = NewHighlighter(settings)
+	if err != nil {
+		t.Fatalf("NewHighlighter() failed with this error: %s", err)
+	}
+	if hl.Buffered() || hl.RenderAll("<pre>") != "<pre>" {
+		t.Errorf("HTML output must not be buffered")
+	}
+}
@@ -0,0 +1,189 @@
+package highlighter
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/deponian/logalize/internal/config"
+)
+
+// timeBoundLayouts are the layouts of absolute times in --since and --until flags
+var timeBoundLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	time.DateTime,
+	"2006-01-02 15:04",
+	time.DateOnly,
+}
+
+// timeRange is the window of time from --since and --until flags.
+// Lines are filtered by the timestamps found by capturing groups
+// with "timestamp" field (see Record).
+type timeRange struct {
+	// zero since or until means the range isn't bounded on that side
+	since, until time.Time
+
+	// local is the time zone of timestamps without one
+	local *time.Location
+	// now is the time the relative bounds are counted from
+	now time.Time
+}
+
+// newTimeRange returns the range of --since and --until flags
+// or nil if neither of them is set
+func newTimeRange(opts config.Options, now time.Time) (*timeRange, error) {
+	if opts.Since == "" && opts.Until == "" {
+		return nil, nil
+	}
+
+	tr := &timeRange{local: time.Local, now: now}
+	var err error
+	if opts.Since != "" {
+		if tr.since, err = tr.parseBound(opts.Since); err != nil {
+			return nil, fmt.Errorf("[since] %s", err)
+		}
+	}
+	if opts.Until != "" {
+		if tr.until, err = tr.parseBound(opts.Until); err != nil {
+			return nil, fmt.Errorf("[until] %s", err)
+		}
+	}
+	if !tr.since.IsZero() && !tr.until.IsZero() && tr.since.After(tr.until) {
+		return nil, fmt.Errorf("--since %q is later than --until %q", opts.Since, opts.Until)
+	}
+
+	return tr, nil
+}
+
+// parseBound parses a duration (e.g. "15m" means 15 minutes ago)
+// or an absolute time in local time zone unless it has an offset
+func (tr *timeRange) parseBound(str string) (time.Time, error) {
+	if q, err := parseQuantity(str); err == nil && q.dimension == duration {
+		return tr.now.Add(-time.Duration(q.value * float64(time.Second))), nil
+	}
+
+	for _, layout := range timeBoundLayouts {
+		if t, err := time.ParseInLocation(layout, str, tr.local); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf(
+		"%q is neither a duration like 15m nor a time like \"2006-01-02 15:04:05\"", str)
+}
+
+// contains reports whether the time is within the range.
+// Lines of unknown time are out of any range.
+func (tr *timeRange) contains(t time.Time) bool {
+	if t.IsZero() {
+		return false
+	}
+
+	return (tr.since.IsZero() || !t.Before(tr.since)) && (tr.until.IsZero() || !t.After(tr.until))
+}
+
+// lineTime returns the time of the line. The timestamp of the format
+// of the line (or of its JSON key) is used if the line has a format
+// and the format has one. Otherwise it's the first timestamp found by patterns.
+func (h Highlighter) lineTime(line string, lf *format) (time.Time, bool) {
+	if lf != nil {
+		if t, ok := lf.parseTime(line, h.timeRange); ok {
+			return t, true
+		}
+	}
+
+	var t time.Time
+	found := -1
+	for _, p := range h.patterns {
+		if p.CapGroups == nil || !p.CapGroups.hasTimestamp() {
+			continue
+		}
+		loc := p.CapGroups.fullRegExp.FindStringIndex(line)
+		if loc == nil || (found >= 0 && loc[0] >= found) {
+			continue
+		}
+		if pt, ok := p.CapGroups.parseTime(line[loc[0]:loc[1]], h.timeRange); ok {
+			t, found = pt, loc[0]
+		}
+	}
+
+	return t, found >= 0
+}
+
+// hasTimestamp reports whether any of the groups has "timestamp" field
+func (cgl *capGroupList) hasTimestamp() bool {
+	for _, cg := range cgl.groups {
+		if len(cg.Timestamp) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseTime parses the timestamp of the line of the format
+func (lf format) parseTime(str string, tr *timeRange) (time.Time, bool) {
+	if lf.JSON != nil {
+		return lf.JSON.parseTime(str, tr)
+	}
+
+	return lf.CapGroups.parseTime(str, tr)
+}
+
+// parseTime parses the value of the first key with "timestamp" field
+// that the object has and that can be parsed
+func (jf *jsonFormat) parseTime(str string, tr *timeRange) (time.Time, bool) {
+	for i := range jf.Keys {
+		key := &jf.Keys[i]
+		if len(key.Timestamp) == 0 {
+			continue
+		}
+		value, ok := jf.value(str, key)
+		if !ok {
+			continue
+		}
+		if t, _, ok := parseTimestamp(value, key.Timestamp, tr.local, tr.now); ok {
+			return t, true
+		}
+		if t, ok := epochTime(value); ok {
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// epochTime parses a JSON number as seconds or milliseconds since
+// the Unix epoch (e.g. "ts" of zap). Numbers from 1e11 on are taken
+// as milliseconds, 1e11 seconds is more than 3000 years from now.
+func epochTime(value string) (time.Time, bool) {
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil || seconds < 0 {
+		return time.Time{}, false
+	}
+	if seconds >= 1e11 {
+		seconds /= 1000
+	}
+	// microseconds are enough, float64 isn't precise beyond them
+	sec, frac := math.Modf(seconds)
+
+	return time.Unix(int64(sec), int64(math.Round(frac*1e6))*1e3), true
+}
+
+// parseTime parses the timestamp of the groups in the string matched by the list
+func (cgl *capGroupList) parseTime(str string, tr *timeRange) (time.Time, bool) {
+	matches := cgl.fullRegExp.FindStringSubmatch(str)
+	if matches == nil {
+		return time.Time{}, false
+	}
+
+	text, layouts, indexes := timestamp(cgl.groups, cgl.texts(matches))
+	if len(indexes) == 0 {
+		return time.Time{}, false
+	}
+	t, _, ok := parseTimestamp(text, layouts, tr.local, tr.now)
+
+	return t, ok
+}
@@ -0,0 +1,225 @@
+package highlighter
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/deponian/logalize/internal/config"
+)
+
+func TestTimeRangeNew(t *testing.T) {
+	now := time.Date(2024, 2, 17, 6, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		opts         config.Options
+		isNil        bool
+		since, until time.Time
+		err          string
+	}{
+		{config.Options{}, true, time.Time{}, time.Time{}, "%!s(<nil>)"},
+		{config.Options{Since: "15m"}, false, now.Add(-15 * time.Minute), time.Time{}, "%!s(<nil>)"},
+		{config.Options{Until: "1.5h"}, false, time.Time{}, now.Add(-90 * time.Minute), "%!s(<nil>)"},
+		{config.Options{Since: "1h30m"}, false, now.Add(-90 * time.Minute), time.Time{}, "%!s(<nil>)"},
+		{config.Options{Since: "1d", Until: "1s"}, false, now.AddDate(0, 0, -1), now.Add(-time.Second), "%!s(<nil>)"},
+		{
+			config.Options{Since: "2024-02-17T05:00:00+01:00", Until: "2024-02-17T05:00:00.5Z"}, false,
+			time.Date(2024, 2, 17, 4, 0, 0, 0, time.UTC), time.Date(2024, 2, 17, 5, 0, 0, 5e8, time.UTC),
+			"%!s(<nil>)",
+		},
+		{
+			config.Options{Since: "2024-02-17 05:00", Until: "2024-02-18"}, false,
+			time.Date(2024, 2, 17, 5, 0, 0, 0, time.Local), time.Date(2024, 2, 18, 0, 0, 0, 0, time.Local),
+			"%!s(<nil>)",
+		},
+		{config.Options{Since: "15"}, true, time.Time{}, time.Time{}, `[since] "15" is neither a duration like 15m nor a time like "2006-01-02 15:04:05"`},
+		{config.Options{Until: "5MB"}, true, time.Time{}, time.Time{}, `[until] "5MB" is neither a duration like 15m nor a time like "2006-01-02 15:04:05"`},
+		{config.Options{Since: "1m", Until: "1h"}, true, time.Time{}, time.Time{}, `--since "1m" is later than --until "1h"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("TestTimeRangeNew%s-%s", tt.opts.Since, tt.opts.Until), func(t *testing.T) {
+			tr, err := newTimeRange(tt.opts, now)
+			if e := fmt.Sprintf("%s", err); e != tt.err {
+				t.Errorf("got %s, want %s", e, tt.err)
+			}
+			if (tr == nil) != tt.isNil {
+				t.Fatalf("got %v, want nil: %v", tr, tt.isNil)
+			}
+			if tr != nil && (!tr.since.Equal(tt.since) || !tr.until.Equal(tt.until)) {
+				t.Errorf("got %s and %s, want %s and %s", tr.since, tr.until, tt.since, tt.until)
+			}
+		})
+	}
+}
+
+func TestTimeRangeContains(t *testing.T) {
+	since := time.Date(2024, 2, 17, 6, 0, 0, 0, time.UTC)
+	until := time.Date(2024, 2, 17, 7, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		tr       timeRange
+		t        time.Time
+		contains bool
+	}{
+		{timeRange{since: since}, since, true},
+		{timeRange{since: since}, since.Add(-time.Nanosecond), false},
+		{timeRange{since: since}, until.AddDate(1, 0, 0), true},
+		{timeRange{until: until}, until, true},
+		{timeRange{until: until}, until.Add(time.Nanosecond), false},
+		{timeRange{since: since, until: until}, since.Add(time.Minute), true},
+		{timeRange{since: since, until: until}, until.Add(time.Minute), false},
+		// lines of unknown time are never shown
+		{timeRange{until: until}, time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("TestTimeRangeContains%s", tt.t), func(t *testing.T) {
+			if contains := tt.tr.contains(tt.t); contains != tt.contains {
+				t.Errorf("got %v, want %v", contains, tt.contains)
+			}
+		})
+	}
+}
+
+func TestTimeRangeLineTime(t *testing.T) {
+	tests := []struct {
+		line string
+		t    time.Time
+		ok   bool
+	}{
+		{"Feb 17 05:56:10 message", time.Date(2024, 2, 17, 5, 56, 10, 0, time.UTC), true},
+		// the timestamp of the format is more important than patterns
+		{"Feb 17 05:56:10 at [2024-01-01 00:00:00]", time.Date(2024, 2, 17, 5, 56, 10, 0, time.UTC), true},
+		// syslog-like timestamps from the future are from the last year
+		{"Dec 31 23:00:00.123 message", time.Date(2023, 12, 31, 23, 0, 0, 123e6, time.UTC), true},
+		{"at [2024-01-01 00:00:00] or [2023-01-01 00:00:00]", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), true},
+		{"Feb 31 05:56:10 at [2023-01-01 00:00:00]", time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), true},
+		{"no timestamp", time.Time{}, false},
+	}
+
+	hl, err := newTestHighlighter(t, "./testdata/timestamp/newFormats/01_good.yaml", config.Options{Since: "1h"})
+	if err != nil {
+		t.Fatalf("NewHighlighter() failed with this error: %s", err)
+	}
+	hl.timeRange.local = time.UTC
+	hl.timeRange.now = time.Date(2024, 2, 17, 6, 0, 0, 0, time.UTC)
+
+	for _, tt := range tests {
+		t.Run("TestTimeRangeLineTime"+tt.line, func(t *testing.T) {
+			record := hl.NewRecordTracker().Track(tt.line)
+			lineTime, ok := record.time, !record.time.IsZero()
+			if ok != tt.ok || !lineTime.Equal(tt.t) {
+				t.Errorf("got %s and %v, want %s and %v", lineTime, ok, tt.t, tt.ok)
+			}
+		})
+	}
+}
+
+func TestTimeRangeWanted(t *testing.T) {
+	lines := []struct {
+		line   string
+		wanted bool
+	}{
+		// lines before the first timestamp don't have time
+		{"header", false},
+		{"Feb 17 05:00:00 too early", false},
+		{"inherits time of the previous line", false},
+		{"Feb 17 05:30:00 in range", true},
+		{"inherits time of the previous line", true},
+		{"at [2024-02-17 05:40:00]", true},
+		{"Feb 17 06:00:00.001 too late", false},
+		{"inherits time of the previous line", false},
+	}
+
+	hl, err := newTestHighlighter(t, "./testdata/timestamp/newFormats/01_good.yaml", config.Options{Since: "2024-02-17T05:30:00Z", Until: "2024-02-17T05:50:00Z", DryRun: true})
+	if err != nil {
+		t.Fatalf("NewHighlighter() failed with this error: %s", err)
+	}
+	hl.timeRange.local = time.UTC
+	hl.timeRange.now = time.Date(2024, 2, 17, 6, 0, 0, 0, time.UTC)
+	if !hl.Filtering() {
+		t.Fatalf("Filtering() returned false")
+	}
+
+	tracker := hl.NewRecordTracker()
+	for _, tt := range lines {
+		record := tracker.Track(tt.line)
+		t.Run("TestTimeRangeWanted"+tt.line, func(t *testing.T) {
+			_, match := hl.ColorizeMatch(tt.line, record)
+			if wanted := hl.Wanted(match); wanted != tt.wanted {
+				t.Errorf("Wanted(%v) returned %t, want %t", match, wanted, tt.wanted)
+			}
+		})
+	}
+}
+
+func TestTimeRangeLineTimeJSON(t *testing.T) {
+	tests := []struct {
+		line string
+		t    time.Time
+		ok   bool
+	}{
+		{`{"time":"2024-02-17T05:56:10Z","msg":"message"}`, time.Date(2024, 2, 17, 5, 56, 10, 0, time.UTC), true},
+		{`{"msg":"message", "ts": "2024-02-17 05:56:10"}`, time.Date(2024, 2, 17, 5, 56, 10, 0, time.UTC), true},
+		// the timestamp of the key is more important than patterns
+		{`{"time":"2024-02-17T05:56:10+01:00","msg":"at [2024-01-01 00:00:00]"}`, time.Date(2024, 2, 17, 4, 56, 10, 0, time.UTC), true},
+		// values that aren't timestamps are skipped
+		{`{"time":"yesterday","msg":"at [2024-01-01 00:00:00]"}`, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), true},
+		// numbers are seconds or milliseconds since the Unix epoch
+		{`{"time":1708149370,"msg":"at [2024-01-01 00:00:00]"}`, time.Date(2024, 2, 17, 5, 56, 10, 0, time.UTC), true},
+		{`{"ts":1708149370.123456,"msg":"message"}`, time.Date(2024, 2, 17, 5, 56, 10, 123456e3, time.UTC), true},
+		{`{"ts":1708149370123,"msg":"message"}`, time.Date(2024, 2, 17, 5, 56, 10, 123e6, time.UTC), true},
+		{`{"time":-1,"msg":"at [2024-01-01 00:00:00]"}`, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), true},
+		{`{"nested":{"time":"2024-02-17T05:56:10Z"}}`, time.Time{}, false},
+		{`{"msg":"no timestamp"}`, time.Time{}, false},
+	}
+
+	hl, err := newTestHighlighter(t, "./testdata/timerange/lineTime/01_json.yaml", config.Options{Since: "1h"})
+	if err != nil {
+		t.Fatalf("NewHighlighter() failed with this error: %s", err)
+	}
+	hl.timeRange.local = time.UTC
+	hl.timeRange.now = time.Date(2024, 2, 17, 6, 0, 0, 0, time.UTC)
+
+	for _, tt := range tests {
+		t.Run("TestTimeRangeLineTimeJSON"+tt.line, func(t *testing.T) {
+			record := hl.NewRecordTracker().Track(tt.line)
+			lineTime, ok := record.time, !record.time.IsZero()
+			if ok != tt.ok || !lineTime.Equal(tt.t) {
+				t.Errorf("got %s and %v, want %s and %v", lineTime, ok, tt.t, tt.ok)
+			}
+		})
+	}
+}
+
+func TestTimeRangeWantedJSON(t *testing.T) {
+	lines := []struct {
+		line   string
+		wanted bool
+	}{
+		{`{"time":"2024-02-17T05:00:00Z","msg":"too early"}`, false},
+		{`{"time":"2024-02-17T05:30:00Z","msg":"in range"}`, true},
+		// lines without a timestamp follow the record they are in
+		{"\tat main.go:12", true},
+		{`{"msg":"inherits time of the previous line"}`, true},
+		{`{"time":"2024-02-17T06:00:00Z","msg":"too late"}`, false},
+		{"\tat main.go:12", false},
+	}
+
+	hl, err := newTestHighlighter(t, "./testdata/timerange/lineTime/01_json.yaml", config.Options{Since: "2024-02-17T05:30:00Z", Until: "2024-02-17T05:50:00Z", DryRun: true})
+	if err != nil {
+		t.Fatalf("NewHighlighter() failed with this error: %s", err)
+	}
+
+	tracker := hl.NewRecordTracker()
+	for _, tt := range lines {
+		record := tracker.Track(tt.line)
+		t.Run("TestTimeRangeWantedJSON"+tt.line, func(t *testing.T) {
+			_, match := hl.ColorizeMatch(tt.line, record)
+			if wanted := hl.Wanted(match); wanted != tt.wanted {
+				t.Errorf("Wanted(%v) returned %t, want %t", match, wanted, tt.wanted)
+			}
+		})
+	}
+}
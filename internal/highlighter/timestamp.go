@@ -0,0 +1,231 @@
+package highlighter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/deponian/logalize/internal/config"
+)
+
+// timestampCutset is trimmed from both the matched text and the layout
+// of a timestamp, so brackets, quotes and spaces around it are preserved
+const timestampCutset = " \t[]()\"'"
+
+// timeFormats are the names that can be used in --time-format flag
+var timeFormats = map[string]string{
+	"rfc3339":     time.RFC3339,
+	"rfc3339nano": time.RFC3339Nano,
+	"rfc1123":     time.RFC1123Z,
+	"datetime":    time.DateTime,
+	"stamp":       time.StampMilli,
+	"kitchen":     time.Kitchen,
+}
+
+// timeConverter rewrites timestamps found by capturing groups
+// with "timestamp" field into another time zone and/or layout
+type timeConverter struct {
+	// nil keeps the time zone of the timestamp
+	location *time.Location
+	// empty layout keeps the layout of the timestamp
+	layout string
+	// relative renders timestamps like "3m12s ago"
+	relative bool
+
+	// local is the time zone of timestamps without one
+	local *time.Location
+	now   func() time.Time
+}
+
+// newTimeConverter returns a converter configured by --tz and --time-format
+// flags. It returns nil if neither of them is set, so timestamps are left as is.
+func newTimeConverter(opts config.Options) (*timeConverter, error) {
+	if opts.TZ == "" && opts.TimeFormat == "" {
+		return nil, nil
+	}
+
+	tc := &timeConverter{local: time.Local, now: time.Now}
+	if opts.TZ != "" {
+		location, err := time.LoadLocation(opts.TZ)
+		if err != nil {
+			return nil, fmt.Errorf("[tz: %s] %s", opts.TZ, err)
+		}
+		tc.location = location
+	}
+
+	name := strings.ToLower(opts.TimeFormat)
+	if name == "relative" {
+		tc.relative = true
+	} else if layout, ok := timeFormats[name]; ok {
+		tc.layout = layout
+	} else {
+		tc.layout = opts.TimeFormat
+	}
+
+	return tc, nil
+}
+
+// rewrite replaces the timestamp made of the texts of all the groups
+// with "timestamp" field with the converted one. The converted timestamp
+// goes to the first of these groups and the rest become empty. Texts are
+// left untouched if the groups don't have a timestamp or it can't be parsed.
+func (tc *timeConverter) rewrite(groups []capGroup, texts []string) {
+	text, layouts, indexes := timestamp(groups, texts)
+	if len(indexes) == 0 {
+		return
+	}
+
+	converted, ok := tc.convert(text, layouts)
+	if !ok {
+		return
+	}
+	for _, i := range indexes {
+		texts[i] = ""
+	}
+	texts[indexes[0]] = converted
+}
+
+// convert parses the text with the first suitable layout and formats it again.
+// Dates without a time are left as they are, they don't have a time
+// to convert to another time zone.
+func (tc *timeConverter) convert(text string, layouts []string) (string, bool) {
+	trimmed := strings.TrimLeft(text, timestampCutset)
+	prefix := text[:len(text)-len(trimmed)]
+	trimmed = strings.TrimRight(trimmed, timestampCutset)
+	suffix := text[len(prefix)+len(trimmed):]
+
+	t, layout, ok := parseTimestamp(trimmed, layouts, tc.local, tc.now())
+	if !ok || withoutHour(layout) {
+		return "", false
+	}
+
+	return prefix + tc.format(t, layout) + suffix, true
+}
+
+func (tc *timeConverter) format(t time.Time, layout string) string {
+	if tc.relative {
+		return relativeTime(tc.now().Sub(t))
+	}
+	if tc.location != nil {
+		t = t.In(tc.location)
+	}
+	if tc.layout != "" {
+		layout = tc.layout
+	}
+
+	return t.Format(layout)
+}
+
+// relativeTime renders the duration since the timestamp
+// with at most two units like "3m12s ago" or "in 5s"
+func relativeTime(d time.Duration) string {
+	d = d.Round(time.Second)
+	future := d < 0
+	if future {
+		d = -d
+	}
+
+	units := []struct {
+		name string
+		size time.Duration
+	}{
+		{"d", 24 * time.Hour},
+		{"h", time.Hour},
+		{"m", time.Minute},
+		{"s", time.Second},
+	}
+
+	var str string
+	for i, unit := range units {
+		if d < unit.size && i < len(units)-1 {
+			continue
+		}
+		str = fmt.Sprintf("%d%s", d/unit.size, unit.name)
+		if i < len(units)-1 {
+			if next := d % unit.size / units[i+1].size; next > 0 {
+				str += fmt.Sprintf("%d%s", next, units[i+1].name)
+			}
+		}
+
+		break
+	}
+
+	if future {
+		return "in " + str
+	}
+
+	return str + " ago"
+}
+
+// timestamp returns the text of the timestamp made of the texts of all the
+// groups with "timestamp" field, its possible layouts and indexes of the groups
+func timestamp(groups []capGroup, texts []string) (text string, layouts []string, indexes []int) {
+	layouts = []string{""}
+	for i, cg := range groups {
+		if len(cg.Timestamp) == 0 {
+			continue
+		}
+		indexes = append(indexes, i)
+		text += texts[i]
+
+		// every combination of layouts of the groups is a possible layout
+		var combined []string
+		for _, layout := range layouts {
+			for _, next := range cg.Timestamp {
+				combined = append(combined, layout+next)
+			}
+		}
+		layouts = combined
+	}
+
+	return text, layouts, indexes
+}
+
+// parseTimestamp parses the text with the first suitable layout and returns
+// the time and the layout. Brackets, quotes and spaces around the text and
+// the layouts are ignored. Timestamps without a time zone are taken in the
+// local location. Timestamps without a year (like in syslog) get the year
+// of now or the previous one if they would be in the future otherwise.
+// Timestamps without a month (like "Mon 17") get the month of now
+// or the previous one in the same way.
+func parseTimestamp(text string, layouts []string, local *time.Location, now time.Time) (time.Time, string, bool) {
+	text = strings.Trim(text, timestampCutset)
+	for _, layout := range layouts {
+		layout = strings.Trim(layout, timestampCutset)
+		t, err := time.ParseInLocation(layout, text, local)
+		if err != nil {
+			continue
+		}
+
+		if t.Year() == 0 && withoutMonth(layout) {
+			month := now.Month()
+			day := time.Date(now.Year(), month, t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+			// the day is in the previous month if this one doesn't have it yet
+			if day.Day() != t.Day() || day.Sub(now) > 24*time.Hour {
+				month--
+			}
+			t = time.Date(now.Year(), month, t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+		} else if t.Year() == 0 {
+			t = t.AddDate(now.Year(), 0, 0)
+			if t.Sub(now) > 24*time.Hour {
+				t = t.AddDate(-1, 0, 0)
+			}
+		}
+
+		return t, layout, true
+	}
+
+	return time.Time{}, "", false
+}
+
+// withoutHour reports whether the layout has neither a 24-hour
+// nor a 12-hour clock hour ("15", "3" and "03")
+func withoutHour(layout string) bool {
+	return !strings.Contains(layout, "15") && !strings.Contains(layout, "3")
+}
+
+// withoutMonth reports whether the layout has neither a month name
+// nor a month number ("15" is the only other element with "1" in it)
+func withoutMonth(layout string) bool {
+	return !strings.Contains(layout, "Jan") && !strings.Contains(strings.ReplaceAll(layout, "15", ""), "1")
+}
@@ -0,0 +1,311 @@
+package highlighter
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/deponian/logalize/internal/config"
+	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/knadh/koanf/v2"
+	"github.com/muesli/termenv"
+)
+
+func TestTimestampNewTimeConverter(t *testing.T) {
+	tests := []struct {
+		opts     config.Options
+		isNil    bool
+		layout   string
+		relative bool
+		err      string
+	}{
+		{config.Options{}, true, "", false, "%!s(<nil>)"},
+		{config.Options{TZ: "UTC"}, false, "", false, "%!s(<nil>)"},
+		{config.Options{TimeFormat: "RFC3339"}, false, time.RFC3339, false, "%!s(<nil>)"},
+		{config.Options{TimeFormat: "stamp"}, false, time.StampMilli, false, "%!s(<nil>)"},
+		{config.Options{TimeFormat: "relative"}, false, "", true, "%!s(<nil>)"},
+		{config.Options{TimeFormat: "15:04"}, false, "15:04", false, "%!s(<nil>)"},
+		{config.Options{TZ: "Nowhere/Nothing"}, true, "", false, "[tz: Nowhere/Nothing] unknown time zone Nowhere/Nothing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("TestTimestampNewTimeConverter%s%s", tt.opts.TZ, tt.opts.TimeFormat), func(t *testing.T) {
+			tc, err := newTimeConverter(tt.opts)
+			if e := fmt.Sprintf("%s", err); e != tt.err {
+				t.Errorf("got %s, want %s", e, tt.err)
+			}
+			if (tc == nil) != tt.isNil {
+				t.Fatalf("got %v, want nil: %v", tc, tt.isNil)
+			}
+			if tc != nil && (tc.layout != tt.layout || tc.relative != tt.relative) {
+				t.Errorf("got %q and %v, want %q and %v", tc.layout, tc.relative, tt.layout, tt.relative)
+			}
+		})
+	}
+}
+
+func TestTimestampConvert(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("time.LoadLocation(...) failed with this error: %s", err)
+	}
+	now := func() time.Time { return time.Date(2024, 2, 17, 6, 0, 0, 0, time.UTC) }
+
+	utc := &timeConverter{location: time.UTC, local: time.UTC, now: now}
+	rfc := &timeConverter{location: berlin, layout: time.RFC3339, local: time.UTC, now: now}
+	relative := &timeConverter{relative: true, local: time.UTC, now: now}
+
+	nginx := []string{"[02/Jan/2006:15:04:05 -0700] "}
+	syslog := []string{"Jan _2 15:04:05 "}
+	rfc3339 := []string{"2006-01-02T15:04:05.999999999Z07:00", "2006-01-02t15:04:05.999999999Z07:00"}
+
+	tests := []struct {
+		tc        *timeConverter
+		text      string
+		layouts   []string
+		converted string
+	}{
+		{utc, "[17/Feb/2024:06:56:10 +0100] ", nginx, "[17/Feb/2024:05:56:10 +0000] "},
+		{rfc, "[17/Feb/2024:06:56:10 +0000] ", nginx, "[2024-02-17T07:56:10+01:00] "},
+		{relative, "[17/Feb/2024:06:56:10 +0100] ", nginx, "[3m50s ago] "},
+		{utc, "Feb 17 05:00:00 ", syslog, "Feb 17 05:00:00 "},
+		{rfc, "Feb 17 05:00:00 ", syslog, "2024-02-17T06:00:00+01:00 "},
+		// syslog timestamps from the future are from the last year
+		{rfc, "Dec 31 23:00:00 ", syslog, "2024-01-01T00:00:00+01:00 "},
+		{rfc, "Feb  3 05:00:00 ", syslog, "2024-02-03T06:00:00+01:00 "},
+		{relative, "Feb 17 06:00:05 ", syslog, "in 5s "},
+		{utc, "2024-02-17T06:56:10.636960544+01:00", rfc3339, "2024-02-17T05:56:10.636960544Z"},
+		{utc, "2024-02-17t06:56:10Z", rfc3339, "2024-02-17t06:56:10Z"},
+		{relative, "\"2024-02-15T04:00:00Z\"", rfc3339, "\"2d2h ago\""},
+	}
+
+	for _, tt := range tests {
+		t.Run("TestTimestampConvert"+tt.text, func(t *testing.T) {
+			converted, ok := tt.tc.convert(tt.text, tt.layouts)
+			if !ok {
+				t.Fatalf("convert(%q) failed", tt.text)
+			}
+			if converted != tt.converted {
+				t.Errorf("got %q, want %q", converted, tt.converted)
+			}
+		})
+	}
+
+	t.Run("TestTimestampConvertBad", func(t *testing.T) {
+		if converted, ok := utc.convert("[yesterday] ", nginx); ok {
+			t.Errorf("convert(...) should have failed, got %q", converted)
+		}
+	})
+
+	// dates without a time don't have a time zone to convert
+	for _, tc := range []*timeConverter{utc, rfc, relative} {
+		t.Run("TestTimestampConvertDate", func(t *testing.T) {
+			if converted, ok := tc.convert("2024-02-16", []string{"2006-01-02"}); ok {
+				t.Errorf("convert(...) should have failed, got %q", converted)
+			}
+		})
+	}
+}
+
+func TestTimestampRelativeTime(t *testing.T) {
+	tests := []struct {
+		d        time.Duration
+		relative string
+	}{
+		{0, "0s ago"},
+		{400 * time.Millisecond, "0s ago"},
+		{-400 * time.Millisecond, "0s ago"},
+		{5 * time.Second, "5s ago"},
+		{-5 * time.Second, "in 5s"},
+		{3*time.Minute + 12*time.Second, "3m12s ago"},
+		{2 * time.Hour, "2h ago"},
+		{2*time.Hour + 3*time.Minute + 4*time.Second, "2h3m ago"},
+		{26 * time.Hour, "1d2h ago"},
+		{-(49*time.Hour + 30*time.Minute), "in 2d1h"},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("TestTimestampRelativeTime%s", tt.d), func(t *testing.T) {
+			if relative := relativeTime(tt.d); relative != tt.relative {
+				t.Errorf("got %q, want %q", relative, tt.relative)
+			}
+		})
+	}
+}
+
+func TestTimestampHighlight(t *testing.T) {
+	tests := []struct {
+		opts    config.Options
+		plain   string
+		colored string
+	}{
+		{
+			config.Options{},
+			"Feb 17 06:56:10.123 at [2024-02-17 06:56:10]",
+			"\x1b[38;2;255;0;0mFeb 17 \x1b[0m\x1b[38;2;0;255;0m06:56:10.123 \x1b[0m" +
+				"at \x1b[38;2;0;0;255m[2024-02-17 \x1b[0m\x1b[38;2;255;0;255m06:56:10]\x1b[0m",
+		},
+		{
+			config.Options{TZ: "Europe/Berlin", DryRun: true},
+			"Feb 17 06:56:10.123 at [2024-02-17 06:56:10]",
+			"Feb 17 06:56:10.123 at [2024-02-17 06:56:10]",
+		},
+		{
+			config.Options{TZ: "Europe/Berlin"},
+			"Feb 17 06:56:10.123 at [2024-02-17 06:56:10]",
+			"\x1b[38;2;255;0;0mFeb 17 07:56:10.123 \x1b[0m" +
+				"at \x1b[38;2;0;0;255m[2024-02-17 07:56:10]\x1b[0m",
+		},
+		{
+			config.Options{TimeFormat: "kitchen"},
+			"Feb 17 18:56:10 at [2024-02-17 06:56:10]",
+			"\x1b[38;2;255;0;0m6:56PM \x1b[0m" +
+				"at \x1b[38;2;0;0;255m[6:56AM]\x1b[0m",
+		},
+		{
+			config.Options{TimeFormat: "relative"},
+			"Feb 17 06:00:00 at [2024-02-17 05:59:00]",
+			"\x1b[38;2;255;0;0m0s ago \x1b[0m" +
+				"at \x1b[38;2;0;0;255m[1m ago]\x1b[0m",
+		},
+		{
+			// timestamps that can't be parsed are left as is
+			config.Options{TZ: "Europe/Berlin"},
+			"Feb 31 06:56:10 at [2024-02-17 06:56:10]",
+			"\x1b[38;2;255;0;0mFeb 31 \x1b[0m\x1b[38;2;0;255;0m06:56:10 \x1b[0m" +
+				"at \x1b[38;2;0;0;255m[2024-02-17 07:56:10]\x1b[0m",
+		},
+	}
+
+	cfg := koanf.New(".")
+	err := cfg.Load(file.Provider("./testdata/timestamp/newFormats/01_good.yaml"), yaml.Parser())
+	if err != nil {
+		t.Fatalf("cfg.Load(...) failed with this error: %s", err)
+	}
+
+	for _, tt := range tests {
+		tt.opts.Theme = "test"
+		settings := config.Settings{Config: cfg, Opts: tt.opts, ColorProfile: termenv.TrueColor}
+		hl, err := NewHighlighter(settings)
+		if err != nil {
+			t.Fatalf("NewHighlighter() failed with this error: %s", err)
+		}
+		if hl.times != nil {
+			hl.times.local = time.UTC
+			hl.times.now = func() time.Time { return time.Date(2024, 2, 17, 6, 0, 0, 0, time.UTC) }
+		}
+
+		t.Run(fmt.Sprintf("TestTimestampHighlight%s%s", tt.opts.TZ, tt.opts.TimeFormat), func(t *testing.T) {
+			if colored := hl.Colorize(tt.plain); colored != tt.colored {
+				t.Errorf("got %q, want %q", colored, tt.colored)
+			}
+		})
+	}
+}
+
+func TestTimestampNewFormatsBad(t *testing.T) {
+	for _, path := range []string{
+		"./testdata/timestamp/newFormats/02_alternative.yaml",
+		"./testdata/timestamp/newFormats/03_empty_layout.yaml",
+	} {
+		cfg := koanf.New(".")
+		err := cfg.Load(file.Provider(path), yaml.Parser())
+		if err != nil {
+			t.Fatalf("cfg.Load(...) failed with this error: %s", err)
+		}
+
+		t.Run("TestTimestampNewFormatsBad"+path, func(t *testing.T) {
+			if _, err := newFormats(cfg, "test"); err == nil {
+				t.Errorf("newFormats() should have failed")
+			}
+		})
+	}
+}
+
+func TestTimestampBuiltins(t *testing.T) {
+	tests := []struct {
+		plain     string
+		converted string
+		t         time.Time
+	}{
+		// klog
+		{
+			"I0217 06:56:10.123456       1 controller.go:175] started",
+			"I2024-02-17T06:56:10Z       1 controller.go:175] started",
+			time.Date(2024, 2, 17, 6, 56, 10, 123456e3, time.UTC),
+		},
+		// klog timestamps from the future are from the last year
+		{
+			"E1231 23:00:00.000000       1 controller.go:175] failed",
+			"E2023-12-31T23:00:00Z       1 controller.go:175] failed",
+			time.Date(2023, 12, 31, 23, 0, 0, 0, time.UTC),
+		},
+		// dates without a time aren't converted,
+		// but they are at midnight for --since and --until
+		// date-1
+		{"backup of 2024-02-16 is done", "backup of 2024-02-16 is done", time.Date(2024, 2, 16, 0, 0, 0, 0, time.UTC)},
+		{"backup of 02/16/2024 is done", "backup of 02/16/2024 is done", time.Date(2024, 2, 16, 0, 0, 0, 0, time.UTC)},
+		// date-2
+		{"paid on 16 February", "paid on 16 February", time.Date(2024, 2, 16, 0, 0, 0, 0, time.UTC)},
+		{"paid on 16-Feb-2023", "paid on 16-Feb-2023", time.Date(2023, 2, 16, 0, 0, 0, 0, time.UTC)},
+		// date-3
+		{"paid on Feb 16", "paid on Feb 16", time.Date(2024, 2, 16, 0, 0, 0, 0, time.UTC)},
+		{"paid on August/27/2023", "paid on August/27/2023", time.Date(2023, 8, 27, 0, 0, 0, 0, time.UTC)},
+		// date-4 timestamps get the month of now or the previous one
+		{"paid on Fri 16", "paid on Fri 16", time.Date(2024, 2, 16, 0, 0, 0, 0, time.UTC)},
+		{"paid on Wednesday 31", "paid on Wednesday 31", time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)},
+		// json
+		{
+			`{"time":"2024-02-17T06:56:10.5+01:00","msg":"started"}`,
+			`{"time":"2024-02-17T05:56:10Z","msg":"started"}`,
+			time.Date(2024, 2, 17, 5, 56, 10, 5e8, time.UTC),
+		},
+		{
+			`{"level":"info","ts":"2024-02-17T06:56:10.123+0100","msg":"started"}`,
+			`{"level":"info","ts":"2024-02-17T05:56:10Z","msg":"started"}`,
+			time.Date(2024, 2, 17, 5, 56, 10, 123e6, time.UTC),
+		},
+		{
+			`{"timestamp":"2024-02-17 06:56:10","msg":"started"}`,
+			`{"timestamp":"2024-02-17T06:56:10Z","msg":"started"}`,
+			time.Date(2024, 2, 17, 6, 56, 10, 0, time.UTC),
+		},
+	}
+
+	cfg := koanf.New(".")
+	err := cfg.Load(file.Provider("./testdata/formats/builtins/theme.yaml"), yaml.Parser())
+	if err != nil {
+		t.Fatalf("cfg.Load(...) failed with this error: %s", err)
+	}
+
+	settings, err := config.NewSettings(builtins, cfg, nil, true)
+	if err != nil {
+		t.Fatalf("config.NewSettings(...) failed with this error: %s", err)
+	}
+	settings.Opts.TZ = "UTC"
+	settings.Opts.TimeFormat = "rfc3339"
+	settings.Opts.Since = "1h"
+	settings.ColorProfile = termenv.Ascii
+
+	hl, err := NewHighlighter(settings)
+	if err != nil {
+		t.Fatalf("NewHighlighter() failed with this error: %s", err)
+	}
+	now := time.Date(2024, 2, 17, 6, 0, 0, 0, time.UTC)
+	hl.times.local = time.UTC
+	hl.times.now = func() time.Time { return now }
+	hl.timeRange.local = time.UTC
+	hl.timeRange.now = now
+
+	for _, tt := range tests {
+		t.Run("TestTimestampBuiltins"+tt.plain, func(t *testing.T) {
+			if converted := hl.Colorize(tt.plain); converted != tt.converted {
+				t.Errorf("got %q, want %q", converted, tt.converted)
+			}
+			if lineTime := hl.NewRecordTracker().Track(tt.plain).time; !lineTime.Equal(tt.t) {
+				t.Errorf("got %s, want %s", lineTime, tt.t)
+			}
+		})
+	}
+}
@@ -69,94 +69,112 @@ func newWords(config *koanf.Koanf, theme string) (wordGroups, error) {
 
 // highlight colors all words in a string.
 // It doesn't touch already colored parts of the input.
-func (words wordGroups) highlight(str string, h Highlighter) string {
-	return walkNonSGR(str, func(part string) string {
-		if part == "" {
-			return part
-		}
+func (words wordGroups) highlight(spans []span, h Highlighter) []span {
+	return walkUncolored(spans, func(part string) []span {
+		return words.highlightText(part, h)
+	})
+}
 
-		if m := negatedWordRegExp.FindStringSubmatchIndex(part); m != nil {
-			leftPart := words.highlight(part[0:m[0]], h)
-			match := words.highlightNegatedWord(part[m[0]:m[1]], part[m[2]:m[3]], part[m[4]:m[5]], h)
-			rightPart := words.highlight(part[m[1]:], h)
+// highlightText colors all words in the text that isn't colored yet
+func (words wordGroups) highlightText(part string, h Highlighter) []span {
+	if part == "" {
+		return nil
+	}
 
-			return leftPart + match + rightPart
-		}
+	if m := negatedWordRegExp.FindStringSubmatchIndex(part); m != nil {
+		leftPart := words.highlightText(part[0:m[0]], h)
+		match := words.highlightNegatedWord(part[m[0]:m[1]], part[m[2]:m[3]], part[m[4]:m[5]], h)
+		rightPart := words.highlightText(part[m[1]:], h)
 
-		if m := wordRegExp.FindStringIndex(part); m != nil {
-			leftPart := words.highlight(part[0:m[0]], h)
-			match := words.highlightWord(part[m[0]:m[1]], h)
-			rightPart := words.highlight(part[m[1]:], h)
+		return append(append(leftPart, match...), rightPart...)
+	}
 
-			return leftPart + match + rightPart
-		}
+	if m := wordRegExp.FindStringIndex(part); m != nil {
+		leftPart := words.highlightText(part[0:m[0]], h)
+		match := words.highlightWord(part[m[0]:m[1]], h)
+		rightPart := words.highlightText(part[m[1]:], h)
 
-		return part
-	})
+		return append(append(leftPart, match...), rightPart...)
+	}
+
+	return plain(part)
 }
 
 // highlightWord colors single word in a string
-func (words wordGroups) highlightWord(word string, h Highlighter) string {
+func (words wordGroups) highlightWord(word string, h Highlighter) []span {
 	// search in all word groups
 	for _, wordGroup := range append(words.Other, words.Good, words.Bad) {
 		lemma := words.Lemmatizer.Lemma(word)
 		if slices.Contains(wordGroup.List, lemma) ||
 			slices.Contains(wordGroup.List, word) ||
 			slices.Contains(wordGroup.List, strings.ToLower(word)) {
-			word = h.highlight(word, wordGroup.Foreground, wordGroup.Background, wordGroup.Style)
+			spans := claim(h.highlight(word, wordGroup.Foreground, wordGroup.Background, wordGroup.Style), "word", wordGroup.Name)
 			if h.settings.Opts.Debug {
-				word = h.addDebugInfo(word, wordGroup)
+				spans = h.addDebugInfo(spans, wordGroup)
 			}
+			h.addMatch(wordGroup)
 
-			break
+			return spans
 		}
 	}
 
-	return word
+	return plain(word)
 }
 
 // highlightNegated colors a phrase with negated word in a string
 // if the word is good, then color the whole phrase as bad and vice versa
 // if the word is neither good nor bad, then don't color the phrase
-func (words wordGroups) highlightNegatedWord(phrase, negator, word string, h Highlighter) string {
+func (words wordGroups) highlightNegatedWord(phrase, negator, word string, h Highlighter) []span {
 	lemma := words.Lemmatizer.Lemma(word)
 	// good
 	if slices.Contains(words.Good.List, lemma) ||
 		slices.Contains(words.Good.List, word) ||
 		slices.Contains(words.Good.List, strings.ToLower(word)) {
-		phrase = h.highlight(phrase, words.Bad.Foreground, words.Bad.Background, words.Bad.Style)
+		spans := claim(h.highlight(phrase, words.Bad.Foreground, words.Bad.Background, words.Bad.Style), "word", words.Bad.Name)
 		if h.settings.Opts.Debug {
-			phrase = h.addDebugInfo(phrase, words.Good)
+			spans = h.addDebugInfo(spans, words.Good)
 		}
+		h.addMatch(words.Bad)
 
-		return phrase
+		return spans
 	}
 	// bad
 	if slices.Contains(words.Bad.List, lemma) ||
 		slices.Contains(words.Bad.List, word) ||
 		slices.Contains(words.Bad.List, strings.ToLower(word)) {
-		phrase = h.highlight(phrase, words.Good.Foreground, words.Good.Background, words.Good.Style)
+		spans := claim(h.highlight(phrase, words.Good.Foreground, words.Good.Background, words.Good.Style), "word", words.Good.Name)
 		if h.settings.Opts.Debug {
-			phrase = h.addDebugInfo(phrase, words.Bad)
+			spans = h.addDebugInfo(spans, words.Bad)
 		}
+		h.addMatch(words.Good)
 
-		return phrase
+		return spans
 	}
 	// other
 	for _, wordGroup := range words.Other {
 		if slices.Contains(wordGroup.List, lemma) ||
 			slices.Contains(wordGroup.List, word) ||
 			slices.Contains(wordGroup.List, strings.ToLower(word)) {
-			word = h.highlight(word, wordGroup.Foreground, wordGroup.Background, wordGroup.Style)
+			spans := claim(h.highlight(word, wordGroup.Foreground, wordGroup.Background, wordGroup.Style), "word", wordGroup.Name)
 			if h.settings.Opts.Debug {
-				word = h.addDebugInfo(word, wordGroup)
+				spans = h.addDebugInfo(spans, wordGroup)
 			}
+			h.addMatch(wordGroup)
 
-			return negator + " " + word
+			return append(plain(negator+" "), spans...)
 		}
 	}
 
-	return phrase
+	return plain(phrase)
+}
+
+// has reports whether there is a word group with the name
+func (words wordGroups) has(name string) bool {
+	if words.Good.Name == name || words.Bad.Name == name {
+		return name != ""
+	}
+
+	return slices.ContainsFunc(words.Other, func(wg wordGroup) bool { return wg.Name == name })
 }
 
 func (wg wordGroup) validate() error {
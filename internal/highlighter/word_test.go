@@ -159,7 +159,7 @@ func TestWordsHighlightWord(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run("TestWordsHighlightWord"+tt.plain, func(t *testing.T) {
-			colored := words.highlightWord(tt.plain, hl)
+			colored := hl.renderer.line(words.highlightWord(tt.plain, hl))
 			if colored != tt.colored {
 				t.Errorf("got %s, want %s", colored, tt.colored)
 			}
@@ -223,7 +223,7 @@ func TestWordsHighlightNegatedWord(t *testing.T) {
 	for _, tt := range tests {
 		t.Run("TestWordsHighlightNegatedWord"+tt.plain, func(t *testing.T) {
 			m := negatedWordRegExp.FindStringSubmatchIndex(tt.plain)
-			colored := words.highlightNegatedWord(tt.plain[m[0]:m[1]], tt.plain[m[2]:m[3]], tt.plain[m[4]:m[5]], hl)
+			colored := hl.renderer.line(words.highlightNegatedWord(tt.plain[m[0]:m[1]], tt.plain[m[2]:m[3]], tt.plain[m[4]:m[5]], hl))
 			if colored != tt.colored {
 				t.Errorf("got %s, want %s", colored, tt.colored)
 			}
@@ -292,7 +292,7 @@ func TestWordsHighlight(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run("TestWordsHighlight"+tt.plain, func(t *testing.T) {
-			colored := words.highlight(tt.plain, hl)
+			colored := hl.renderer.line(words.highlight(plain(tt.plain), hl))
 			if colored != tt.colored {
 				t.Errorf("got %s, want %s", colored, tt.colored)
 			}
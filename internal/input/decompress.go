@@ -0,0 +1,120 @@
+package input
+
+import (
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// compression is a compressed stream type detected by its magic bytes
+type compression struct {
+	magic [][]byte // the stream starts with one of these
+	open  func(io.Reader) (io.ReadCloser, error)
+}
+
+var compressions = []compression{
+	// gzip
+	{
+		magic: [][]byte{{0x1f, 0x8b}},
+		open: func(r io.Reader) (io.ReadCloser, error) {
+			return gzip.NewReader(r)
+		},
+	},
+	// bzip2 ("BZh" and the block size from 1 to 9)
+	{
+		magic: [][]byte{
+			[]byte("BZh1"), []byte("BZh2"), []byte("BZh3"),
+			[]byte("BZh4"), []byte("BZh5"), []byte("BZh6"),
+			[]byte("BZh7"), []byte("BZh8"), []byte("BZh9"),
+		},
+		open: func(r io.Reader) (io.ReadCloser, error) {
+			return io.NopCloser(bzip2.NewReader(r)), nil
+		},
+	},
+	// xz
+	{
+		magic: [][]byte{{0xfd, '7', 'z', 'X', 'Z', 0x00}},
+		open: func(r io.Reader) (io.ReadCloser, error) {
+			reader, err := xz.NewReader(r)
+
+			return io.NopCloser(reader), err
+		},
+	},
+	// zstd
+	{
+		magic: [][]byte{{0x28, 0xb5, 0x2f, 0xfd}},
+		open: func(r io.Reader) (io.ReadCloser, error) {
+			decoder, err := zstd.NewReader(r)
+			if err != nil {
+				return nil, err
+			}
+
+			return decoder.IOReadCloser(), nil
+		},
+	},
+}
+
+// Decompress detects gzip, bzip2, xz and zstd streams by their magic bytes
+// and returns a reader that decompresses them. Other data is returned as is.
+// Closing the returned reader doesn't close the original one.
+//
+// Only as many bytes as needed to tell the stream type are read in advance,
+// so it's safe to use it with slow readers like followed files or a terminal.
+func Decompress(reader io.Reader) (io.ReadCloser, error) {
+	head, err := readHead(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	// put the bytes we've read back in front of the rest of the stream
+	reader = io.MultiReader(bytes.NewReader(head), reader)
+
+	for _, c := range compressions {
+		for _, magic := range c.magic {
+			if bytes.HasPrefix(head, magic) {
+				return c.open(reader)
+			}
+		}
+	}
+
+	return io.NopCloser(reader), nil
+}
+
+// readHead reads from the reader until it's clear whether the stream
+// starts with one of the known magic byte sequences or not
+func readHead(reader io.Reader) ([]byte, error) {
+	var head []byte
+	buf := make([]byte, 16)
+
+	for maybeCompressed(head) {
+		n, err := reader.Read(buf)
+		head = append(head, buf[:n]...)
+		if errors.Is(err, io.EOF) {
+			return head, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return head, nil
+}
+
+// maybeCompressed reports whether head is too short to tell
+// if the stream is compressed or not
+func maybeCompressed(head []byte) bool {
+	for _, c := range compressions {
+		for _, magic := range c.magic {
+			if len(head) < len(magic) && bytes.HasPrefix(magic, head) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
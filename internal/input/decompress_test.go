@@ -0,0 +1,124 @@
+package input
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestDecompress(t *testing.T) {
+	tests := []struct {
+		filename string
+		data     string
+	}{
+		{"./testdata/decompress/plain.txt", "hello\nworld\n"},
+		{"./testdata/decompress/plain.txt.gz", "hello\nworld\n"},
+		{"./testdata/decompress/plain.txt.bz2", "hello\nworld\n"},
+		{"./testdata/decompress/plain.txt.xz", "hello\nworld\n"},
+		{"./testdata/decompress/plain.txt.zst", "hello\nworld\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run("TestDecompress"+tt.filename, func(t *testing.T) {
+			file, err := os.Open(tt.filename)
+			if err != nil {
+				t.Fatalf("os.Open(%s) failed with this error: %s", tt.filename, err)
+			}
+			defer file.Close()
+
+			reader, err := Decompress(file)
+			if err != nil {
+				t.Fatalf("Decompress() failed with this error: %s", err)
+			}
+			defer reader.Close()
+
+			data, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatalf("io.ReadAll() failed with this error: %s", err)
+			}
+			if string(data) != tt.data {
+				t.Errorf("got %q, want %q", data, tt.data)
+			}
+		})
+	}
+}
+
+func TestDecompressShortInput(t *testing.T) {
+	tests := []string{
+		"",
+		"a",
+		"\x1f",
+		"BZ",
+		"BZh",
+		"BZhx",
+		"\xfd7z",
+	}
+
+	for _, input := range tests {
+		t.Run("TestDecompressShortInput", func(t *testing.T) {
+			reader, err := Decompress(bytes.NewReader([]byte(input)))
+			if err != nil {
+				t.Fatalf("Decompress() failed with this error: %s", err)
+			}
+
+			data, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatalf("io.ReadAll() failed with this error: %s", err)
+			}
+			if string(data) != input {
+				t.Errorf("got %q, want %q", data, input)
+			}
+		})
+	}
+}
+
+// byteReader returns one byte per Read call like a slow pipe does
+type byteReader struct {
+	data []byte
+	read int // number of Read calls
+}
+
+func (r *byteReader) Read(p []byte) (int, error) {
+	r.read++
+	if len(r.data) == 0 {
+		return 0, io.EOF
+	}
+	p[0] = r.data[0]
+	r.data = r.data[1:]
+
+	return 1, nil
+}
+
+func TestDecompressSlowReader(t *testing.T) {
+	reader := &byteReader{data: []byte("hello\nworld\n")}
+
+	decompressed, err := Decompress(reader)
+	if err != nil {
+		t.Fatalf("Decompress() failed with this error: %s", err)
+	}
+
+	t.Run("TestDecompressSlowReaderHead", func(t *testing.T) {
+		// "h" doesn't start any magic sequence so one byte is enough
+		if reader.read != 1 {
+			t.Errorf("Decompress() read %d times, want 1", reader.read)
+		}
+	})
+
+	t.Run("TestDecompressSlowReaderData", func(t *testing.T) {
+		data, err := io.ReadAll(decompressed)
+		if err != nil {
+			t.Fatalf("io.ReadAll() failed with this error: %s", err)
+		}
+		if string(data) != "hello\nworld\n" {
+			t.Errorf("got %q, want %q", data, "hello\nworld\n")
+		}
+	})
+}
+
+func TestDecompressBadStream(t *testing.T) {
+	_, err := Decompress(bytes.NewReader([]byte{0x1f, 0x8b, 0x00}))
+	if err == nil {
+		t.Error("Decompress() should have failed")
+	}
+}
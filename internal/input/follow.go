@@ -0,0 +1,230 @@
+package input
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// PollInterval is how often a followed file is checked for new data,
+// truncation and rotation after its end has been reached.
+var PollInterval = 250 * time.Millisecond
+
+// TailLines is the number of last lines of a followed file
+// that are read before waiting for new data (the same as "tail -F" does).
+const TailLines = 10
+
+// errNewStream is returned once by the follower that reports new streams
+// (see follower.streams) when the followed file starts anew after
+// rotation or truncation. The new file can be compressed in another way
+// than the old one, so its stream type must be detected again.
+var errNewStream = errors.New("followed file starts anew")
+
+// follower reads a file like "tail -F" does
+type follower struct {
+	path string
+
+	streams   bool // return errNewStream when the file starts anew
+	newStream bool // the file has started anew since the last read
+
+	mu     sync.Mutex
+	file   *os.File // the file we are reading right now
+	next   *os.File // the new file that will be read after the rotated one is drained
+	offset int64    // position in the current file
+	closed bool
+
+	done chan struct{}
+}
+
+func newFollower(path string, file *os.File) (*follower, error) {
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+
+		return nil, err
+	}
+
+	offset, err := tailOffset(file, info.Size(), TailLines)
+	if err != nil {
+		_ = file.Close()
+
+		return nil, err
+	}
+
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		_ = file.Close()
+
+		return nil, err
+	}
+
+	return &follower{
+		path:   path,
+		file:   file,
+		offset: offset,
+		done:   make(chan struct{}),
+	}, nil
+}
+
+// Read reads data from the followed file. When the end of the file is reached
+// it blocks until new data appears or the follower is closed.
+func (f *follower) Read(p []byte) (int, error) {
+	for {
+		n, err := f.read(p)
+		if n > 0 || err != nil {
+			return n, err
+		}
+
+		select {
+		case <-f.done:
+			return 0, os.ErrClosed
+		case <-time.After(PollInterval):
+		}
+
+		if err := f.check(); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// read reads the current file and switches to the next one
+// if the current file was rotated and there is nothing left in it
+func (f *follower) read(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+
+	for {
+		if f.newStream {
+			f.newStream = false
+
+			return 0, errNewStream
+		}
+
+		n, err := f.file.Read(p)
+		f.offset += int64(n)
+		if n > 0 {
+			return n, nil
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return 0, err
+		}
+
+		// the end of the current file is reached
+		if f.next == nil {
+			return 0, nil
+		}
+
+		// the rotated file is drained, so continue with the new one
+		_ = f.file.Close()
+		f.file = f.next
+		f.next = nil
+		f.offset = 0
+		f.newStream = f.streams
+	}
+}
+
+// check detects rotation and truncation of the followed file
+func (f *follower) check() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed || f.next != nil {
+		return nil
+	}
+
+	info, err := os.Stat(f.path)
+	// the file was removed and hasn't been created again yet
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	current, err := f.file.Stat()
+	if err != nil {
+		return err
+	}
+
+	// the file was rotated
+	if !os.SameFile(current, info) {
+		next, err := os.Open(filepath.Clean(f.path))
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		f.next = next
+
+		return nil
+	}
+
+	// the file was truncated
+	if info.Size() < f.offset {
+		if _, err := f.file.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		f.offset = 0
+		f.newStream = f.streams
+	}
+
+	return nil
+}
+
+// Close stops following the file.
+func (f *follower) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+	close(f.done)
+
+	if f.next != nil {
+		_ = f.next.Close()
+	}
+
+	return f.file.Close()
+}
+
+// tailOffset returns the offset where the last n lines of the file begin
+func tailOffset(file io.ReaderAt, size int64, n int) (int64, error) {
+	if n <= 0 {
+		return size, nil
+	}
+
+	buf := make([]byte, 4096)
+	end := size
+	for end > 0 {
+		start := max(end-int64(len(buf)), 0)
+		chunk := buf[:end-start]
+		if _, err := file.ReadAt(chunk, start); err != nil && !errors.Is(err, io.EOF) {
+			return 0, err
+		}
+
+		for i := len(chunk) - 1; i >= 0; i-- {
+			// the newline at the very end of the file doesn't start a new line
+			if chunk[i] != '\n' || start+int64(i) == size-1 {
+				continue
+			}
+			n--
+			if n == 0 {
+				return start + int64(i) + 1, nil
+			}
+		}
+
+		end = start
+	}
+
+	return 0, nil
+}
@@ -0,0 +1,262 @@
+package input
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func init() {
+	PollInterval = 10 * time.Millisecond
+}
+
+// readLine reads one line from the reader or fails after a timeout
+func readLine(t *testing.T, reader *bufio.Reader) string {
+	t.Helper()
+
+	result := make(chan string, 1)
+	go func() {
+		line, _ := reader.ReadString('\n')
+		result <- line
+	}()
+
+	select {
+	case line := <-result:
+		return line
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a line")
+	}
+
+	return ""
+}
+
+func appendToFile(t *testing.T, filename, data string) {
+	t.Helper()
+
+	file, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatalf("Wasn't able to open %s: %s", filename, err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(data); err != nil {
+		t.Fatalf("Wasn't able to write to %s: %s", filename, err)
+	}
+}
+
+func TestFollowerTailOffset(t *testing.T) {
+	tests := []struct {
+		data   string
+		n      int
+		offset int64
+	}{
+		{"", 10, 0},
+		{"one\ntwo\nthree\n", 0, 14},
+		{"one\ntwo\nthree\n", 1, 8},
+		{"one\ntwo\nthree\n", 2, 4},
+		{"one\ntwo\nthree\n", 3, 0},
+		{"one\ntwo\nthree\n", 10, 0},
+		{"one\ntwo\nthree", 1, 8},
+		{"one\n\n\n", 2, 4},
+		{strings.Repeat("x", 5000) + "\n" + strings.Repeat("y", 5000) + "\n", 1, 5001},
+	}
+
+	for _, tt := range tests {
+		t.Run("TestFollowerTailOffset", func(t *testing.T) {
+			offset, err := tailOffset(strings.NewReader(tt.data), int64(len(tt.data)), tt.n)
+			if err != nil {
+				t.Fatalf("tailOffset() failed with this error: %s", err)
+			}
+			if offset != tt.offset {
+				t.Errorf("got %d, want %d", offset, tt.offset)
+			}
+		})
+	}
+}
+
+func TestFollowerRead(t *testing.T) {
+	filename := t.TempDir() + "/input.log"
+	var data strings.Builder
+	for i := range 15 {
+		data.WriteString(strings.Repeat("line", i) + "\n")
+	}
+	appendToFile(t, filename, data.String())
+
+	reader, err := Open(filename, true, false)
+	if err != nil {
+		t.Fatalf("Open() failed with this error: %s", err)
+	}
+	defer reader.Close()
+	bufReader := bufio.NewReader(reader)
+
+	t.Run("TestFollowerReadTail", func(t *testing.T) {
+		for i := 5; i < 15; i++ {
+			want := strings.Repeat("line", i) + "\n"
+			if got := readLine(t, bufReader); got != want {
+				t.Errorf("got %q, want %q", got, want)
+			}
+		}
+	})
+
+	t.Run("TestFollowerReadAppend", func(t *testing.T) {
+		appendToFile(t, filename, "appended\n")
+		if got := readLine(t, bufReader); got != "appended\n" {
+			t.Errorf("got %q, want %q", got, "appended\n")
+		}
+	})
+
+	t.Run("TestFollowerReadTruncate", func(t *testing.T) {
+		if err := os.Truncate(filename, 0); err != nil {
+			t.Fatalf("Wasn't able to truncate %s: %s", filename, err)
+		}
+		// wait until the truncation is noticed
+		time.Sleep(10 * PollInterval)
+		appendToFile(t, filename, "truncated\n")
+		if got := readLine(t, bufReader); got != "truncated\n" {
+			t.Errorf("got %q, want %q", got, "truncated\n")
+		}
+	})
+
+	t.Run("TestFollowerReadRotate", func(t *testing.T) {
+		if err := os.Rename(filename, filename+".1"); err != nil {
+			t.Fatalf("Wasn't able to rename %s: %s", filename, err)
+		}
+		// this line is written after the rotation but still has to be read
+		appendToFile(t, filename+".1", "old\n")
+		time.Sleep(10 * PollInterval)
+		appendToFile(t, filename, "new\n")
+
+		if got := readLine(t, bufReader); got != "old\n" {
+			t.Errorf("got %q, want %q", got, "old\n")
+		}
+		if got := readLine(t, bufReader); got != "new\n" {
+			t.Errorf("got %q, want %q", got, "new\n")
+		}
+	})
+}
+
+func TestFollowerReadDecompress(t *testing.T) {
+	filename := t.TempDir() + "/input.log"
+	appendToFile(t, filename, "")
+
+	// the empty file can't tell whether it's compressed or not,
+	// but it must not block opening it
+	opened := make(chan io.ReadCloser, 1)
+	go func() {
+		reader, err := Open(filename, true, true)
+		if err != nil {
+			t.Errorf("Open() failed with this error: %s", err)
+		}
+		opened <- reader
+	}()
+
+	var reader io.ReadCloser
+	select {
+	case reader = <-opened:
+		if reader == nil {
+			return
+		}
+		defer reader.Close()
+	case <-time.After(5 * time.Second):
+		t.Fatal("Open() is blocked by the empty file")
+	}
+
+	t.Run("TestFollowerReadDecompressAppend", func(t *testing.T) {
+		appendToFile(t, filename, "appended\n")
+		if got := readLine(t, bufio.NewReader(reader)); got != "appended\n" {
+			t.Errorf("got %q, want %q", got, "appended\n")
+		}
+	})
+}
+
+func TestFollowerReadDecompressRotate(t *testing.T) {
+	compressed, err := os.ReadFile("./testdata/decompress/plain.txt.gz")
+	if err != nil {
+		t.Fatalf("os.ReadFile(...) failed with this error: %s", err)
+	}
+	filename := t.TempDir() + "/input.log"
+	appendToFile(t, filename, "plain\n")
+
+	reader, err := Open(filename, true, true)
+	if err != nil {
+		t.Fatalf("Open() failed with this error: %s", err)
+	}
+	defer reader.Close()
+
+	// gzip reader waits for the next member after the end of the first one,
+	// so lines are read in the background while the file is rotated
+	lines := make(chan string, 4)
+	go func() {
+		bufReader := bufio.NewReader(reader)
+		for {
+			line, err := bufReader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			lines <- line
+		}
+	}()
+
+	// the stream type of every new file is detected again
+	for i, data := range []string{string(compressed), "after\n"} {
+		time.Sleep(10 * PollInterval)
+		if err := os.Rename(filename, filename+"."+strconv.Itoa(i+1)); err != nil {
+			t.Fatalf("Wasn't able to rename %s: %s", filename, err)
+		}
+		appendToFile(t, filename, data)
+	}
+
+	for _, want := range []string{"plain\n", "hello\n", "world\n", "after\n"} {
+		select {
+		case got := <-lines:
+			if got != want {
+				t.Errorf("got %q, want %q", got, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for %q", want)
+		}
+	}
+}
+
+func TestFollowerClose(t *testing.T) {
+	filename := t.TempDir() + "/input.log"
+	appendToFile(t, filename, "")
+
+	reader, err := Open(filename, true, false)
+	if err != nil {
+		t.Fatalf("Open() failed with this error: %s", err)
+	}
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := reader.Read(make([]byte, 16))
+		result <- err
+	}()
+
+	time.Sleep(5 * PollInterval)
+	if err := reader.Close(); err != nil {
+		t.Errorf("Close() failed with this error: %s", err)
+	}
+
+	t.Run("TestFollowerClose", func(t *testing.T) {
+		select {
+		case err := <-result:
+			if !errors.Is(err, os.ErrClosed) {
+				t.Errorf("Read() should have failed with os.ErrClosed, got: %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Error("Read() wasn't interrupted by Close()")
+		}
+	})
+
+	t.Run("TestFollowerCloseTwice", func(t *testing.T) {
+		if err := reader.Close(); err != nil {
+			t.Errorf("second Close() failed with this error: %s", err)
+		}
+	})
+}
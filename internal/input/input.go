@@ -0,0 +1,119 @@
+// Package input opens the sources logalize reads its lines from.
+package input
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Stdin is the special path that refers to the standard input.
+const Stdin = "-"
+
+// Open opens the file at path for reading.
+//
+// If decompress is true, gzip, bzip2, xz and zstd files
+// are decompressed on the fly (see Decompress). The stream type
+// is detected on the first Read, so opening a followed file
+// that is still empty doesn't block. It's detected again every time
+// a followed file is rotated or truncated.
+//
+// If follow is true, the returned reader doesn't stop at the end of the file
+// and waits for new data instead, like "tail -F" does. It keeps reading
+// the file when it's truncated and reopens it when it's rotated
+// (renamed or removed and then created again). Reading starts
+// from the last TailLines lines of the file in this case.
+//
+// The path "-" refers to the standard input which is never followed.
+func Open(path string, follow, decompress bool) (io.ReadCloser, error) {
+	var reader io.ReadCloser
+	if path == Stdin {
+		reader = io.NopCloser(os.Stdin)
+	} else {
+		file, err := os.Open(filepath.Clean(path))
+		if err != nil {
+			return nil, err
+		}
+		reader = file
+
+		if follow {
+			f, err := newFollower(path, file)
+			if err != nil {
+				return nil, err
+			}
+			f.streams = decompress
+			reader = f
+		}
+	}
+
+	if !decompress {
+		return reader, nil
+	}
+
+	decompressed := &lazyDecompressor{reader: reader}
+
+	return readCloser{decompressed, func() error {
+		_ = decompressed.Close()
+
+		return reader.Close()
+	}}, nil
+}
+
+// lazyDecompressor calls Decompress on the first Read and again
+// when a followed file starts anew (see errNewStream).
+// It can be closed while Read is waiting for a followed file.
+type lazyDecompressor struct {
+	reader       io.Reader
+	mu           sync.Mutex
+	decompressed io.ReadCloser
+}
+
+func (ld *lazyDecompressor) Read(p []byte) (int, error) {
+	if ld.decompressed == nil {
+		decompressed, err := Decompress(ld.reader)
+		if err != nil {
+			return 0, err
+		}
+		ld.mu.Lock()
+		ld.decompressed = decompressed
+		ld.mu.Unlock()
+	}
+
+	n, err := ld.decompressed.Read(p)
+	if errors.Is(err, errNewStream) {
+		ld.mu.Lock()
+		_ = ld.decompressed.Close()
+		ld.decompressed = nil
+		ld.mu.Unlock()
+
+		if n == 0 {
+			return ld.Read(p)
+		}
+
+		return n, nil
+	}
+
+	return n, err
+}
+
+func (ld *lazyDecompressor) Close() error {
+	ld.mu.Lock()
+	defer ld.mu.Unlock()
+	if ld.decompressed == nil {
+		return nil
+	}
+
+	return ld.decompressed.Close()
+}
+
+// readCloser closes both the decompressor and the file under it
+type readCloser struct {
+	io.Reader
+	close func() error
+}
+
+func (rc readCloser) Close() error {
+	return rc.close()
+}
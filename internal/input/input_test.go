@@ -0,0 +1,96 @@
+package input
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"testing"
+)
+
+func TestInputOpen(t *testing.T) {
+	filename := t.TempDir() + "/input.txt"
+	if err := os.WriteFile(filename, []byte("hello\nworld\n"), 0o600); err != nil {
+		t.Fatalf("Wasn't able to write test file to %s: %s", filename, err)
+	}
+
+	t.Run("TestInputOpenFile", func(t *testing.T) {
+		reader, err := Open(filename, false, false)
+		if err != nil {
+			t.Fatalf("Open() failed with this error: %s", err)
+		}
+		defer reader.Close()
+
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("io.ReadAll() failed with this error: %s", err)
+		}
+		if string(data) != "hello\nworld\n" {
+			t.Errorf("got %q, want %q", data, "hello\nworld\n")
+		}
+	})
+
+	t.Run("TestInputOpenFollow", func(t *testing.T) {
+		reader, err := Open(filename, true, false)
+		if err != nil {
+			t.Fatalf("Open() failed with this error: %s", err)
+		}
+		defer reader.Close()
+
+		if _, ok := reader.(*follower); !ok {
+			t.Errorf("Open() should have returned *follower, got: %T", reader)
+		}
+	})
+
+	t.Run("TestInputOpenStdin", func(t *testing.T) {
+		reader, err := Open(Stdin, true, false)
+		if err != nil {
+			t.Fatalf("Open() failed with this error: %s", err)
+		}
+		if _, ok := reader.(*follower); ok {
+			t.Error("stdin can't be followed")
+		}
+	})
+
+	t.Run("TestInputOpenNonExistent", func(t *testing.T) {
+		_, err := Open(t.TempDir()+"/non-existent.txt", false, false)
+		if _, ok := err.(*fs.PathError); !ok {
+			t.Errorf("Open() should have failed with *fs.PathError, got: [%T] %s", err, err)
+		}
+	})
+}
+
+func TestInputOpenDecompress(t *testing.T) {
+	filename := "./testdata/decompress/plain.txt.gz"
+
+	t.Run("TestInputOpenDecompress", func(t *testing.T) {
+		reader, err := Open(filename, false, true)
+		if err != nil {
+			t.Fatalf("Open() failed with this error: %s", err)
+		}
+		defer reader.Close()
+
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("io.ReadAll() failed with this error: %s", err)
+		}
+		if string(data) != "hello\nworld\n" {
+			t.Errorf("got %q, want %q", data, "hello\nworld\n")
+		}
+	})
+
+	t.Run("TestInputOpenNoDecompress", func(t *testing.T) {
+		reader, err := Open(filename, false, false)
+		if err != nil {
+			t.Fatalf("Open() failed with this error: %s", err)
+		}
+		defer reader.Close()
+
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("io.ReadAll() failed with this error: %s", err)
+		}
+		if string(data) == "hello\nworld\n" {
+			t.Error("Open() shouldn't have decompressed the file")
+		}
+	})
+}
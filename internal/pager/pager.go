@@ -0,0 +1,79 @@
+// Package pager pipes the output through a pager like "less".
+package pager
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// Pager is a running pager program the output is written to
+type Pager struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+}
+
+// Command returns the pager command from LOGALIZE_PAGER or PAGER
+// environment variables or "less -R" if none of them is set.
+// Empty command or "cat" means that the pager isn't needed.
+func Command() string {
+	for _, name := range []string{"LOGALIZE_PAGER", "PAGER"} {
+		if command, ok := os.LookupEnv(name); ok {
+			if command == "cat" {
+				return ""
+			}
+
+			return command
+		}
+	}
+
+	return "less -R"
+}
+
+// Start runs the pager command with the shell, so it can have
+// arguments and quotes. The pager writes to the output.
+// Like git does, less gets LESS=FRX if LESS isn't set,
+// so it exits right away if the text fits the screen.
+func Start(command string, output io.Writer) (*Pager, error) {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stdout = output
+	cmd.Stderr = os.Stderr
+	cmd.Env = os.Environ()
+	if _, ok := os.LookupEnv("LESS"); !ok {
+		cmd.Env = append(cmd.Env, "LESS=FRX")
+	}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	return &Pager{cmd: cmd, stdin: stdin}, nil
+}
+
+func (p *Pager) Write(data []byte) (int, error) {
+	return p.stdin.Write(data)
+}
+
+// Close tells the pager that there is no more text
+// and waits until user quits it
+func (p *Pager) Close() error {
+	err := p.stdin.Close()
+	if waitErr := p.cmd.Wait(); err == nil && waitErr != nil {
+		err = fmt.Errorf("[pager: %s] %w", p.cmd.Args[2], waitErr)
+	}
+
+	return err
+}
+
+// Quit reports whether the error is caused by user quitting
+// the pager before the whole text was written to it
+func Quit(err error) bool {
+	return errors.Is(err, syscall.EPIPE) || errors.Is(err, os.ErrClosed)
+}
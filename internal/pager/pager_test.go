@@ -0,0 +1,99 @@
+package pager
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestPagerCommand(t *testing.T) {
+	tests := []struct {
+		name          string
+		logalizePager *string
+		pager         *string
+		command       string
+	}{
+		{"Default", nil, nil, "less -R"},
+		{"Pager", nil, ptr("most"), "most"},
+		{"LogalizePager", ptr("less -RS"), ptr("most"), "less -RS"},
+		{"EmptyLogalizePager", ptr(""), ptr("most"), ""},
+		{"EmptyPager", nil, ptr(""), ""},
+		{"Cat", nil, ptr("cat"), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run("TestPagerCommand"+tt.name, func(t *testing.T) {
+			setenv(t, "LOGALIZE_PAGER", tt.logalizePager)
+			setenv(t, "PAGER", tt.pager)
+
+			if command := Command(); command != tt.command {
+				t.Errorf("got %q, want %q", command, tt.command)
+			}
+		})
+	}
+}
+
+func TestPagerStart(t *testing.T) {
+	t.Run("TestPagerStartGood", func(t *testing.T) {
+		setenv(t, "LESS", nil)
+
+		var output bytes.Buffer
+		pager, err := Start("tr a-z A-Z; echo $LESS", &output)
+		if err != nil {
+			t.Fatalf("Start(...) failed with this error: %s", err)
+		}
+		if _, err := io.WriteString(pager, "hello\n"); err != nil {
+			t.Errorf("pager.Write(...) failed with this error: %s", err)
+		}
+		if err := pager.Close(); err != nil {
+			t.Errorf("pager.Close() failed with this error: %s", err)
+		}
+		if output.String() != "HELLO\nFRX\n" {
+			t.Errorf("got %q, want %q", output.String(), "HELLO\nFRX\n")
+		}
+	})
+
+	t.Run("TestPagerStartQuit", func(t *testing.T) {
+		pager, err := Start("exit 0", io.Discard)
+		if err != nil {
+			t.Fatalf("Start(...) failed with this error: %s", err)
+		}
+		// the pipe buffer is filled up long before this
+		// and the pager can't read it anymore
+		_, err = io.WriteString(pager, strings.Repeat("line\n", 1<<20))
+		if !Quit(err) {
+			t.Errorf("pager.Write(...) should have failed because of the quit pager, got: %v", err)
+		}
+		if err := pager.Close(); err != nil {
+			t.Errorf("pager.Close() failed with this error: %s", err)
+		}
+	})
+
+	t.Run("TestPagerStartBad", func(t *testing.T) {
+		pager, err := Start("exit 127", io.Discard)
+		if err != nil {
+			t.Fatalf("Start(...) failed with this error: %s", err)
+		}
+		if err := pager.Close(); err == nil {
+			t.Error("pager.Close() should have failed")
+		}
+	})
+}
+
+func ptr(s string) *string {
+	return &s
+}
+
+// setenv sets the environment variable for the test
+// or unsets it if value is nil
+func setenv(t *testing.T, name string, value *string) {
+	t.Helper()
+	t.Setenv(name, "")
+	if value == nil {
+		_ = os.Unsetenv(name)
+	} else {
+		_ = os.Setenv(name, *value)
+	}
+}
@@ -3,6 +3,8 @@ package main
 import (
 	"embed"
 	"os"
+	// --tz flag must work even without time zone database in the system
+	_ "time/tzdata"
 
 	cmd "github.com/deponian/logalize/cmd/logalize"
 )